@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/kpb/wingit-mcp/internal/alerts"
+	"github.com/kpb/wingit-mcp/internal/config"
+	mcpi "github.com/kpb/wingit-mcp/internal/mcp"
+	"github.com/kpb/wingit-mcp/internal/store"
+	"github.com/kpb/wingit-mcp/internal/tools"
+	"github.com/kpb/wingit-mcp/internal/users"
+)
+
+// app is the service loaded from the config, shared by the MCP server and
+// the command-line subcommands so they answer the same query the same way.
+type app struct {
+	store *store.Store
+	svc   *mcpi.Service
+}
+
+// newApp loads everything cfg names. cfg must already be valid.
+func newApp(logger *log.Logger, cfg *config.Config) (*app, error) {
+	st, err := openStore(logger, cfg)
+	if err != nil {
+		return nil, err
+	}
+	tax := loadTaxonomy(logger, cfg)
+	reg, err := loadUsers(logger, cfg, users.Options{Store: st, Taxonomy: tax})
+	if err != nil {
+		if st != nil {
+			st.Close()
+		}
+		return nil, err
+	}
+	return &app{
+		store: st,
+		svc: &mcpi.Service{
+			Users: reg,
+			Recent: alerts.SourceFunc(func(context.Context) ([]tools.RecentObservation, error) {
+				return loadRecent(cfg.Data.Recent)
+			}),
+			Taxonomy: tax,
+			Locales:  loadLocales(logger, cfg),
+			State:    openState(logger, cfg),
+			Profiles: openProfiles(logger, cfg),
+			Defaults: cfg.Targets.Defaults(),
+			Clock:    alerts.SystemClock{},
+			Logger:   logger,
+		},
+	}, nil
+}
+
+func (a *app) close() {
+	if a.store != nil {
+		a.store.Close()
+	}
+}
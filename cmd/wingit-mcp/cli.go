@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/kpb/wingit-mcp/internal/config"
+	mcpi "github.com/kpb/wingit-mcp/internal/mcp"
+	"github.com/kpb/wingit-mcp/internal/render"
+	"github.com/kpb/wingit-mcp/internal/tools"
+)
+
+// cliCommand runs one of the data subcommands (targets, stats, species)
+// against a freshly loaded app, writing results to w and logs to stderr.
+type cliCommand func(ctx context.Context, a *app, args []string, w io.Writer) error
+
+var cliCommands = map[string]cliCommand{
+	"targets": targetsCommand,
+	"stats":   statsCommand,
+	"species": speciesCommand,
+}
+
+// runCLI validates cfg, loads the app and runs cmd. It returns the process
+// exit code: 2 for configuration or usage errors, 1 when the command fails.
+func runCLI(logger *log.Logger, cfg *config.Config, cmd cliCommand, args []string, w io.Writer) int {
+	if err := cfg.Validate(); err != nil {
+		logger.Printf("ERROR: invalid configuration (see `wingit-mcp config show`):\n%v", err)
+		return 2
+	}
+	a, err := newApp(logger, cfg)
+	if err != nil {
+		logger.Printf("ERROR: %v", err)
+		return 2
+	}
+	defer a.close()
+	if err := cmd(context.Background(), a, args, w); err != nil {
+		logger.Printf("ERROR: %v", err)
+		var ue usageError
+		if errors.As(err, &ue) {
+			return 2
+		}
+		return 1
+	}
+	return 0
+}
+
+// newFlagSet returns a flag set for a subcommand whose errors are returned
+// rather than printed.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// targetsCommand prints a target checklist, e.g.
+//
+//	wingit-mcp targets --location 35.687,-105.938 --radius 15 --days 3 --format csv
+//
+// It takes the same arguments as the target_checklist tool and runs the
+// same pipeline, so results (and saved history for since) match the server.
+func targetsCommand(ctx context.Context, a *app, args []string, w io.Writer) error {
+	var ta tools.TargetArgs
+	fs := newFlagSet("targets")
+	fs.StringVar(&ta.Location, "location", "", `"lat,lng" or a place name`)
+	fs.Float64Var(&ta.RadiusKm, "radius", 0, "search radius in km")
+	fs.IntVar(&ta.DaysBack, "days", 0, "days of recent observations")
+	fs.IntVar(&ta.MaxSpecies, "max-species", 0, "cap on targets returned")
+	fs.Float64Var(&ta.MinFrequency, "min-frequency", 0, "minimum recent frequency (0-1)")
+	fs.BoolVar(&ta.IncludeHeardOnly, "heard-only", false, "include heard-only reports")
+	fs.BoolVar(&ta.ExcludeHeardOnly, "no-heard-only", false, "leave out heard-only reports even if the profile includes them")
+	fs.StringVar(&ta.Locale, "locale", "", "localize common names")
+	fs.StringVar(&ta.SortOrder, "sort", "", "sort order")
+	fs.StringVar(&ta.Profile, "profile", "", "saved target profile to run")
+	fs.StringVar(&ta.Since, "since", "", `also report changes since "last" or a date (saves this run)`)
+	save := fs.Bool("save", false, "save this run to the target history, as the server does")
+	fs.StringVar(&ta.User, "user", "", "user ID (multi-user mode)")
+	fs.StringVar(&ta.Format, "format", render.FormatMarkdown, "md, csv, json, html or geojson")
+	if err := fs.Parse(args); err != nil {
+		return usageErrorf("wingit-mcp targets --location <lat,lng> [--radius km] [--days n] [--format md|csv|json]: %w", err)
+	}
+	if ta.Location == "" && ta.Profile == "" {
+		return usageErrorf("wingit-mcp targets: --location or --profile is required")
+	}
+
+	u, err := a.svc.Users.Lookup(ta.User)
+	if err != nil {
+		return err
+	}
+	// Scripted runs leave the history (and so target_changes and alerts)
+	// alone unless asked to take part in it.
+	run := a.svc.PreviewTargets
+	if *save || ta.Since != "" {
+		run = a.svc.TargetChecklist
+	}
+	out, ta, recent, err := run(ctx, u, ta)
+	if err != nil {
+		return err
+	}
+	hotspots, _ := tools.TargetHotspots(out, recent)
+	doc, rendered, err := render.TargetChecklist(ta.Format, out, hotspots)
+	if err != nil {
+		return err
+	}
+	if !rendered {
+		return writeJSON(w, out)
+	}
+	if _, err := io.WriteString(w, doc.Text); err != nil {
+		return err
+	}
+	if out.Changes != nil && doc.Format == render.FormatMarkdown {
+		_, err = fmt.Fprintf(w, "\n_%s_\n", out.Changes.Summary())
+	}
+	return err
+}
+
+// statsCommand prints life list statistics, optionally for one region.
+func statsCommand(ctx context.Context, a *app, args []string, w io.Writer) error {
+	var sa tools.LifeStatsArgs
+	fs := newFlagSet("stats")
+	fs.StringVar(&sa.Region, "region", "", `eBird region prefix such as "US-NM"`)
+	fs.StringVar(&sa.User, "user", "", "user ID (multi-user mode)")
+	format := fs.String("format", "text", "text or json")
+	if err := fs.Parse(args); err != nil {
+		return usageErrorf("wingit-mcp stats [--region code] [--format text|json]: %w", err)
+	}
+
+	u, err := a.svc.Users.Lookup(sa.User)
+	if err != nil {
+		return err
+	}
+	pc, err := u.FirstSightings(ctx)
+	if err != nil {
+		return err
+	}
+	out, err := tools.BuildLifeStats(ctx, sa, pc, a.svc.Taxonomy)
+	if err != nil {
+		return err
+	}
+	switch *format {
+	case "json":
+		return writeJSON(w, out)
+	case "text":
+	default:
+		return usageErrorf("wingit-mcp stats: unknown format %q (want text or json)", *format)
+	}
+
+	fmt.Fprintln(w, mcpi.LifeStatsSummary(out))
+	writeCounts := func(title string, rows []tools.RegionCount) {
+		if len(rows) == 0 {
+			return
+		}
+		fmt.Fprintf(w, "\n%s\n", title)
+		for _, r := range rows {
+			fmt.Fprintf(w, "  %-12s %5d\n", r.Region, r.Species)
+		}
+	}
+	writeCounts("By country", out.ByCountry)
+	writeCounts("By state", out.ByState)
+	if len(out.ByYear) > 0 {
+		fmt.Fprintf(w, "\n%-6s %7s %7s %10s\n", "Year", "Species", "Lifers", "Cumulative")
+		for _, y := range out.ByYear {
+			fmt.Fprintf(w, "%-6s %7d %7d %10d\n", y.Year, y.SpeciesSeen, y.Lifers, y.Cumulative)
+		}
+	}
+	if len(out.Milestones) > 0 {
+		fmt.Fprintln(w, "\nMilestones")
+		for _, m := range out.Milestones {
+			fmt.Fprintf(w, "  %5d  %s  %s\n", m.N, m.Date, m.CommonName)
+		}
+	}
+	return nil
+}
+
+// speciesCommand looks up one species and the user's history with it.
+func speciesCommand(ctx context.Context, a *app, args []string, w io.Writer) error {
+	var sa tools.SpeciesInfoArgs
+	fs := newFlagSet("species")
+	fs.StringVar(&sa.User, "user", "", "user ID (multi-user mode)")
+	format := fs.String("format", "text", "text or json")
+	if err := fs.Parse(args); err != nil {
+		return usageErrorf("wingit-mcp species [--format text|json] <name or code>: %w", err)
+	}
+	sa.Query = strings.Join(fs.Args(), " ")
+	if sa.Query == "" {
+		return usageErrorf("wingit-mcp species [--format text|json] <name or code>")
+	}
+
+	u, err := a.svc.Users.Lookup(sa.User)
+	if err != nil {
+		return err
+	}
+	out, err := a.svc.SpeciesInfo(ctx, u, sa)
+	if err != nil {
+		return err
+	}
+	switch *format {
+	case "json":
+		return writeJSON(w, out)
+	case "text":
+	default:
+		return usageErrorf("wingit-mcp species: unknown format %q (want text or json)", *format)
+	}
+
+	fmt.Fprintln(w, mcpi.SpeciesInfoSummary(out))
+	if sp := out.Species; sp.FamilyComName != "" {
+		fmt.Fprintf(w, "  family: %s (%s), order: %s\n", sp.FamilyComName, sp.FamilySciName, sp.Order)
+	}
+	if h := out.History; h != nil {
+		fmt.Fprintf(w, "  last seen %s; %d checklists, %d individuals\n", h.LastSeen, h.TotalChecklists, h.TotalCount)
+	}
+	for _, m := range out.Alternatives {
+		fmt.Fprintf(w, "  did you mean: %s (%s)?\n", m.Entry.CommonName, m.Entry.SpeciesCode)
+	}
+	return nil
+}
+
+// usageError is a bad command line, as opposed to a failed command.
+type usageError struct{ error }
+
+func usageErrorf(format string, a ...any) error {
+	return usageError{fmt.Errorf("usage: "+format, a...)}
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/kpb/wingit-mcp/internal/config"
+	"github.com/kpb/wingit-mcp/internal/state"
+	"github.com/kpb/wingit-mcp/internal/tools"
+)
+
+func testConfig(t *testing.T) *config.Config {
+	t.Helper()
+	cfg := config.Default()
+	cfg.Dir = t.TempDir()
+	cfg.Data.Personal = "../../data/personal_checklist_example.json"
+	cfg.Data.Recent = "../../data/recent_nearby_example.json"
+	cfg.Data.Taxonomy = "../../data/taxonomy_example.json"
+	return cfg
+}
+
+func Test_targets_command_prints_each_format(t *testing.T) {
+	t.Parallel()
+
+	logger := log.New(io.Discard, "", 0)
+	cfg := testConfig(t)
+	for format, want := range map[string]string{
+		"md":   "- [ ] **Lewis's Woodpecker**",
+		"csv":  "lewo,Lewis's Woodpecker",
+		"json": `"SpeciesCode": "lewo"`,
+	} {
+		var buf bytes.Buffer
+		args := []string{"--location", "35.687,-105.938", "--days", "30", "--format", format}
+		if code := runCLI(logger, cfg, targetsCommand, args, &buf); code != 0 {
+			t.Fatalf("%s: exit code %d", format, code)
+		}
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("%s: expected %q in:\n%s", format, want, buf.String())
+		}
+	}
+}
+
+func Test_targets_command_saves_history_only_on_request(t *testing.T) {
+	t.Parallel()
+
+	logger := log.New(io.Discard, "", 0)
+	cfg := testConfig(t)
+	history := func() []string {
+		t.Helper()
+		st, err := state.Open(cfg.StateDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys, err := st.Keys(tools.TargetHistoryBucket)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return keys
+	}
+
+	args := []string{"--location", "35.687,-105.938", "--days", "30"}
+	if code := runCLI(logger, cfg, targetsCommand, args, io.Discard); code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	if keys := history(); len(keys) != 0 {
+		t.Fatalf("plain run saved history: %v", keys)
+	}
+	if code := runCLI(logger, cfg, targetsCommand, append(args, "--save"), io.Discard); code != 0 {
+		t.Fatalf("--save: exit code %d", code)
+	}
+	if keys := history(); len(keys) != 1 {
+		t.Fatalf("--save history = %v, want one query", keys)
+	}
+}
+
+func Test_cli_commands_report_usage_errors(t *testing.T) {
+	t.Parallel()
+
+	logger := log.New(io.Discard, "", 0)
+	cfg := testConfig(t)
+	for name, args := range map[string][]string{
+		"targets": {"--radius", "5"},
+		"stats":   {"--format", "xml"},
+		"species": nil,
+	} {
+		if code := runCLI(logger, cfg, cliCommands[name], args, io.Discard); code != 2 {
+			t.Errorf("%s %v: expected exit code 2, got %d", name, args, code)
+		}
+	}
+
+	var buf bytes.Buffer
+	if code := runCLI(logger, cfg, speciesCommand, []string{"lewis", "woodpecker"}, &buf); code != 0 {
+		t.Fatalf("species: exit code %d", code)
+	}
+	if !strings.HasPrefix(buf.String(), "Lewis's Woodpecker (Melanerpes lewis): not yet seen") {
+		t.Errorf("unexpected species output:\n%s", buf.String())
+	}
+}
+
+// bearerTransport adds a bearer token to every request.
+type bearerTransport struct{ token string }
+
+func (b bearerTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("Authorization", "Bearer "+b.token)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func Test_http_token_admits_tool_calls(t *testing.T) {
+	t.Parallel()
+
+	a, err := newApp(log.New(io.Discard, "", 0), testConfig(t))
+	if err != nil {
+		t.Fatalf("newApp: %v", err)
+	}
+	defer a.close()
+	srv := httptest.NewServer(httpHandler(a.svc.NewServer(&mcp.Implementation{Name: "wingit-test"}), "s3cret"))
+	defer srv.Close()
+
+	if resp, err := http.Post(srv.URL, "application/json", strings.NewReader("{}")); err != nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("a request without the token should be rejected, got %v, %v", resp, err)
+	}
+
+	ctx := context.Background()
+	client := mcp.NewClient(&mcp.Implementation{Name: "client"}, nil)
+	cs, err := client.Connect(ctx, &mcp.StreamableClientTransport{
+		Endpoint:   srv.URL,
+		HTTPClient: &http.Client{Transport: bearerTransport{"s3cret"}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer cs.Close()
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "life_stats"})
+	if err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+	if text := res.Content[0].(*mcp.TextContent).Text; res.IsError || !strings.Contains(text, "species on your life list") {
+		t.Errorf("life_stats through the token: %q", text)
+	}
+}
+
+func Test_doctor_checks_every_user_without_side_effects(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	b, err := os.ReadFile("../../data/personal_checklist_example.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string]string{
+		"users.json": `{"users": [
+			{"id": "alice", "personalJson": "alice.json", "token": "a"},
+			{"id": "bob", "personalJson": "bob.json", "token": "b"},
+			{"id": "carol", "personalJson": "carol.json", "token": "c"}
+		]}`,
+		"alice.json": string(b),
+		"bob.json":   `{"sightings": [{"speciesCode": "clanut", "obsDt": "2025-09-12", "lat": 91}]}`,
+		"carol.json": `{"sightings": []}`,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := testConfig(t)
+	cfg.Data.Personal = ""
+	cfg.Data.UsersDir = dir
+	cfg.Cache.StoreDB = filepath.Join(cfg.Dir, "cache", "store.db")
+
+	var buf bytes.Buffer
+	if code := doctor(&buf, cfg); code != 1 {
+		t.Errorf("exit code %d, want 1 for bob's checklist", code)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"ok    personal checklist for alice",
+		"FAIL  personal checklist for bob: $.sightings[0].lat:",
+		"warn  personal checklist for carol: $: no sightings or species index",
+		"ok    every profile has a bearer token",
+		"not created yet",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	for _, path := range []string{cfg.Cache.StoreDB, filepath.Dir(cfg.Cache.StoreDB), cfg.StateDir()} {
+		if _, err := os.Stat(path); err == nil {
+			t.Errorf("doctor created %s", path)
+		}
+	}
+}
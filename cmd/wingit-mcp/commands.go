@@ -0,0 +1,105 @@
+package main
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/kpb/wingit-mcp/internal/config"
+)
+
+// buildVersion is set at link time with
+// -ldflags "-X main.buildVersion=v1.2.3"; see version.
+var buildVersion string
+
+// version is the link-time version, else the module version recorded by
+// `go install module@version`, else "dev".
+func version() string {
+	if buildVersion != "" {
+		return buildVersion
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		return bi.Main.Version
+	}
+	return "dev"
+}
+
+// runCommand runs a subcommand instead of the server and returns the
+// process exit code.
+func runCommand(cfg *config.Config, args []string) int {
+	switch args[0] {
+	case "doctor":
+		return doctor(os.Stdout, cfg)
+	case "config":
+		if len(args) == 2 && args[1] == "show" {
+			cfg.Show(os.Stdout)
+			if err := cfg.Validate(); err != nil {
+				fmt.Fprintf(os.Stdout, "\n# invalid:\n# %s\n", strings.ReplaceAll(err.Error(), "\n", "\n# "))
+				return 1
+			}
+			return 0
+		}
+	case "targets", "stats", "species":
+		logger := log.New(os.Stderr, "wingit-mcp: ", log.LstdFlags|log.Lmsgprefix)
+		return runCLI(logger, cfg, cliCommands[args[0]], args[1:], os.Stdout)
+	case "version":
+		fmt.Fprintln(os.Stdout, version())
+		return 0
+	}
+	fmt.Fprintf(os.Stderr, "wingit-mcp: unknown command %q\n", strings.Join(args, " "))
+	usage(os.Stderr)
+	return 2
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, `usage: wingit-mcp [flags] [command]
+
+With no command, serves MCP on stdio (or HTTP with --http-addr).
+
+commands:
+  targets       print a target checklist (--location, --radius, --days, --format md|csv|json)
+  stats         print life list statistics (--region, --format text|json)
+  species       look up a species and your history with it
+  doctor        check the configuration and data files
+  config show   print the effective configuration, secrets redacted
+  version       print the server version
+
+flags (each overrides its environment variable, which overrides the config file):`)
+	config.Usage(w)
+}
+
+// httpHandler serves s over streamable HTTP, behind requireToken when
+// token is set.
+func httpHandler(s *mcp.Server, token string) http.Handler {
+	var handler http.Handler = mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return s }, nil)
+	if token != "" {
+		handler = requireToken(token, handler)
+	}
+	return handler
+}
+
+// requireToken rejects HTTP requests that don't carry "Authorization:
+// Bearer <token>". The header is removed from accepted requests: the
+// transport token admits a caller but does not name a user, so it must not
+// reach users.Registry.Resolve as a per-user token.
+func requireToken(token string, next http.Handler) http.Handler {
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(got, want) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		r = r.Clone(r.Context())
+		r.Header.Del("Authorization")
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kpb/wingit-mcp/internal/config"
+	"github.com/kpb/wingit-mcp/internal/ebird"
+	"github.com/kpb/wingit-mcp/internal/state"
+	"github.com/kpb/wingit-mcp/internal/store"
+	"github.com/kpb/wingit-mcp/internal/taxonomy"
+	"github.com/kpb/wingit-mcp/internal/tools"
+	it "github.com/kpb/wingit-mcp/internal/types"
+	"github.com/kpb/wingit-mcp/internal/users"
+)
+
+// doctor checks the server's configuration without starting it: where
+// each setting came from, whether every data file loads and validates, how
+// callers authenticate, and what is cached. It returns the process exit
+// code: 1 if any check failed, 0 otherwise (warnings don't fail).
+func doctor(w io.Writer, cfg *config.Config) int {
+	d := &doctorReport{w: w}
+
+	d.section("config")
+	if cfg.File != "" {
+		d.ok("config file %s", cfg.File)
+	} else {
+		d.ok("no config file: using environment and flags")
+	}
+	if err := cfg.Validate(); err != nil {
+		if j, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range j.Unwrap() {
+				d.fail("%v", e)
+			}
+		} else {
+			d.fail("%v", err)
+		}
+	}
+	usersDir := cfg.Data.UsersDir
+	personalPath := cfg.Data.Personal
+	httpAddr := cfg.Transport.HTTPAddr
+	switch {
+	case usersDir != "":
+		d.ok("multi-user mode: data.usersDir=%s", usersDir)
+		if personalPath != "" {
+			d.warn("data.personal is ignored when data.usersDir is set")
+		}
+	case personalPath != "":
+		d.ok("single-user mode: data.personal=%s", personalPath)
+	}
+	if httpAddr != "" {
+		d.ok("transport: streamable HTTP on %s", httpAddr)
+	} else {
+		d.ok("transport: stdio")
+	}
+	d.ok("target defaults: radius %g km, %d days back, at most %d species",
+		cfg.Targets.RadiusKm, cfg.Targets.DaysBack, cfg.Targets.MaxSpecies)
+	if iv, ok := cfg.Alerts.WatchInterval(); ok {
+		d.ok("alerts: target profile queries re-run every %s", iv)
+	} else {
+		d.ok("alerts: disabled")
+	}
+
+	d.section("files")
+	var tax *taxonomy.Taxonomy
+	if path := cfg.Data.Taxonomy; path != "" {
+		if t, err := taxonomy.Load(path); err != nil {
+			d.fail("taxonomy %s: %v", path, err)
+		} else {
+			tax = t
+			d.ok("taxonomy %s: %d taxa", path, tax.Len())
+		}
+	} else {
+		d.warn("data.taxonomy not set: families, orders and species search disabled")
+	}
+	var reg *users.Registry
+	switch {
+	case usersDir != "":
+		// Profiles are read without their checklists, so each checklist is
+		// checked (and reported) on its own.
+		r, err := users.LoadProfiles(usersDir)
+		if err != nil {
+			d.fail("load users: %v", err)
+			break
+		}
+		reg = r
+		for _, u := range r.Users() {
+			d.checkPersonal(u.ID, u.Path(), tax)
+		}
+	case personalPath != "":
+		d.checkPersonal(users.DefaultUserID, personalPath, tax)
+	}
+	if path := cfg.Data.Recent; path != "" {
+		if rows, err := ebird.LoadRecentNearby(path); err != nil {
+			d.fail("recent observations %s: %v", path, err)
+		} else {
+			d.ok("recent observations %s: %d rows", path, len(rows))
+		}
+	} else {
+		d.warn("data.recent not set: targets will be empty")
+	}
+	if path := cfg.Data.TaxonomyAltNames; path != "" {
+		if _, err := taxonomy.LoadAltNames(path); err != nil {
+			d.fail("alternate names %s: %v", path, err)
+		} else {
+			d.ok("alternate names %s", path)
+		}
+	}
+	if dir := cfg.Data.LocalesDir; dir != "" {
+		if locales, err := taxonomy.LoadLocalesDir(dir); err != nil {
+			d.fail("locales %s: %v", dir, err)
+		} else {
+			d.ok("locales %s: %v", dir, locales.Available())
+		}
+	}
+
+	if path := cfg.ProfilesFile(); path == "" {
+		d.warn("no config directory: target profiles disabled")
+	} else {
+		profiles := tools.OpenTargetProfiles(path)
+		if _, err := profiles.List(""); err != nil {
+			d.fail("target profiles: %v", err)
+		} else {
+			d.ok("target profiles %s", profiles.Path())
+		}
+	}
+
+	d.section("token")
+	switch {
+	case usersDir == "":
+		d.ok("single user: no bearer tokens needed")
+	case reg == nil:
+		d.warn("bearer tokens not checked: %s did not load", users.ProfilesFile)
+	default:
+		var missing, protected []string
+		for _, u := range reg.Users() {
+			if u.HasToken() {
+				protected = append(protected, u.ID)
+			} else {
+				missing = append(missing, u.ID)
+			}
+		}
+		switch {
+		case len(missing) == 0:
+			d.ok("every profile has a bearer token")
+		case httpAddr != "":
+			d.warn("profiles without a token can be selected by any HTTP caller: %v", missing)
+		default:
+			d.ok("profiles without a token (fine over stdio): %v", missing)
+		}
+		if httpAddr == "" && len(protected) > 0 {
+			d.warn("profiles with a token can only be used over HTTP, by presenting it: %v", protected)
+		}
+	}
+
+	d.section("cache")
+	if path := cfg.Cache.StoreDB; path != "" {
+		d.checkStore(path, reg)
+	} else {
+		d.ok("no observation store: personal checklists are decoded into memory (set cache.storeDb for large life lists)")
+	}
+
+	if dir := cfg.StateDir(); dir != "" {
+		if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+			d.ok("state directory %s: not created yet (created on first run)", dir)
+		} else if st, err := state.Open(dir); err != nil {
+			d.fail("state directory: %v", err)
+		} else if keys, err := st.Keys(tools.TargetHistoryBucket); err != nil {
+			d.fail("state directory: %v", err)
+		} else {
+			d.ok("state directory %s: %d saved target queries", dir, len(keys))
+		}
+	} else {
+		d.warn("no state directory: target changes and alerts disabled")
+	}
+
+	fmt.Fprintf(w, "\n%d failed, %d warnings\n", d.failed, d.warned)
+	if d.failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+type doctorReport struct {
+	w              io.Writer
+	failed, warned int
+}
+
+func (d *doctorReport) section(name string) { fmt.Fprintf(d.w, "[%s]\n", name) }
+
+func (d *doctorReport) ok(format string, a ...any) {
+	fmt.Fprintf(d.w, "  ok    "+format+"\n", a...)
+}
+
+func (d *doctorReport) warn(format string, a ...any) {
+	d.warned++
+	fmt.Fprintf(d.w, "  warn  "+format+"\n", a...)
+}
+
+func (d *doctorReport) fail(format string, a ...any) {
+	d.failed++
+	fmt.Fprintf(d.w, "  FAIL  "+format+"\n", a...)
+}
+
+// checkPersonal validates one personal checklist, listing every problem. A
+// plain JSON export is decoded strictly; other sources and manifests are
+// loaded with the taxonomy and the merged checklist is validated.
+func (d *doctorReport) checkPersonal(userID, path string, tax *taxonomy.Taxonomy) {
+	var pc *it.PersonalChecklist
+	var rep ebird.ValidationReport
+	var err error
+	files, ferr := ebird.PersonalSourceFiles(path)
+	if ferr == nil && len(files) == 1 && strings.EqualFold(filepath.Ext(path), ".json") {
+		pc, rep, err = ebird.ValidatePersonalFile(path)
+	} else {
+		var warnings []string
+		pc, warnings, err = ebird.LoadPersonalWithWarnings(path, tax)
+		for _, w := range warnings {
+			d.warn("personal checklist for %s: %s", userID, w)
+		}
+		if err == nil {
+			rep = ebird.ValidatePersonalChecklist(pc)
+		}
+	}
+	if err != nil {
+		d.fail("personal checklist for %s: %v", userID, err)
+		return
+	}
+	for _, p := range rep.Problems {
+		if p.Severity == ebird.SeverityError {
+			d.fail("personal checklist for %s: %s: %s", userID, p.Path, p.Message)
+		} else {
+			d.warn("personal checklist for %s: %s: %s", userID, p.Path, p.Message)
+		}
+	}
+	if pc != nil && rep.Err() == nil {
+		d.ok("personal checklist for %s (%s): %d sightings, %d indexed species", userID, path, len(pc.Sightings), len(pc.SpeciesIndex))
+	}
+}
+
+// checkStore opens the observation store and reports which users have data
+// in it. Nothing is imported, and a missing store is not created: the
+// server does both on start.
+func (d *doctorReport) checkStore(path string, reg *users.Registry) {
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		d.ok("observation store %s: not created yet (created and imported on next start)", path)
+		return
+	}
+	st, err := store.Open(path)
+	if err != nil {
+		d.fail("observation store: %v", err)
+		return
+	}
+	defer st.Close()
+	ids := []string{users.DefaultUserID}
+	if reg != nil {
+		ids = reg.IDs()
+	}
+	for _, id := range ids {
+		ok, err := st.HasUser(context.Background(), id)
+		switch {
+		case err != nil:
+			d.fail("observation store %s: %v", path, err)
+		case ok:
+			d.ok("observation store %s: %s imported", path, id)
+		default:
+			d.warn("observation store %s: %s not imported yet (imported on next start)", path, id)
+		}
+	}
+}
@@ -4,180 +4,232 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 
-	"github.com/kpb/wingit-mcp/internal/prompts"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/kpb/wingit-mcp/internal/config"
 	"github.com/kpb/wingit-mcp/internal/ebird"
-	mcpi "github.com/kpb/wingit-mcp/internal/mcp"
+	"github.com/kpb/wingit-mcp/internal/state"
+	"github.com/kpb/wingit-mcp/internal/store"
+	"github.com/kpb/wingit-mcp/internal/taxonomy"
 	"github.com/kpb/wingit-mcp/internal/tools"
-	it "github.com/kpb/wingit-mcp/internal/types"
+	"github.com/kpb/wingit-mcp/internal/users"
 )
 
-func registerPrompts(s *mcp.Server) {
-	prompt := &mcp.Prompt{
-		Name:        "field_checklist",
-		Description: "Format WingIt target_checklist results as a printable field checklist.",
-		Arguments: []*mcp.PromptArgument{
-			{
-				Name:        "location",
-				Description: "Name of the birding location or general area.",
-				Required:    true,
-			},
-			{
-				Name:        "dayRange",
-				Description: "How far back the recent observations go (e.g. 'last 7 days').",
-				Required:    false,
-			},
-		},
-	}
-
-	promptHandler := func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
-		args := req.Params.Arguments
-		loc := args["location"]
-		dayRange := args["dayRange"]
-
-		if loc == "" {
-			loc = "this area"
-		}
-		if dayRange == "" {
-			dayRange = "the recent period"
-		}
+func main() {
+	// IMPORTANT: stdio servers must not write to stdout; use stderr for logs. :contentReference[oaicite:1]{index=1}
+	logger := log.New(os.Stderr, "wingit-mcp: ", log.LstdFlags|log.Lmsgprefix)
+
+	cfg, cmdArgs, err := config.Load(os.Args[1:], os.Getenv)
+	if err != nil {
+		logger.Printf("ERROR: %v", err)
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+	if len(cmdArgs) > 0 {
+		os.Exit(runCommand(cfg, cmdArgs))
+	}
+	if err := cfg.Validate(); err != nil {
+		logger.Printf("ERROR: invalid configuration (see `wingit-mcp config show`):\n%v", err)
+		os.Exit(2)
+	}
+	if cfg.Data.Recent == "" {
+		logger.Printf("INFO: data.recent not set; targets will be empty")
+	}
 
-		text := fmt.Sprintf(
-			`You are a birding assistant. The user has just called the WingIt-MCP tool "target_checklist" to get likely new lifers near %s for %s.
+	a, err := newApp(logger, cfg)
+	if err != nil {
+		logger.Printf("ERROR: %v", err)
+		os.Exit(2)
+	}
+	defer a.close()
 
-Using the tool output provided in this conversation (JSON with "targets" and "filters"), produce a concise, printable field checklist:
+	s := a.svc.NewServer(&mcp.Implementation{Name: "wingit-mcp", Version: version()})
 
-- Focus only on likely lifers (the "targets" array).
-- Group species by approximate recent frequency (high / medium / low) based on "recentFrequency".
-- For each species, show: common name, scientific name, and a short note like "seen recently at <locName>" if present.
-- Keep it compact, suitable for printing or quick reference in the field.
-- Do not reprint the raw JSON; summarize it.
+	// Re-run saved target queries and alert on newly reported needed species.
+	if interval, ok := cfg.Alerts.WatchInterval(); ok {
+		if poller := a.svc.Poller(); poller != nil {
+			go poller.Run(context.Background(), interval)
+		}
+	}
 
-If there are no targets, explain that there are no likely new lifers for this query and suggest broadening radius or daysBack.`, loc, dayRange)
+	// Watch personal checklist files and hot-reload them on change.
+	go a.svc.Users.Watch(context.Background(), users.DefaultWatchInterval, func(u *users.User, err error) {
+		if err != nil {
+			logger.Printf("WARN: reload personal checklist for %q: %v (keeping previous data)", u.ID, err)
+			return
+		}
+		logger.Printf("reloaded personal checklist for %q: species=%d", u.ID, len(u.Seen()))
+		a.svc.NotifyPersonalUpdated(context.Background())
+	})
 
-		return &mcp.GetPromptResult{
-			Description: "Format WingIt target_checklist results as a printable field checklist.",
-			Messages: []*mcp.PromptMessage{
-				{
-					Role: "user",
-					Content: &mcp.TextContent{
-						Text: text,
-					},
-				},
-			},
-		}, nil
+	// Serve over streamable HTTP when an address is configured, otherwise stdio.
+	if addr := cfg.Transport.HTTPAddr; addr != "" {
+		logger.Printf("listening on %s", addr)
+		if err := http.ListenAndServe(addr, httpHandler(s, cfg.Transport.HTTPToken)); err != nil {
+			logger.Printf("server failed: %v", err)
+		}
+		return
 	}
 
-	s.AddPrompt(prompt, promptHandler)
+	// Run the server on stdio transport.
+	if err := s.Run(context.Background(), &mcp.StdioTransport{}); err != nil {
+		logger.Printf("server failed: %v", err)
+	}
 }
 
-func main() {
-	// IMPORTANT: stdio servers must not write to stdout; use stderr for logs. :contentReference[oaicite:1]{index=1}
-	logger := log.New(os.Stderr, "wingit-mcp: ", log.LstdFlags|log.Lmsgprefix)
+// openStore opens the SQLite observation store at cache.storeDb. It is
+// optional: without it personal checklists are decoded into memory.
+func openStore(logger *log.Logger, cfg *config.Config) (*store.Store, error) {
+	path := cfg.Cache.StoreDB
+	if path == "" {
+		return nil, nil
+	}
+	st, err := store.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("store.Open(%q): %w", path, err)
+	}
+	logger.Printf("using observation store %s", path)
+	return st, nil
+}
 
-	// --- Config: load personal checklist path from env, build seen set ---
-	personalPath := os.Getenv("WINGIT_PERSONAL_JSON")
-	if personalPath == "" {
-		logger.Printf("ERROR: WINGIT_PERSONAL_JSON is not set")
-		os.Exit(2)
+// openState opens the directory where target results and alerts are kept
+// between runs. Without one, results are not saved and since finds no
+// earlier run.
+func openState(logger *log.Logger, cfg *config.Config) *state.Store {
+	dir := cfg.StateDir()
+	if dir == "" {
+		logger.Printf("WARN: no state directory (target changes and alerts disabled)")
+		return nil
 	}
-	pc, err := ebird.LoadPersonalChecklist(personalPath)
+	st, err := state.Open(dir)
 	if err != nil {
-		logger.Printf("ERROR: LoadPersonalChecklist(%q): %v", personalPath, err)
-		os.Exit(2)
+		logger.Printf("WARN: state.Open(%q): %v (target changes and alerts disabled)", dir, err)
+		return nil
 	}
-	seen := ebird.BuildPersonalSeenSet(pc)
-	logger.Printf("loaded personal checklist: species=%d (seen set size)", len(seen))
-
-	s := mcp.NewServer(&mcp.Implementation{
-		Name:    "wingit-mcp",
-		Version: "0.1.0",
-	}, nil)
-
-	// Register prompts before tools so the host sees them on initialize.
-	prompts.Register(s)
-	mcpi.RegisterResources(s, pc)
-
-	// Register the target_checklist tool.
-	// The SDK infers JSON Schema for input/output from the types you use.
-	mcp.AddTool(s, &mcp.Tool{
-		Name:        "target_checklist",
-		Description: "Return likely new lifers near a location by comparing recent eBird observations with your personal history.",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args tools.TargetArgs) (*mcp.CallToolResult, any, error) {
-		// Load "recent nearby" from env JSON (offline demo path for now).
-		recentPath := os.Getenv("WINGIT_RECENT_JSON")
-		var recent []it.RecentObservation
-		if recentPath != "" {
-			rows, err := ebird.LoadRecentNearby(recentPath)
-			if err != nil {
-				// Fail softly: log and continue with empty recent data.
-				logger.Printf("WARN: LoadRecentNearby(%q): %v (continuing with empty recent)", recentPath, err)
-			} else {
-				recent = rows
-			}
-		} else {
-			logger.Printf("INFO: WINGIT_RECENT_JSON not set; continuing with empty recent")
-		}
+	return st
+}
 
-		// Adapt internal/types -> engine's RecentObservation
-		engineRecent := make([]tools.RecentObservation, 0, len(recent))
-		for _, r := range recent {
-			engineRecent = append(engineRecent, tools.RecentObservation{
-				SpeciesCode: r.SpeciesCode,
-				CommonName:  r.CommonName,
-				SciName:     r.SciName,
-				LocName:     r.LocName,
-				LocID:       r.LocID,
-				ObsDt:       r.ObsDt,
-				HeardOnly:   r.HeardOnly,
-			})
-		}
+// openProfiles returns the saved target query presets in the config
+// directory, or nil (no presets) without one.
+func openProfiles(logger *log.Logger, cfg *config.Config) *tools.TargetProfiles {
+	path := cfg.ProfilesFile()
+	if path == "" {
+		logger.Printf("WARN: no config directory (target profiles disabled)")
+		return nil
+	}
+	return tools.OpenTargetProfiles(path)
+}
 
-		// Call the pure engine.
-		out, err := tools.BuildTargetChecklist(ctx, args, seen, engineRecent)
+// loadUsers builds the user registry. data.usersDir (a directory holding
+// users.json) enables multi-user mode; otherwise data.personal is loaded as
+// the single default user. Either may name an eBird JSON or CSV export, an
+// extra-lifers YAML file or a sources manifest; the taxonomy resolves names
+// in the latter. With a store, checklist files are imported into it and
+// served from it.
+func loadUsers(logger *log.Logger, cfg *config.Config, opts users.Options) (*users.Registry, error) {
+	if dir := cfg.Data.UsersDir; dir != "" {
+		reg, err := users.LoadDirWith(context.Background(), dir, opts)
 		if err != nil {
-			return nil, nil, err
+			return nil, fmt.Errorf("load users from %q: %w", dir, err)
 		}
+		logger.Printf("loaded user profiles: %v", reg.IDs())
+		return reg, nil
+	}
 
-		// Human-friendly text summary for host UI.
-		summary := "WingIt-MCP: no candidate lifers"
-		if n := len(out.Targets); n > 0 {
-			top := out.Targets[0].CommonName
-			summary = os.ExpandEnv(
-				// Example: “3 candidate lifers; top: Lewis's Woodpecker”
-				// (short and useful in the host’s call result view)
-				// not actually using env vars here; ExpandEnv is a no-op—just compact code.
-				// Feel free to format however you like.
-				// Avoid stdout; host reads JSON-RPC on stdout.
-				// Logging already goes to stderr via logger above.
-				// Return structured JSON too (second return value).
-				// Hosts that understand MCP structured content can render tables.
-				// Others will still show the text content.
-				// Keep it brief and actionable.
-				// n.b.: You can add more details later (e.g., location hints).
-				"",
-			)
-			summary = // short, explicit:
-				func(n int, top string) string {
-					return fmt.Sprintf("%d candidate lifers; top: %s", n, top)
-				}(n, top)
-		}
+	personalPath := cfg.Data.Personal
+	if personalPath == "" {
+		return nil, fmt.Errorf("data.personal is not set")
+	}
+	u, err := users.OpenUser(context.Background(), users.DefaultUserID, "", personalPath, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w (run `wingit-mcp doctor` for details)", personalPath, err)
+	}
+	if opts.Store != nil {
+		logger.Printf("loaded personal checklist from store: species=%d (seen set size)", len(u.Seen()))
+		return users.NewSingle(u), nil
+	}
+	pc, err := u.Checklist(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", personalPath, err)
+	}
+	if w := ebird.ValidatePersonalChecklist(pc).Warnings(); len(w) > 0 {
+		logger.Printf("WARN: %s: %d validation warnings (run `wingit-mcp doctor` for details)", personalPath, len(w))
+	}
+	logger.Printf("loaded personal checklist: species=%d (seen set size)", len(u.Seen()))
+	return users.NewSingle(u), nil
+}
 
-		res := &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: summary},
-			},
+// loadTaxonomy loads the eBird taxonomy from data.taxonomy, plus
+// former/alternate names from data.taxonomyAltNames for search. It is
+// optional: without it, taxonomy-based output (families, orders) is empty.
+func loadTaxonomy(logger *log.Logger, cfg *config.Config) *taxonomy.Taxonomy {
+	path := cfg.Data.Taxonomy
+	if path == "" {
+		logger.Printf("INFO: data.taxonomy not set; taxonomy features disabled")
+		return nil
+	}
+	tax, err := taxonomy.Load(path)
+	if err != nil {
+		logger.Printf("WARN: taxonomy.Load(%q): %v (continuing without taxonomy)", path, err)
+		return nil
+	}
+	if altPath := cfg.Data.TaxonomyAltNames; altPath != "" {
+		alt, err := taxonomy.LoadAltNames(altPath)
+		if err != nil {
+			logger.Printf("WARN: taxonomy.LoadAltNames(%q): %v (continuing without alternate names)", altPath, err)
+		} else {
+			tax = tax.WithAltNames(alt)
 		}
+	}
+	logger.Printf("loaded taxonomy: taxa=%d", tax.Len())
+	return tax
+}
 
-		// Return both: user-facing text and structured JSON (engine result).
-		return res, out, nil
-	})
+// loadLocales loads localized taxonomy files (<locale>.json) from
+// data.localesDir. Optional: without it only source names are used.
+func loadLocales(logger *log.Logger, cfg *config.Config) *taxonomy.Locales {
+	dir := cfg.Data.LocalesDir
+	if dir == "" {
+		return nil
+	}
+	locales, err := taxonomy.LoadLocalesDir(dir)
+	if err != nil {
+		logger.Printf("WARN: taxonomy.LoadLocalesDir(%q): %v (continuing without locales)", dir, err)
+		return nil
+	}
+	logger.Printf("loaded locales: %v", locales.Available())
+	return locales
+}
 
-	// Run the server on stdio transport.
-	if err := s.Run(context.Background(), &mcp.StdioTransport{}); err != nil {
-		logger.Printf("server failed: %v", err)
+// loadRecent loads "recent nearby" observations from path (offline demo
+// data for now; re-read on every call so updates show up) and adapts them
+// to the engine type. No path yields no observations.
+func loadRecent(path string) ([]tools.RecentObservation, error) {
+	if path == "" {
+		return nil, nil
+	}
+	recent, err := ebird.LoadRecentNearby(path)
+	if err != nil {
+		return nil, fmt.Errorf("LoadRecentNearby(%q): %w", path, err)
+	}
+
+	// Adapt internal/types -> engine's RecentObservation
+	engineRecent := make([]tools.RecentObservation, 0, len(recent))
+	for _, r := range recent {
+		engineRecent = append(engineRecent, tools.RecentObservation{
+			SpeciesCode: r.SpeciesCode,
+			CommonName:  r.CommonName,
+			SciName:     r.SciName,
+			LocName:     r.LocName,
+			LocID:       r.LocID,
+			Lat:         r.Lat,
+			Lng:         r.Lng,
+			ObsDt:       r.ObsDt,
+			HeardOnly:   r.HeardOnly,
+		})
 	}
+	return engineRecent, nil
 }
@@ -2,9 +2,26 @@ module github.com/kpb/wingit-mcp
 
 go 1.23.0
 
-require github.com/modelcontextprotocol/go-sdk v0.3.0
+require (
+	github.com/modelcontextprotocol/go-sdk v0.3.0
+	modernc.org/sqlite v1.34.5
+)
 
 require (
+	github.com/BurntSushi/toml v1.4.0
 	github.com/google/jsonschema-go v0.2.0
 	github.com/yosida95/uritemplate/v3 v3.0.2
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/dustin/go-humanize v1.0.1 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/ncruces/go-strftime v0.1.9 // indirect
+	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
+	golang.org/x/sys v0.22.0 // indirect
+	modernc.org/libc v1.55.3 // indirect
+	modernc.org/mathutil v1.6.0 // indirect
+	modernc.org/memory v1.8.0 // indirect
 )
@@ -0,0 +1,208 @@
+// Package alerts re-runs saved target queries in the background and records
+// an alert whenever a species the user still needs newly appears in them.
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kpb/wingit-mcp/internal/state"
+	"github.com/kpb/wingit-mcp/internal/tools"
+)
+
+const (
+	// Bucket holds each user's alerts, newest first.
+	Bucket = "alerts"
+	// Keep is how many alerts are kept per user.
+	Keep = 200
+	// watchBucket holds the result each query had at its last poll, so a
+	// species is alerted once when it appears rather than on every poll.
+	watchBucket = "watch"
+	// DefaultInterval is how often saved queries are re-run.
+	DefaultInterval = 30 * time.Minute
+)
+
+// Alert is one needed species newly reported for a saved query.
+type Alert struct {
+	At              time.Time `json:"at"`
+	User            string    `json:"user"`
+	Query           string    `json:"query"`
+	Location        string    `json:"location"`
+	SpeciesCode     string    `json:"speciesCode"`
+	CommonName      string    `json:"commonName"`
+	SciName         string    `json:"sciName"`
+	RecentFrequency float64   `json:"recentFrequency"`
+	LastSeenNearby  string    `json:"lastSeenNearby"`
+}
+
+// Message is a one-line description for logs and notifications.
+func (a Alert) Message() string {
+	return fmt.Sprintf("%s (%s) reported near %s on %s", a.CommonName, a.SpeciesCode, a.Location, a.LastSeenNearby)
+}
+
+// Clock tells the poller the time and when to poll next.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock is the wall clock.
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time                         { return time.Now() }
+func (SystemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Source supplies fresh recent observations for each poll.
+type Source interface {
+	Recent(ctx context.Context) ([]tools.RecentObservation, error)
+}
+
+// SourceFunc adapts a function to Source.
+type SourceFunc func(ctx context.Context) ([]tools.RecentObservation, error)
+
+func (f SourceFunc) Recent(ctx context.Context) ([]tools.RecentObservation, error) { return f(ctx) }
+
+// Poller re-runs the target queries saved in State (see tools.RecordTargets)
+// that Watched accepts against fresh data from Source. A target absent from the query's previous
+// result (its last poll, or the saved run before the first poll) becomes an
+// alert: appended to the user's alerts in State and passed to Notify.
+type Poller struct {
+	State  *state.Store
+	Source Source
+	// Seen returns a user's seen set; an error skips that user's queries.
+	Seen func(userID string) (map[string]struct{}, error)
+	// Watched reports whether a saved query is still to be polled; a query
+	// it rejects is skipped and its poll state dropped. Nil polls them all.
+	Watched func(tools.TargetSnapshot) bool
+	Clock   Clock
+	Notify  func(ctx context.Context, alerts []Alert)
+	// OnError reports a query that failed; polling carries on.
+	OnError func(query string, err error)
+}
+
+// Run polls every interval until ctx is done. The first poll happens one
+// interval after Run starts.
+func (p *Poller) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.Clock.After(interval):
+		}
+		if _, err := p.Poll(ctx); err != nil && p.OnError != nil {
+			p.OnError("", err)
+		}
+	}
+}
+
+// Poll re-runs each saved query once and returns the new alerts, which have
+// already been stored and passed to Notify.
+func (p *Poller) Poll(ctx context.Context) ([]Alert, error) {
+	keys, err := p.State.Keys(tools.TargetHistoryBucket)
+	if err != nil {
+		return nil, err
+	}
+	recent, err := p.Source.Recent(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("recent observations: %w", err)
+	}
+	now := p.Clock.Now()
+
+	var out []Alert
+	for _, key := range keys {
+		alerts, err := p.pollQuery(ctx, key, recent, now)
+		if err != nil {
+			if p.OnError != nil {
+				p.OnError(key, err)
+			}
+			continue
+		}
+		out = append(out, alerts...)
+	}
+	for _, a := range out {
+		if err := p.State.Save(Bucket, a.User, a.At, a, Keep); err != nil {
+			return out, err
+		}
+	}
+	if len(out) > 0 && p.Notify != nil {
+		p.Notify(ctx, out)
+	}
+	return out, nil
+}
+
+func (p *Poller) pollQuery(ctx context.Context, key string, recent []tools.RecentObservation, now time.Time) ([]Alert, error) {
+	saved, ok, err := newest[tools.TargetSnapshot](p.State, tools.TargetHistoryBucket, key)
+	if err != nil || !ok {
+		return nil, err
+	}
+	if p.Watched != nil && !p.Watched(saved) {
+		return nil, p.State.Delete(watchBucket, key)
+	}
+	prev := saved.Result
+	if last, ok, err := newest[tools.TargetResult](p.State, watchBucket, key); err != nil {
+		return nil, err
+	} else if ok {
+		prev = last
+	}
+
+	seen, err := p.Seen(saved.User)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := tools.BuildTargetChecklist(ctx, saved.Args, seen, recent)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.State.Save(watchBucket, key, now, cur, 1); err != nil {
+		return nil, err
+	}
+
+	diff := tools.DiffTargets(prev, cur)
+	alerts := make([]Alert, 0, len(diff.New))
+	for _, t := range diff.New {
+		alerts = append(alerts, Alert{
+			At: now.UTC(), User: saved.User, Query: key, Location: saved.Args.Location,
+			SpeciesCode: t.SpeciesCode, CommonName: t.CommonName, SciName: t.SciName,
+			RecentFrequency: t.RecentFrequency, LastSeenNearby: t.LastSeenNearby,
+		})
+	}
+	return alerts, nil
+}
+
+// List returns up to limit of a user's alerts, newest first (all when
+// limit <= 0).
+func List(st *state.Store, userID string, limit int) ([]Alert, error) {
+	snaps, err := st.Snapshots(Bucket, userID)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(snaps) > limit {
+		snaps = snaps[:limit]
+	}
+	out := make([]Alert, 0, len(snaps))
+	for _, s := range snaps {
+		var a Alert
+		if err := json.Unmarshal(s.Data, &a); err != nil {
+			return nil, fmt.Errorf("decode alert: %w", err)
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+func newest[T any](st *state.Store, bucket, key string) (T, bool, error) {
+	var v T
+	snaps, err := st.Snapshots(bucket, key)
+	if err != nil || len(snaps) == 0 {
+		return v, false, err
+	}
+	if err := json.Unmarshal(snaps[0].Data, &v); err != nil {
+		return v, false, fmt.Errorf("decode %s %s: %w", bucket, key, err)
+	}
+	return v, true, nil
+}
@@ -0,0 +1,152 @@
+package alerts
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kpb/wingit-mcp/internal/state"
+	"github.com/kpb/wingit-mcp/internal/tools"
+)
+
+type fakeClock struct {
+	now  time.Time
+	tick chan time.Time
+}
+
+func (c *fakeClock) Now() time.Time                       { return c.now }
+func (c *fakeClock) After(time.Duration) <-chan time.Time { return c.tick }
+
+type fakeSource struct{ rows []tools.RecentObservation }
+
+func (s *fakeSource) Recent(context.Context) ([]tools.RecentObservation, error) { return s.rows, nil }
+
+func obs(code, date string) tools.RecentObservation {
+	return tools.RecentObservation{SpeciesCode: code, CommonName: code, ObsDt: date}
+}
+
+func testPoller(t *testing.T, src *fakeSource, clock *fakeClock) *Poller {
+	t.Helper()
+	st, err := state.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("state.Open: %v", err)
+	}
+	// The morning's target_checklist run saved the query with one target.
+	saved := tools.TargetResult{Targets: []tools.TargetRow{{SpeciesCode: "lewo", RecentFrequency: 0.2}}}
+	if _, err := tools.RecordTargets(st, "me", tools.TargetArgs{Location: "Albuquerque, NM"}, saved, clock.now); err != nil {
+		t.Fatalf("RecordTargets: %v", err)
+	}
+	return &Poller{
+		State:  st,
+		Source: src,
+		Seen: func(string) (map[string]struct{}, error) {
+			return map[string]struct{}{"clanut": {}}, nil
+		},
+		Clock:   clock,
+		OnError: func(q string, err error) { t.Errorf("poll %s: %v", q, err) },
+	}
+}
+
+func codes(alerts []Alert) []string {
+	var out []string
+	for _, a := range alerts {
+		out = append(out, a.SpeciesCode)
+	}
+	return out
+}
+
+func Test_poll_alerts_once_per_newly_reported_species(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{now: time.Date(2025, 10, 20, 7, 0, 0, 0, time.UTC)}
+	src := &fakeSource{rows: []tools.RecentObservation{obs("lewo", "2025-10-19"), obs("rethaw", "2025-10-20"), obs("clanut", "2025-10-20")}}
+	p := testPoller(t, src, clock)
+	ctx := context.Background()
+
+	got, err := p.Poll(ctx)
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if c := codes(got); len(c) != 1 || c[0] != "rethaw" {
+		t.Fatalf("first poll alerts = %v, want [rethaw] (lewo was already a target, clanut is seen)", c)
+	}
+	if got[0].User != "me" || got[0].Location != "Albuquerque, NM" || !got[0].At.Equal(clock.now) {
+		t.Errorf("unexpected alert: %+v", got[0])
+	}
+
+	clock.now = clock.now.Add(30 * time.Minute)
+	if got, _ := p.Poll(ctx); len(got) != 0 {
+		t.Fatalf("second poll should not repeat alerts, got %v", codes(got))
+	}
+
+	clock.now = clock.now.Add(30 * time.Minute)
+	src.rows = append(src.rows, obs("caltow", "2025-10-20"))
+	if got, _ := p.Poll(ctx); len(got) != 1 || got[0].SpeciesCode != "caltow" {
+		t.Fatalf("third poll alerts = %v, want [caltow]", codes(got))
+	}
+
+	list, err := List(p.State, "me", 0)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if c := codes(list); len(c) != 2 || c[0] != "caltow" || c[1] != "rethaw" {
+		t.Errorf("stored alerts = %v, want newest first [caltow rethaw]", c)
+	}
+	if other, _ := List(p.State, "you", 0); len(other) != 0 {
+		t.Errorf("alerts leaked to another user: %v", codes(other))
+	}
+}
+
+func Test_poll_skips_queries_that_are_not_watched(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{now: time.Date(2025, 10, 20, 7, 0, 0, 0, time.UTC)}
+	src := &fakeSource{rows: []tools.RecentObservation{obs("rethaw", "2025-10-20")}}
+	p := testPoller(t, src, clock)
+	var asked []string
+	p.Watched = func(q tools.TargetSnapshot) bool {
+		asked = append(asked, q.User)
+		return false
+	}
+
+	got, err := p.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if len(got) != 0 || len(asked) != 1 || asked[0] != "me" {
+		t.Fatalf("Poll = %v after asking about %v; want no alerts", codes(got), asked)
+	}
+	if keys, _ := p.State.Keys(watchBucket); len(keys) != 0 {
+		t.Errorf("unwatched query kept poll state: %v", keys)
+	}
+}
+
+func Test_run_polls_on_each_tick_and_notifies(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{now: time.Date(2025, 10, 20, 7, 0, 0, 0, time.UTC), tick: make(chan time.Time)}
+	src := &fakeSource{rows: []tools.RecentObservation{obs("rethaw", "2025-10-20")}}
+	p := testPoller(t, src, clock)
+	notified := make(chan []Alert, 1)
+	p.Notify = func(_ context.Context, a []Alert) { notified <- a }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.Run(ctx, time.Minute)
+		close(done)
+	}()
+
+	clock.tick <- clock.now
+	select {
+	case a := <-notified:
+		if c := codes(a); len(c) != 1 || c[0] != "rethaw" {
+			t.Errorf("notified %v, want [rethaw]", c)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification after a tick")
+	}
+
+	cancel()
+	<-done
+}
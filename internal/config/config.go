@@ -0,0 +1,409 @@
+// Package config assembles the server's settings from, in increasing
+// precedence, built-in defaults, a TOML or YAML config file, WINGIT_*
+// environment variables and command-line flags.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"github.com/kpb/wingit-mcp/internal/tools"
+)
+
+// Config is every server setting. File keys are the lower-camel field
+// names under their table, e.g. data.personal or targets.radiusKm.
+type Config struct {
+	// Dir holds files the server writes: saved target profiles and, by
+	// default, the state directory. It also holds the default config file.
+	Dir string `toml:"dir" yaml:"dir"`
+
+	Data      Data      `toml:"data" yaml:"data"`
+	Cache     Cache     `toml:"cache" yaml:"cache"`
+	Transport Transport `toml:"transport" yaml:"transport"`
+	Targets   Targets   `toml:"targets" yaml:"targets"`
+	Alerts    Alerts    `toml:"alerts" yaml:"alerts"`
+
+	// File is the config file that was read, if any.
+	File string `toml:"-" yaml:"-"`
+	// origin records which layer set each key, for Show.
+	origin map[string]string
+}
+
+// Data names the input files.
+type Data struct {
+	// Personal is the single user's checklist, CSV, YAML or sources manifest.
+	Personal string `toml:"personal" yaml:"personal"`
+	// UsersDir holds users.json; it enables multi-user mode.
+	UsersDir         string `toml:"usersDir" yaml:"usersDir"`
+	Recent           string `toml:"recent" yaml:"recent"`
+	Taxonomy         string `toml:"taxonomy" yaml:"taxonomy"`
+	TaxonomyAltNames string `toml:"taxonomyAltNames" yaml:"taxonomyAltNames"`
+	LocalesDir       string `toml:"localesDir" yaml:"localesDir"`
+}
+
+// Cache names where derived data is kept.
+type Cache struct {
+	// StoreDB is the SQLite observation store; empty keeps checklists in memory.
+	StoreDB string `toml:"storeDb" yaml:"storeDb"`
+	// StateDir keeps target results between runs (default Dir/state).
+	StateDir string `toml:"stateDir" yaml:"stateDir"`
+}
+
+// Transport chooses how hosts connect.
+type Transport struct {
+	// HTTPAddr serves streamable HTTP on this address instead of stdio.
+	HTTPAddr string `toml:"httpAddr" yaml:"httpAddr"`
+	// HTTPToken, in single-user mode, is the bearer token HTTP callers must
+	// present. Multi-user servers use the tokens in users.json instead.
+	HTTPToken string `toml:"httpToken" yaml:"httpToken"`
+}
+
+// Targets are the defaults for target query fields a call leaves unset.
+type Targets struct {
+	RadiusKm   float64 `toml:"radiusKm" yaml:"radiusKm"`
+	DaysBack   int     `toml:"daysBack" yaml:"daysBack"`
+	MaxSpecies int     `toml:"maxSpecies" yaml:"maxSpecies"`
+}
+
+// Defaults converts t for the target tools.
+func (t Targets) Defaults() tools.TargetDefaults {
+	return tools.TargetDefaults{RadiusKm: t.RadiusKm, DaysBack: t.DaysBack, MaxSpecies: t.MaxSpecies}
+}
+
+// Alerts configures the saved-query poller.
+type Alerts struct {
+	// Interval is a Go duration, or "off" to disable polling.
+	Interval string `toml:"interval" yaml:"interval"`
+}
+
+// WatchInterval returns the poll interval, or false when polling is off.
+func (a Alerts) WatchInterval() (time.Duration, bool) {
+	if a.Interval == "off" || a.Interval == "0" {
+		return 0, false
+	}
+	d, err := time.ParseDuration(a.Interval)
+	if err != nil || d <= 0 {
+		return 0, false
+	}
+	return d, true
+}
+
+// Origins of a setting, as Show reports them.
+const (
+	OriginDefault = "default"
+	OriginFile    = "file"
+	OriginEnv     = "env"
+	OriginFlag    = "flag"
+)
+
+// setting describes one key: where it is read from and how it is stored.
+type setting struct {
+	key    string
+	env    string
+	flag   string
+	usage  string
+	secret bool
+	path   bool // relative values in the config file are file-relative
+	ptr    any  // *string, *int or *float64 into the Config
+}
+
+func (c *Config) settings() []setting {
+	return []setting{
+		{key: "dir", env: "WINGIT_CONFIG_DIR", flag: "config-dir", usage: "directory for saved profiles and state", path: true, ptr: &c.Dir},
+		{key: "data.personal", env: "WINGIT_PERSONAL_JSON", flag: "personal", usage: "personal checklist (JSON, CSV, YAML or sources manifest)", path: true, ptr: &c.Data.Personal},
+		{key: "data.usersDir", env: "WINGIT_USERS_DIR", flag: "users-dir", usage: "directory holding users.json (multi-user mode)", path: true, ptr: &c.Data.UsersDir},
+		{key: "data.recent", env: "WINGIT_RECENT_JSON", flag: "recent", usage: "recent nearby observations JSON", path: true, ptr: &c.Data.Recent},
+		{key: "data.taxonomy", env: "WINGIT_TAXONOMY_JSON", flag: "taxonomy", usage: "eBird taxonomy JSON", path: true, ptr: &c.Data.Taxonomy},
+		{key: "data.taxonomyAltNames", env: "WINGIT_TAXONOMY_ALTNAMES_JSON", flag: "taxonomy-altnames", usage: "alternate species names JSON", path: true, ptr: &c.Data.TaxonomyAltNames},
+		{key: "data.localesDir", env: "WINGIT_LOCALES_DIR", flag: "locales-dir", usage: "directory of localized common names", path: true, ptr: &c.Data.LocalesDir},
+		{key: "cache.storeDb", env: "WINGIT_STORE_DB", flag: "store-db", usage: "SQLite observation store", path: true, ptr: &c.Cache.StoreDB},
+		{key: "cache.stateDir", env: "WINGIT_STATE_DIR", flag: "state-dir", usage: "directory for saved target results and alerts", path: true, ptr: &c.Cache.StateDir},
+		{key: "transport.httpAddr", env: "WINGIT_HTTP_ADDR", flag: "http-addr", usage: "serve streamable HTTP on this address instead of stdio", ptr: &c.Transport.HTTPAddr},
+		{key: "transport.httpToken", env: "WINGIT_HTTP_TOKEN", flag: "http-token", usage: "bearer token HTTP callers must send (single-user mode)", secret: true, ptr: &c.Transport.HTTPToken},
+		{key: "targets.radiusKm", env: "WINGIT_RADIUS_KM", flag: "radius", usage: "default target search radius in km", ptr: &c.Targets.RadiusKm},
+		{key: "targets.daysBack", env: "WINGIT_DAYS_BACK", flag: "days", usage: "default days of recent observations", ptr: &c.Targets.DaysBack},
+		{key: "targets.maxSpecies", env: "WINGIT_MAX_SPECIES", flag: "max-species", usage: "default cap on targets returned", ptr: &c.Targets.MaxSpecies},
+		{key: "alerts.interval", env: "WINGIT_WATCH_INTERVAL", flag: "watch-interval", usage: `how often saved target queries are re-run ("off" disables)`, ptr: &c.Alerts.Interval},
+	}
+}
+
+// Default returns the built-in settings.
+func Default() *Config {
+	d := tools.BuiltinTargetDefaults()
+	c := &Config{
+		Targets: Targets{RadiusKm: d.RadiusKm, DaysBack: d.DaysBack, MaxSpecies: d.MaxSpecies},
+		Alerts:  Alerts{Interval: "30m"},
+		origin:  map[string]string{},
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		c.Dir = filepath.Join(dir, "wingit-mcp")
+	}
+	for _, s := range c.settings() {
+		c.origin[s.key] = OriginDefault
+	}
+	return c
+}
+
+// Load builds the config for args (the command line without the program
+// name). Flags come first; the remaining arguments, such as a subcommand,
+// are returned. getenv is usually os.Getenv. The file is the --config flag,
+// else WINGIT_CONFIG, else config.toml, config.yaml or config.yml in the
+// config directory if one exists. Load does not validate: see Validate.
+func Load(args []string, getenv func(string) string) (*Config, []string, error) {
+	c := Default()
+
+	fs := flag.NewFlagSet("wingit-mcp", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	file := fs.String("config", "", "config file (TOML or YAML)")
+	flagVals := map[string]*string{}
+	for _, s := range c.settings() {
+		flagVals[s.flag] = fs.String(s.flag, "", s.usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	setFlags := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+
+	// The config directory decides where the default file is, so resolve
+	// it from env and flags before reading the file.
+	if v := getenv("WINGIT_CONFIG_DIR"); v != "" {
+		c.Dir = v
+	}
+	if setFlags["config-dir"] {
+		c.Dir = *flagVals["config-dir"]
+	}
+	path := *file
+	if path == "" {
+		path = getenv("WINGIT_CONFIG")
+	}
+	if path == "" && c.Dir != "" {
+		for _, name := range []string{"config.toml", "config.yaml", "config.yml"} {
+			if p := filepath.Join(c.Dir, name); fileExists(p) {
+				path = p
+				break
+			}
+		}
+	}
+	if path != "" {
+		if err := c.readFile(path); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, s := range c.settings() {
+		if v := getenv(s.env); v != "" {
+			if err := set(s, v); err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", s.env, err)
+			}
+			c.origin[s.key] = OriginEnv
+		}
+	}
+	for _, s := range c.settings() {
+		if setFlags[s.flag] {
+			if err := set(s, *flagVals[s.flag]); err != nil {
+				return nil, nil, fmt.Errorf("--%s: %w", s.flag, err)
+			}
+			c.origin[s.key] = OriginFlag
+		}
+	}
+	return c, fs.Args(), nil
+}
+
+// readFile decodes a TOML or YAML file (by extension) over c. Unknown keys
+// are errors so typos don't pass silently.
+func (c *Config) readFile(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+	before := map[string]string{}
+	for _, s := range c.settings() {
+		before[s.key] = valueString(s)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		md, err := toml.Decode(string(b), c)
+		if err != nil {
+			return fmt.Errorf("config %s: %w", path, err)
+		}
+		if und := md.Undecoded(); len(und) > 0 {
+			return fmt.Errorf("config %s: unknown key %s", path, und[0])
+		}
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(b))
+		dec.KnownFields(true)
+		if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("config %s: %w", path, err)
+		}
+	default:
+		return fmt.Errorf("config %s: want a .toml, .yaml or .yml file", path)
+	}
+
+	c.File = path
+	for _, s := range c.settings() {
+		if valueString(s) == before[s.key] {
+			continue
+		}
+		c.origin[s.key] = OriginFile
+		if p, ok := s.ptr.(*string); ok && s.path && *p != "" && !filepath.IsAbs(*p) {
+			*p = filepath.Join(filepath.Dir(path), *p)
+		}
+	}
+	return nil
+}
+
+func set(s setting, v string) error {
+	switch p := s.ptr.(type) {
+	case *string:
+		*p = v
+	case *int:
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", v)
+		}
+		*p = n
+	case *float64:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", v)
+		}
+		*p = f
+	}
+	return nil
+}
+
+func valueString(s setting) string {
+	switch p := s.ptr.(type) {
+	case *string:
+		return *p
+	case *int:
+		return strconv.Itoa(*p)
+	case *float64:
+		return strconv.FormatFloat(*p, 'g', -1, 64)
+	}
+	return ""
+}
+
+// Validate reports every problem that would stop the server from starting,
+// joined into one error.
+func (c *Config) Validate() error {
+	var errs []error
+	bad := func(format string, a ...any) { errs = append(errs, fmt.Errorf(format, a...)) }
+
+	if c.Data.Personal == "" && c.Data.UsersDir == "" {
+		bad("data.personal or data.usersDir is required (WINGIT_PERSONAL_JSON / WINGIT_USERS_DIR)")
+	}
+	for _, f := range []struct{ key, path string }{
+		{"data.personal", c.Data.Personal},
+		{"data.recent", c.Data.Recent},
+		{"data.taxonomy", c.Data.Taxonomy},
+		{"data.taxonomyAltNames", c.Data.TaxonomyAltNames},
+	} {
+		if f.path != "" && !fileExists(f.path) {
+			bad("%s: %s does not exist", f.key, f.path)
+		}
+	}
+	for _, d := range []struct{ key, path string }{
+		{"data.usersDir", c.Data.UsersDir},
+		{"data.localesDir", c.Data.LocalesDir},
+	} {
+		if fi, err := os.Stat(d.path); d.path != "" && (err != nil || !fi.IsDir()) {
+			bad("%s: %s is not a directory", d.key, d.path)
+		}
+	}
+	if c.Transport.HTTPAddr != "" {
+		if _, _, err := net.SplitHostPort(c.Transport.HTTPAddr); err != nil {
+			bad("transport.httpAddr: %v", err)
+		}
+	}
+	if c.Transport.HTTPToken != "" && c.Data.UsersDir != "" {
+		bad("transport.httpToken applies to single-user mode; set tokens in users.json instead")
+	}
+	if c.Targets.RadiusKm <= 0 || c.Targets.RadiusKm > 50 {
+		bad("targets.radiusKm: %g is outside 0-50 km", c.Targets.RadiusKm)
+	}
+	if c.Targets.DaysBack < 1 || c.Targets.DaysBack > 30 {
+		bad("targets.daysBack: %d is outside 1-30", c.Targets.DaysBack)
+	}
+	if c.Targets.MaxSpecies < 1 {
+		bad("targets.maxSpecies: %d must be positive", c.Targets.MaxSpecies)
+	}
+	if v := c.Alerts.Interval; v != "off" && v != "0" {
+		if d, err := time.ParseDuration(v); err != nil || d <= 0 {
+			bad("alerts.interval: %q is not a positive duration or \"off\"", v)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// StateDir is Cache.StateDir, defaulting to Dir/state.
+func (c *Config) StateDir() string {
+	if c.Cache.StateDir != "" || c.Dir == "" {
+		return c.Cache.StateDir
+	}
+	return filepath.Join(c.Dir, "state")
+}
+
+// ProfilesFile is where saved target profiles live, or "" without Dir.
+func (c *Config) ProfilesFile() string {
+	if c.Dir == "" {
+		return ""
+	}
+	return filepath.Join(c.Dir, "profiles.json")
+}
+
+// Show writes every setting with where it came from, secrets redacted.
+func (c *Config) Show(w io.Writer) {
+	if c.File != "" {
+		fmt.Fprintf(w, "# config file: %s\n", c.File)
+	} else {
+		fmt.Fprintln(w, "# config file: none")
+	}
+	settings := c.settings()
+	sort.SliceStable(settings, func(i, j int) bool {
+		// Keep the file's table order but list top-level keys first.
+		return !strings.Contains(settings[i].key, ".") && strings.Contains(settings[j].key, ".")
+	})
+	for _, s := range settings {
+		v := valueString(s)
+		if s.secret && v != "" {
+			v = "<redacted>"
+		}
+		src := c.origin[s.key]
+		switch src {
+		case OriginEnv:
+			src += " " + s.env
+		case OriginFlag:
+			src += " --" + s.flag
+		}
+		fmt.Fprintf(w, "%-22s = %-40q # %s\n", s.key, v, src)
+	}
+}
+
+// Usage writes the flags Load accepts.
+func Usage(w io.Writer) {
+	fmt.Fprintf(w, "  --%-18s %s\n", "config", "config file (TOML or YAML); also WINGIT_CONFIG")
+	for _, s := range Default().settings() {
+		fmt.Fprintf(w, "  --%-18s %s (%s)\n", s.flag, s.usage, s.env)
+	}
+}
+
+func fileExists(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && !fi.IsDir()
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func env(m map[string]string) func(string) string {
+	return func(k string) string { return m[k] }
+}
+
+func Test_load_layers_file_env_and_flags(t *testing.T) {
+	t.Parallel()
+
+	getenv := env(map[string]string{
+		"WINGIT_CONFIG":    "testdata/config.toml",
+		"WINGIT_DAYS_BACK": "7",
+		"WINGIT_RADIUS_KM": "20",
+	})
+	c, rest, err := Load([]string{"--radius", "3", "doctor"}, getenv)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(rest) != 1 || rest[0] != "doctor" {
+		t.Errorf("expected the subcommand left over, got %v", rest)
+	}
+	if c.Targets.RadiusKm != 3 {
+		t.Errorf("flag should beat env and file: radius %g", c.Targets.RadiusKm)
+	}
+	if c.Targets.DaysBack != 7 {
+		t.Errorf("env should beat file: daysBack %d", c.Targets.DaysBack)
+	}
+	if c.Targets.MaxSpecies != Default().Targets.MaxSpecies {
+		t.Errorf("unset key should keep its default: maxSpecies %d", c.Targets.MaxSpecies)
+	}
+	if want := filepath.Join("testdata", "personal.json"); c.Data.Personal != want {
+		t.Errorf("relative file paths should resolve against the file: got %q, want %q", c.Data.Personal, want)
+	}
+	if c.Data.Recent != "/data/recent.json" {
+		t.Errorf("absolute paths are kept: got %q", c.Data.Recent)
+	}
+	if want := filepath.Join("testdata", "state-home", "state"); c.StateDir() != want {
+		t.Errorf("state dir should default under dir: got %q, want %q", c.StateDir(), want)
+	}
+}
+
+func Test_load_reads_yaml(t *testing.T) {
+	t.Parallel()
+
+	c, _, err := Load([]string{"--config", "testdata/config.yaml"}, env(nil))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if c.Data.UsersDir != filepath.Join("testdata", "users") || c.Targets.MaxSpecies != 10 {
+		t.Errorf("unexpected config: %+v", c)
+	}
+	if _, ok := c.Alerts.WatchInterval(); ok {
+		t.Errorf("expected polling off")
+	}
+}
+
+func Test_load_rejects_unknown_keys_and_bad_values(t *testing.T) {
+	t.Parallel()
+
+	if _, _, err := Load([]string{"--config", "testdata/typo.toml"}, env(nil)); err == nil || !strings.Contains(err.Error(), "targets.radius") {
+		t.Errorf("expected an unknown key error, got %v", err)
+	}
+	if _, _, err := Load(nil, env(map[string]string{"WINGIT_DAYS_BACK": "week"})); err == nil || !strings.Contains(err.Error(), "WINGIT_DAYS_BACK") {
+		t.Errorf("expected a bad env value error, got %v", err)
+	}
+	if _, _, err := Load([]string{"--nope"}, env(nil)); err == nil {
+		t.Errorf("expected an unknown flag error")
+	}
+}
+
+func Test_validate_reports_every_problem(t *testing.T) {
+	t.Parallel()
+
+	c := Default()
+	c.Dir = t.TempDir()
+	if err := c.Validate(); err == nil {
+		t.Fatalf("expected missing personal data to be an error")
+	}
+
+	personal := filepath.Join(c.Dir, "personal.json")
+	if err := os.WriteFile(personal, []byte("[]"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c.Data.Personal = personal
+	if err := c.Validate(); err != nil {
+		t.Fatalf("expected a valid config, got %v", err)
+	}
+
+	c.Data.Recent = filepath.Join(c.Dir, "missing.json")
+	c.Transport.HTTPAddr = "8080"
+	c.Targets.RadiusKm = 80
+	c.Alerts.Interval = "often"
+	err := c.Validate()
+	var j interface{ Unwrap() []error }
+	if !errors.As(err, &j) || len(j.Unwrap()) != 4 {
+		t.Fatalf("expected 4 problems, got %v", err)
+	}
+	for _, key := range []string{"data.recent", "transport.httpAddr", "targets.radiusKm", "alerts.interval"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("expected a problem with %s in %v", key, err)
+		}
+	}
+}
+
+func Test_show_redacts_secrets_and_names_origins(t *testing.T) {
+	t.Parallel()
+
+	c, _, err := Load([]string{"--config", "testdata/config.toml", "--days", "2"}, env(map[string]string{"WINGIT_MAX_SPECIES": "9"}))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	var buf bytes.Buffer
+	c.Show(&buf)
+	out := buf.String()
+	if strings.Contains(out, "s3cret") || !strings.Contains(out, "<redacted>") {
+		t.Errorf("expected the token redacted:\n%s", out)
+	}
+	for _, want := range []string{"# config file: testdata/config.toml", "# flag --days", "# env WINGIT_MAX_SPECIES", "# file", "# default"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+}
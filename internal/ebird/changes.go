@@ -0,0 +1,172 @@
+// internal/ebird/changes.go
+package ebird
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	it "github.com/kpb/wingit-mcp/internal/types"
+)
+
+// SightingKey identifies a sighting across exports: its checklist and
+// species, or its date, location and species when it has no checklist ID.
+func SightingKey(s it.PersonalSighting) string {
+	if s.ChecklistID != "" {
+		return s.ChecklistID + "/" + s.SpeciesCode
+	}
+	return "~" + s.ObsDt + "/" + s.LocID + "/" + s.SpeciesCode
+}
+
+// Dedupe returns a copy of pc keeping the first sighting for each
+// SightingKey, and how many duplicates were dropped.
+func Dedupe(pc *it.PersonalChecklist) (*it.PersonalChecklist, int) {
+	out := &it.PersonalChecklist{Meta: pc.Meta, SpeciesIndex: pc.SpeciesIndex}
+	out.Sightings = make([]it.PersonalSighting, 0, len(pc.Sightings))
+	seen := make(map[string]bool, len(pc.Sightings))
+	dupes := 0
+	for _, s := range pc.Sightings {
+		key := SightingKey(s)
+		if seen[key] {
+			dupes++
+			continue
+		}
+		seen[key] = true
+		out.Sightings = append(out.Sightings, s)
+	}
+	return out, dupes
+}
+
+// ChangeReport describes what a new export changes relative to the data
+// already loaded. Checklist IDs and species codes are sorted.
+type ChangeReport struct {
+	NewLifers         []Lifer  `json:"newLifers"`
+	LostSpecies       []string `json:"lostSpecies"`
+	NewChecklists     []string `json:"newChecklists"`
+	EditedChecklists  []string `json:"editedChecklists"`
+	DeletedChecklists []string `json:"deletedChecklists"`
+	SightingsAdded    int      `json:"sightingsAdded"`
+	SightingsEdited   int      `json:"sightingsEdited"`
+	SightingsRemoved  int      `json:"sightingsRemoved"`
+	DuplicatesDropped int      `json:"duplicatesDropped"`
+}
+
+// Changed reports whether the new export differs from the old data at all.
+func (r ChangeReport) Changed() bool {
+	return len(r.NewLifers) > 0 || len(r.LostSpecies) > 0 || r.SightingsAdded > 0 ||
+		r.SightingsEdited > 0 || r.SightingsRemoved > 0
+}
+
+// Summary is a one-line description such as
+// "3 new lifers, 12 new checklists, 1 deleted".
+func (r ChangeReport) Summary() string {
+	if !r.Changed() {
+		return "no changes"
+	}
+	var parts []string
+	add := func(n int, one, many string) {
+		if n == 1 {
+			parts = append(parts, "1 "+one)
+		} else if n > 1 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, many))
+		}
+	}
+	add(len(r.NewLifers), "new lifer", "new lifers")
+	add(len(r.NewChecklists), "new checklist", "new checklists")
+	add(len(r.EditedChecklists), "edited", "edited")
+	add(len(r.DeletedChecklists), "deleted", "deleted")
+	add(len(r.LostSpecies), "species dropped from life list", "species dropped from life list")
+	if len(parts) == 0 {
+		// Only sightings without checklist IDs changed.
+		add(r.SightingsAdded, "sighting added", "sightings added")
+		add(r.SightingsEdited, "sighting edited", "sightings edited")
+		add(r.SightingsRemoved, "sighting removed", "sightings removed")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// DiffChecklists compares the next export with the old data. Sightings are
+// matched by SightingKey; a checklist counts as edited when any of its
+// sightings was added, changed or removed, and as new or deleted when it
+// appears on only one side. Lifers are judged on the seen set (sightings
+// plus species index) of each side.
+func DiffChecklists(old, next *it.PersonalChecklist) ChangeReport {
+	r := ChangeReport{
+		NewLifers: []Lifer{}, LostSpecies: []string{},
+		NewChecklists: []string{}, EditedChecklists: []string{}, DeletedChecklists: []string{},
+	}
+
+	oldBy := make(map[string]it.PersonalSighting, len(old.Sightings))
+	oldLists := map[string]bool{}
+	for _, s := range old.Sightings {
+		oldBy[SightingKey(s)] = s
+		if s.ChecklistID != "" {
+			oldLists[s.ChecklistID] = true
+		}
+	}
+	newKeys := make(map[string]bool, len(next.Sightings))
+	newLists := map[string]bool{}
+	edited := map[string]bool{}
+	for _, s := range next.Sightings {
+		key := SightingKey(s)
+		if newKeys[key] {
+			r.DuplicatesDropped++
+			continue
+		}
+		newKeys[key] = true
+		if s.ChecklistID != "" {
+			newLists[s.ChecklistID] = true
+		}
+		prev, ok := oldBy[key]
+		switch {
+		case !ok:
+			r.SightingsAdded++
+		case prev != s:
+			r.SightingsEdited++
+		default:
+			continue
+		}
+		if s.ChecklistID != "" {
+			edited[s.ChecklistID] = true
+		}
+	}
+	for key, s := range oldBy {
+		if newKeys[key] {
+			continue
+		}
+		r.SightingsRemoved++
+		if s.ChecklistID != "" {
+			edited[s.ChecklistID] = true
+		}
+	}
+
+	for id := range newLists {
+		if !oldLists[id] {
+			r.NewChecklists = append(r.NewChecklists, id)
+		} else if edited[id] {
+			r.EditedChecklists = append(r.EditedChecklists, id)
+		}
+	}
+	for id := range oldLists {
+		if !newLists[id] {
+			r.DeletedChecklists = append(r.DeletedChecklists, id)
+		}
+	}
+	sort.Strings(r.NewChecklists)
+	sort.Strings(r.EditedChecklists)
+	sort.Strings(r.DeletedChecklists)
+
+	oldSeen, newSeen := BuildPersonalSeenSet(old), BuildPersonalSeenSet(next)
+	for _, l := range LifeList(next) {
+		if _, ok := oldSeen[l.SpeciesCode]; !ok {
+			r.NewLifers = append(r.NewLifers, l)
+		}
+	}
+	for code := range oldSeen {
+		if _, ok := newSeen[code]; !ok {
+			r.LostSpecies = append(r.LostSpecies, code)
+		}
+	}
+	sort.Strings(r.LostSpecies)
+	return r
+}
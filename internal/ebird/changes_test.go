@@ -0,0 +1,52 @@
+package ebird
+
+import (
+	"testing"
+
+	it "github.com/kpb/wingit-mcp/internal/types"
+)
+
+func Test_diff_checklists_detects_edits_and_deletions(t *testing.T) {
+	t.Parallel()
+
+	old := &it.PersonalChecklist{Sightings: []it.PersonalSighting{
+		{SpeciesCode: "clanut", ChecklistID: "S1", ObsDt: "2025-01-01", Count: 1},
+		{SpeciesCode: "amgold", ChecklistID: "S1", ObsDt: "2025-01-01", Count: 3},
+		{SpeciesCode: "amgold", ChecklistID: "S2", ObsDt: "2025-02-01", Count: 2},
+		{SpeciesCode: "stejay", ChecklistID: "S3", ObsDt: "2025-03-01", Count: 1},
+	}}
+	next := &it.PersonalChecklist{Sightings: []it.PersonalSighting{
+		{SpeciesCode: "clanut", ChecklistID: "S1", ObsDt: "2025-01-01", Count: 1},
+		{SpeciesCode: "amgold", ChecklistID: "S1", ObsDt: "2025-01-01", Count: 5}, // edited count
+		{SpeciesCode: "amgold", ChecklistID: "S2", ObsDt: "2025-02-01", Count: 2},
+		{SpeciesCode: "lewo", ChecklistID: "S4", ObsDt: "2025-04-01", Count: 1},
+		{SpeciesCode: "pinsis", ChecklistID: "S4", ObsDt: "2025-04-01", Count: 6},
+		{SpeciesCode: "pinsis", ChecklistID: "S4", ObsDt: "2025-04-01", Count: 6},
+	}}
+
+	r := DiffChecklists(old, next)
+	if len(r.NewLifers) != 2 || r.NewLifers[0].SpeciesCode != "lewo" {
+		t.Errorf("new lifers = %+v", r.NewLifers)
+	}
+	if len(r.LostSpecies) != 1 || r.LostSpecies[0] != "stejay" {
+		t.Errorf("lost species = %v", r.LostSpecies)
+	}
+	if len(r.NewChecklists) != 1 || len(r.EditedChecklists) != 1 || r.EditedChecklists[0] != "S1" ||
+		len(r.DeletedChecklists) != 1 || r.DeletedChecklists[0] != "S3" {
+		t.Errorf("checklists: new %v edited %v deleted %v", r.NewChecklists, r.EditedChecklists, r.DeletedChecklists)
+	}
+	if r.SightingsAdded != 2 || r.SightingsEdited != 1 || r.SightingsRemoved != 1 || r.DuplicatesDropped != 1 {
+		t.Errorf("sighting counts = %+v", r)
+	}
+	if got, want := r.Summary(), "2 new lifers, 1 new checklist, 1 edited, 1 deleted, 1 species dropped from life list"; got != want {
+		t.Errorf("Summary = %q, want %q", got, want)
+	}
+
+	deduped, dupes := Dedupe(next)
+	if dupes != 1 || len(deduped.Sightings) != 5 {
+		t.Errorf("Dedupe = %d sightings, %d dupes", len(deduped.Sightings), dupes)
+	}
+	if DiffChecklists(deduped, deduped).Changed() {
+		t.Errorf("identical checklists should report no changes")
+	}
+}
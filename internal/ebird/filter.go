@@ -0,0 +1,81 @@
+// internal/ebird/filter.go
+package ebird
+
+import (
+	it "github.com/kpb/wingit-mcp/internal/types"
+)
+
+// Filter narrows a personal checklist to the sightings a query needs. Empty
+// fields match everything; From and To bound obsDt by date, inclusive.
+type Filter struct {
+	SpeciesCodes []string
+	LocID        string
+	ChecklistID  string
+	CountyCode   string
+	From         string
+	To           string
+}
+
+// Empty reports whether f matches every sighting.
+func (f Filter) Empty() bool {
+	return len(f.SpeciesCodes) == 0 && f.LocID == "" && f.ChecklistID == "" && f.CountyCode == "" && f.From == "" && f.To == ""
+}
+
+// SpeciesOnly reports whether f constrains nothing but species. SpeciesIndex
+// entries describe a subset only when f is empty or species-only.
+func (f Filter) SpeciesOnly() bool {
+	return len(f.SpeciesCodes) > 0 && f.LocID == "" && f.ChecklistID == "" && f.CountyCode == "" && f.From == "" && f.To == ""
+}
+
+// Match reports whether s passes every constraint in f.
+func (f Filter) Match(s it.PersonalSighting) bool {
+	if len(f.SpeciesCodes) > 0 && !contains(f.SpeciesCodes, s.SpeciesCode) {
+		return false
+	}
+	if f.LocID != "" && s.LocID != f.LocID {
+		return false
+	}
+	if f.ChecklistID != "" && s.ChecklistID != f.ChecklistID {
+		return false
+	}
+	if f.CountyCode != "" && s.CountyCode != f.CountyCode {
+		return false
+	}
+	date := dateOnly(s.ObsDt)
+	if f.From != "" && date < f.From {
+		return false
+	}
+	if f.To != "" && date > f.To {
+		return false
+	}
+	return true
+}
+
+// Subset returns a checklist holding the sightings of pc that match f, plus
+// the SpeciesIndex entries of the filtered species when f is empty or
+// species-only. Meta is copied unchanged.
+func Subset(pc *it.PersonalChecklist, f Filter) *it.PersonalChecklist {
+	out := &it.PersonalChecklist{Meta: pc.Meta}
+	for _, s := range pc.Sightings {
+		if f.Match(s) {
+			out.Sightings = append(out.Sightings, s)
+		}
+	}
+	if f.Empty() || f.SpeciesOnly() {
+		for _, si := range pc.SpeciesIndex {
+			if f.Empty() || contains(f.SpeciesCodes, si.SpeciesCode) {
+				out.SpeciesIndex = append(out.SpeciesIndex, si)
+			}
+		}
+	}
+	return out
+}
+
+func contains(list []string, v string) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
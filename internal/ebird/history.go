@@ -0,0 +1,323 @@
+// internal/ebird/history.go
+package ebird
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/kpb/wingit-mcp/internal/taxonomy"
+	it "github.com/kpb/wingit-mcp/internal/types"
+)
+
+// LocationRef names a location a species was seen at.
+type LocationRef struct {
+	LocID   string `json:"locId"`
+	LocName string `json:"locName,omitempty"`
+}
+
+// SpeciesHistory is the user's life history for one species.
+type SpeciesHistory struct {
+	SpeciesCode     string                `json:"speciesCode"`
+	CommonName      string                `json:"commonName"`
+	SciName         string                `json:"sciName"`
+	FirstSeen       string                `json:"firstSeen"`
+	LastSeen        string                `json:"lastSeen"`
+	TotalChecklists int                   `json:"totalChecklists"`
+	TotalCount      int                   `json:"totalCount"`
+	Locations       []LocationRef         `json:"locations"`
+	Sightings       []it.PersonalSighting `json:"sightings"`
+}
+
+// SpeciesTally counts one species within a location or checklist.
+type SpeciesTally struct {
+	SpeciesCode string `json:"speciesCode"`
+	CommonName  string `json:"commonName"`
+	Sightings   int    `json:"sightings"`
+	TotalCount  int    `json:"totalCount"`
+}
+
+// LocationHistory is the user's birding history at one location.
+type LocationHistory struct {
+	LocID      string         `json:"locId"`
+	LocName    string         `json:"locName"`
+	CountyCode string         `json:"countyCode,omitempty"`
+	Lat        float64        `json:"lat"`
+	Lng        float64        `json:"lng"`
+	FirstVisit string         `json:"firstVisit"`
+	LastVisit  string         `json:"lastVisit"`
+	Checklists []string       `json:"checklists"`
+	Species    []SpeciesTally `json:"species"`
+}
+
+// ChecklistHistory is one of the user's checklists.
+type ChecklistHistory struct {
+	ChecklistID string                `json:"checklistId"`
+	ObsDt       string                `json:"obsDt"`
+	LocID       string                `json:"locId"`
+	LocName     string                `json:"locName"`
+	CountyCode  string                `json:"countyCode,omitempty"`
+	Species     []it.PersonalSighting `json:"species"`
+	// Lifers lists species whose first recorded sighting is on this checklist.
+	Lifers []string `json:"lifers"`
+}
+
+// FindSpeciesHistory returns the history for code, preferring the aggregated
+// SpeciesIndex for totals and falling back to Sightings. ok is false when the
+// user has no record of the species.
+func FindSpeciesHistory(pc *it.PersonalChecklist, code string) (SpeciesHistory, bool) {
+	h := SpeciesHistory{SpeciesCode: code, Locations: []LocationRef{}, Sightings: []it.PersonalSighting{}}
+	found := false
+
+	locNames := map[string]string{}
+	checklists := map[string]bool{}
+	for _, s := range pc.Sightings {
+		if s.SpeciesCode != code {
+			continue
+		}
+		found = true
+		h.Sightings = append(h.Sightings, s)
+		h.CommonName, h.SciName = s.CommonName, s.SciName
+		h.FirstSeen = minDate(h.FirstSeen, s.ObsDt)
+		h.LastSeen = maxDate(h.LastSeen, s.ObsDt)
+		h.TotalCount += s.Count
+		if s.ChecklistID != "" {
+			checklists[s.ChecklistID] = true
+		}
+		if s.LocID != "" {
+			locNames[s.LocID] = s.LocName
+		}
+	}
+	h.TotalChecklists = len(checklists)
+
+	for _, si := range pc.SpeciesIndex {
+		if si.SpeciesCode != code {
+			continue
+		}
+		found = true
+		h.CommonName, h.SciName = si.CommonName, si.SciName
+		h.FirstSeen = minDate(h.FirstSeen, si.FirstSeen)
+		h.LastSeen = maxDate(h.LastSeen, si.LastSeen)
+		if si.TotalChecklists > h.TotalChecklists {
+			h.TotalChecklists = si.TotalChecklists
+		}
+		if si.TotalCount > h.TotalCount {
+			h.TotalCount = si.TotalCount
+		}
+		for _, id := range si.Locations {
+			if _, ok := locNames[id]; !ok {
+				locNames[id] = ""
+			}
+		}
+		break
+	}
+
+	for id, name := range locNames {
+		h.Locations = append(h.Locations, LocationRef{LocID: id, LocName: name})
+	}
+	sort.Slice(h.Locations, func(i, j int) bool { return h.Locations[i].LocID < h.Locations[j].LocID })
+	sortSightingsByDate(h.Sightings)
+	return h, found
+}
+
+// FindLocationHistory returns the user's history at locID.
+func FindLocationHistory(pc *it.PersonalChecklist, locID string) (LocationHistory, bool) {
+	h := LocationHistory{LocID: locID, Checklists: []string{}, Species: []SpeciesTally{}}
+	checklists := map[string]bool{}
+	tallies := map[string]*SpeciesTally{}
+	for _, s := range pc.Sightings {
+		if s.LocID != locID {
+			continue
+		}
+		h.LocName, h.CountyCode, h.Lat, h.Lng = s.LocName, s.CountyCode, s.Lat, s.Lng
+		h.FirstVisit = minDate(h.FirstVisit, s.ObsDt)
+		h.LastVisit = maxDate(h.LastVisit, s.ObsDt)
+		if s.ChecklistID != "" && !checklists[s.ChecklistID] {
+			checklists[s.ChecklistID] = true
+			h.Checklists = append(h.Checklists, s.ChecklistID)
+		}
+		t, ok := tallies[s.SpeciesCode]
+		if !ok {
+			t = &SpeciesTally{SpeciesCode: s.SpeciesCode, CommonName: s.CommonName}
+			tallies[s.SpeciesCode] = t
+		}
+		t.Sightings++
+		t.TotalCount += s.Count
+	}
+	if len(tallies) == 0 {
+		return h, false
+	}
+	for _, t := range tallies {
+		h.Species = append(h.Species, *t)
+	}
+	sort.Slice(h.Species, func(i, j int) bool { return h.Species[i].SpeciesCode < h.Species[j].SpeciesCode })
+	sort.Strings(h.Checklists)
+	return h, true
+}
+
+// FindChecklistHistory returns the sightings recorded on checklistID. Its
+// lifers are the species whose life-list entry is this checklist.
+func FindChecklistHistory(pc *it.PersonalChecklist, checklistID string) (ChecklistHistory, bool) {
+	h := ChecklistHistory{ChecklistID: checklistID, Species: []it.PersonalSighting{}, Lifers: []string{}}
+	for _, s := range pc.Sightings {
+		if s.ChecklistID != checklistID {
+			continue
+		}
+		h.ObsDt, h.LocID, h.LocName, h.CountyCode = s.ObsDt, s.LocID, s.LocName, s.CountyCode
+		h.Species = append(h.Species, s)
+	}
+	if len(h.Species) == 0 {
+		return h, false
+	}
+	for _, l := range LifeList(pc) {
+		if l.ChecklistID == checklistID {
+			h.Lifers = append(h.Lifers, l.SpeciesCode)
+		}
+	}
+	return h, true
+}
+
+// SpeciesCodes returns every species code in the checklist, sorted.
+func SpeciesCodes(pc *it.PersonalChecklist) []string {
+	set := map[string]bool{}
+	for _, s := range pc.SpeciesIndex {
+		set[s.SpeciesCode] = true
+	}
+	for _, s := range pc.Sightings {
+		set[s.SpeciesCode] = true
+	}
+	return sortedKeys(set)
+}
+
+// PersonalTaxonomy builds a minimal taxonomy (codes and names only) from the
+// species in a personal checklist, for use when no taxonomy file is loaded.
+func PersonalTaxonomy(pc *it.PersonalChecklist) *taxonomy.Taxonomy {
+	seen := map[string]bool{}
+	var entries []taxonomy.Entry
+	add := func(code, com, sci string) {
+		if code == "" || seen[code] {
+			return
+		}
+		seen[code] = true
+		entries = append(entries, taxonomy.Entry{SpeciesCode: code, CommonName: com, SciName: sci, Category: "species"})
+	}
+	for _, s := range pc.SpeciesIndex {
+		add(s.SpeciesCode, s.CommonName, s.SciName)
+	}
+	for _, s := range pc.Sightings {
+		add(s.SpeciesCode, s.CommonName, s.SciName)
+	}
+	return taxonomy.New(entries)
+}
+
+// LocationIDs returns every location ID in the sightings, sorted.
+func LocationIDs(pc *it.PersonalChecklist) []string {
+	set := map[string]bool{}
+	for _, s := range pc.Sightings {
+		set[s.LocID] = true
+	}
+	return sortedKeys(set)
+}
+
+// ChecklistIDs returns every checklist ID in the sightings, sorted.
+func ChecklistIDs(pc *it.PersonalChecklist) []string {
+	set := map[string]bool{}
+	for _, s := range pc.Sightings {
+		set[s.ChecklistID] = true
+	}
+	return sortedKeys(set)
+}
+
+// LocationSummary aggregates the sightings at one location.
+type LocationSummary struct {
+	LocID      string
+	LocName    string
+	CountyCode string
+	Lat        float64
+	Lng        float64
+	FirstVisit string
+	LastVisit  string
+	Species    []string // sorted species codes
+	Checklists int
+}
+
+// SummarizeLocations groups sightings with coordinates by location ID (or
+// name, when there is no ID), sorted by that key. Names and coordinates come
+// from the first sighting at each location.
+func SummarizeLocations(sightings []it.PersonalSighting) []LocationSummary {
+	type acc struct {
+		sum        LocationSummary
+		species    map[string]bool
+		checklists map[string]bool
+	}
+	byLoc := map[string]*acc{}
+	var keys []string
+	for _, s := range sightings {
+		if s.Lat == 0 && s.Lng == 0 {
+			continue
+		}
+		key := s.LocID
+		if key == "" {
+			key = s.LocName
+		}
+		a, ok := byLoc[key]
+		if !ok {
+			a = &acc{
+				sum:        LocationSummary{LocID: s.LocID, LocName: s.LocName, CountyCode: s.CountyCode, Lat: s.Lat, Lng: s.Lng, FirstVisit: s.ObsDt, LastVisit: s.ObsDt},
+				species:    map[string]bool{},
+				checklists: map[string]bool{},
+			}
+			byLoc[key] = a
+			keys = append(keys, key)
+		}
+		if s.ObsDt != "" && (a.sum.FirstVisit == "" || s.ObsDt < a.sum.FirstVisit) {
+			a.sum.FirstVisit = s.ObsDt
+		}
+		if s.ObsDt > a.sum.LastVisit {
+			a.sum.LastVisit = s.ObsDt
+		}
+		a.species[s.SpeciesCode] = true
+		if s.ChecklistID != "" {
+			a.checklists[s.ChecklistID] = true
+		}
+	}
+	sort.Strings(keys)
+
+	out := make([]LocationSummary, 0, len(keys))
+	for _, key := range keys {
+		a := byLoc[key]
+		a.sum.Species = sortedKeys(a.species)
+		a.sum.Checklists = len(a.checklists)
+		out = append(out, a.sum)
+	}
+	return out
+}
+
+func sortedKeys(set map[string]bool) []string {
+	out := make([]string, 0, len(set))
+	for k := range set {
+		if strings.TrimSpace(k) != "" {
+			out = append(out, k)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func sortSightingsByDate(s []it.PersonalSighting) {
+	sort.SliceStable(s, func(i, j int) bool { return s[i].ObsDt < s[j].ObsDt })
+}
+
+// minDate and maxDate compare YYYY-MM-DD strings, ignoring empty values.
+func minDate(a, b string) string {
+	if a == "" || (b != "" && b < a) {
+		return b
+	}
+	return a
+}
+
+func maxDate(a, b string) string {
+	if b > a {
+		return b
+	}
+	return a
+}
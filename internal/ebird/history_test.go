@@ -0,0 +1,88 @@
+package ebird
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	it "github.com/kpb/wingit-mcp/internal/types"
+)
+
+func Test_history_lookups(t *testing.T) {
+	pc, err := LoadPersonalChecklist(filepath.Join("testdata", "personal_checklist_example.json"))
+	if err != nil {
+		t.Fatalf("LoadPersonalChecklist: %v", err)
+	}
+
+	sh, ok := FindSpeciesHistory(pc, "clanut")
+	if !ok {
+		t.Fatalf("clanut not found")
+	}
+	// Index totals win over the single sighting in the export.
+	if sh.FirstSeen != "2018-05-01" || sh.LastSeen != "2025-09-12" || sh.TotalChecklists != 6 || sh.TotalCount != 11 {
+		t.Fatalf("species history = %+v", sh)
+	}
+	if len(sh.Locations) != 1 || sh.Locations[0].LocName != "Aspen Vista" {
+		t.Fatalf("locations = %+v", sh.Locations)
+	}
+	if _, ok := FindSpeciesHistory(pc, "nope"); ok {
+		t.Fatalf("unexpected history for unknown species")
+	}
+
+	lh, ok := FindLocationHistory(pc, "L998877")
+	if !ok || lh.LocName != "Santa Fe River Trail" || len(lh.Species) != 1 || lh.Species[0].SpeciesCode != "amgold" {
+		t.Fatalf("location history = %+v, %v", lh, ok)
+	}
+
+	ch, ok := FindChecklistHistory(pc, "S100000001")
+	if !ok || ch.LocID != "L654321" || len(ch.Species) != 1 {
+		t.Fatalf("checklist history = %+v, %v", ch, ok)
+	}
+	// First sighting per the index predates this checklist, so no lifer here.
+	if len(ch.Lifers) != 0 {
+		t.Fatalf("lifers = %v, want none", ch.Lifers)
+	}
+
+	if got, want := SpeciesCodes(pc), []string{"amgold", "clanut"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("SpeciesCodes = %v, want %v", got, want)
+	}
+	if got, want := ChecklistIDs(pc), []string{"S100000001", "S100000002"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("ChecklistIDs = %v, want %v", got, want)
+	}
+}
+
+func Test_checklist_history_lifers_come_from_the_life_list(t *testing.T) {
+	pc := &it.PersonalChecklist{Sightings: []it.PersonalSighting{
+		{SpeciesCode: "clanut", ObsDt: "2025-09-12 08:15", ChecklistID: "S1"},
+		{SpeciesCode: "pinsis", ObsDt: "2025-09-12 08:15", ChecklistID: "S1"},
+		{SpeciesCode: "pinsis", ObsDt: "2025-09-12 08:15", ChecklistID: "S1"},
+		{SpeciesCode: "clanut", ObsDt: "2025-09-12 17:00", ChecklistID: "S2"},
+		{SpeciesCode: "cantow", ObsDt: "2025-09-12 17:00", ChecklistID: "S2"},
+	}}
+	for id, want := range map[string][]string{"S1": {"clanut", "pinsis"}, "S2": {"cantow"}} {
+		ch, ok := FindChecklistHistory(pc, id)
+		if !ok || !reflect.DeepEqual(ch.Lifers, want) {
+			t.Errorf("%s: lifers = %v, %v; want %v", id, ch.Lifers, ok, want)
+		}
+	}
+}
+
+func Test_life_list_prefers_earliest_date(t *testing.T) {
+	pc, err := LoadPersonalChecklist(filepath.Join("testdata", "personal_checklist_example.json"))
+	if err != nil {
+		t.Fatalf("LoadPersonalChecklist: %v", err)
+	}
+	got := LifeList(pc)
+	if len(got) != 2 {
+		t.Fatalf("len(LifeList) = %d, want 2", len(got))
+	}
+	// Index first-seen dates (2018, 2019) predate the exported sightings.
+	if got[0].SpeciesCode != "clanut" || got[0].FirstSeen != "2018-05-01" || got[0].LocID != "" {
+		t.Fatalf("first lifer = %+v", got[0])
+	}
+
+	fromSightings := LifeListFromSightings(pc.Sightings)
+	if fromSightings[0].SpeciesCode != "amgold" || fromSightings[0].LocName != "Santa Fe River Trail" {
+		t.Fatalf("sightings-only first lifer = %+v", fromSightings[0])
+	}
+}
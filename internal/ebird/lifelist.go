@@ -0,0 +1,87 @@
+// internal/ebird/lifelist.go
+package ebird
+
+import (
+	"sort"
+
+	it "github.com/kpb/wingit-mcp/internal/types"
+)
+
+// Lifer is the first recorded sighting of a species.
+// Location and checklist fields are empty when only the SpeciesIndex knows
+// the first-seen date.
+type Lifer struct {
+	SpeciesCode string `json:"speciesCode"`
+	CommonName  string `json:"commonName"`
+	SciName     string `json:"sciName"`
+	FirstSeen   string `json:"firstSeen"`
+	LocID       string `json:"locId,omitempty"`
+	LocName     string `json:"locName,omitempty"`
+	CountyCode  string `json:"countyCode,omitempty"`
+	ChecklistID string `json:"checklistId,omitempty"`
+}
+
+// LifeList returns one Lifer per species, in chronological order of first
+// sighting (then by species code). It combines Sightings with
+// SpeciesIndex.FirstSeen, taking whichever date is earlier.
+func LifeList(pc *it.PersonalChecklist) []Lifer {
+	return lifeList(pc.Sightings, pc.SpeciesIndex)
+}
+
+// LifeListFromSightings is LifeList restricted to the given sightings, for
+// views (such as a region) the SpeciesIndex cannot answer.
+func LifeListFromSightings(sightings []it.PersonalSighting) []Lifer {
+	return lifeList(sightings, nil)
+}
+
+func lifeList(sightings []it.PersonalSighting, index []it.SpeciesIndex) []Lifer {
+	by := map[string]*Lifer{}
+	for _, s := range sightings {
+		if s.SpeciesCode == "" {
+			continue
+		}
+		l, ok := by[s.SpeciesCode]
+		if ok && (s.ObsDt == "" || (l.FirstSeen != "" && l.FirstSeen <= s.ObsDt)) {
+			continue
+		}
+		by[s.SpeciesCode] = &Lifer{
+			SpeciesCode: s.SpeciesCode,
+			CommonName:  s.CommonName,
+			SciName:     s.SciName,
+			FirstSeen:   s.ObsDt,
+			LocID:       s.LocID,
+			LocName:     s.LocName,
+			CountyCode:  s.CountyCode,
+			ChecklistID: s.ChecklistID,
+		}
+	}
+	for _, si := range index {
+		if si.SpeciesCode == "" {
+			continue
+		}
+		l, ok := by[si.SpeciesCode]
+		if !ok {
+			by[si.SpeciesCode] = &Lifer{SpeciesCode: si.SpeciesCode, CommonName: si.CommonName, SciName: si.SciName, FirstSeen: si.FirstSeen}
+			continue
+		}
+		if si.FirstSeen != "" && (l.FirstSeen == "" || si.FirstSeen < l.FirstSeen) {
+			*l = Lifer{SpeciesCode: si.SpeciesCode, CommonName: si.CommonName, SciName: si.SciName, FirstSeen: si.FirstSeen}
+		}
+	}
+
+	out := make([]Lifer, 0, len(by))
+	for _, l := range by {
+		out = append(out, *l)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].FirstSeen != out[j].FirstSeen {
+			// Undated lifers sort last.
+			if out[i].FirstSeen == "" || out[j].FirstSeen == "" {
+				return out[j].FirstSeen == ""
+			}
+			return out[i].FirstSeen < out[j].FirstSeen
+		}
+		return out[i].SpeciesCode < out[j].SpeciesCode
+	})
+	return out
+}
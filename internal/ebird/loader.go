@@ -38,16 +38,15 @@ func LoadRecentNearby(path string) ([]it.RecentObservation, error) {
 	return rows, nil
 }
 
-// BuildPersonalSeenSet builds a set keyed by species code for quick lookup of seen birds.
+// BuildPersonalSeenSet builds a set keyed by species code for quick lookup of
+// seen birds: the union of SpeciesIndex and Sightings, so a stale index
+// cannot hide a lifer recorded only in sightings. See Reconcile.
 func BuildPersonalSeenSet(pc *it.PersonalChecklist) map[string]struct{} {
 	seen := make(map[string]struct{}, len(pc.SpeciesIndex))
-	if len(pc.SpeciesIndex) > 0 {
-		for _, s := range pc.SpeciesIndex {
-			if s.SpeciesCode != "" {
-				seen[s.SpeciesCode] = struct{}{}
-			}
+	for _, s := range pc.SpeciesIndex {
+		if s.SpeciesCode != "" {
+			seen[s.SpeciesCode] = struct{}{}
 		}
-		return seen
 	}
 	for _, s := range pc.Sightings {
 		if s.SpeciesCode != "" {
@@ -0,0 +1,212 @@
+// internal/ebird/reconcile.go
+package ebird
+
+import (
+	"fmt"
+	"sort"
+
+	it "github.com/kpb/wingit-mcp/internal/types"
+)
+
+// Provenance records where a species in the effective seen set came from.
+const (
+	ProvenanceBoth      = "both"
+	ProvenanceSightings = "sightings"
+	ProvenanceIndex     = "index"
+)
+
+// Discrepancy kinds.
+const (
+	DiscrepancyMissingFromIndex = "missingFromIndex"
+	DiscrepancyFirstSeenLater   = "firstSeenLater"
+	DiscrepancyLastSeenEarlier  = "lastSeenEarlier"
+	DiscrepancyChecklistsUnder  = "checklistsUndercounted"
+)
+
+// Discrepancy is one way the supplied SpeciesIndex disagrees with Sightings.
+// Path locates the offending entry in the checklist JSON.
+type Discrepancy struct {
+	SpeciesCode string `json:"speciesCode"`
+	Kind        string `json:"kind"`
+	Path        string `json:"path"`
+	Message     string `json:"message"`
+}
+
+// Reconciliation merges the supplied SpeciesIndex with one rebuilt from
+// Sightings. Index is the merged index (sorted by species code), Provenance
+// maps every species to where it was found, and Discrepancies lists where the
+// supplied index was stale or wrong.
+type Reconciliation struct {
+	Index         []it.SpeciesIndex `json:"index"`
+	Provenance    map[string]string `json:"provenance"`
+	Discrepancies []Discrepancy     `json:"discrepancies"`
+}
+
+// SeenSet returns the effective seen set: every species in either source.
+func (r Reconciliation) SeenSet() map[string]struct{} {
+	seen := make(map[string]struct{}, len(r.Provenance))
+	for code := range r.Provenance {
+		seen[code] = struct{}{}
+	}
+	return seen
+}
+
+// RebuildSpeciesIndex derives a SpeciesIndex from sightings alone, sorted by
+// species code.
+func RebuildSpeciesIndex(sightings []it.PersonalSighting) []it.SpeciesIndex {
+	by := map[string]*it.SpeciesIndex{}
+	checklists := map[string]map[string]bool{}
+	locations := map[string]map[string]bool{}
+	for _, s := range sightings {
+		if s.SpeciesCode == "" {
+			continue
+		}
+		e, ok := by[s.SpeciesCode]
+		if !ok {
+			e = &it.SpeciesIndex{SpeciesCode: s.SpeciesCode, CommonName: s.CommonName, SciName: s.SciName}
+			by[s.SpeciesCode] = e
+			checklists[s.SpeciesCode] = map[string]bool{}
+			locations[s.SpeciesCode] = map[string]bool{}
+		}
+		if s.ObsDt != "" {
+			date := dateOnly(s.ObsDt)
+			e.FirstSeen, e.LastSeen = minDate(e.FirstSeen, date), maxDate(e.LastSeen, date)
+		}
+		e.TotalCount += s.Count
+		if s.ChecklistID != "" {
+			checklists[s.SpeciesCode][s.ChecklistID] = true
+		}
+		if s.LocID != "" {
+			locations[s.SpeciesCode][s.LocID] = true
+		}
+	}
+
+	out := make([]it.SpeciesIndex, 0, len(by))
+	for code, e := range by {
+		e.TotalChecklists = len(checklists[code])
+		e.Locations = sortedKeys(locations[code])
+		out = append(out, *e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].SpeciesCode < out[j].SpeciesCode })
+	return out
+}
+
+// Summary is a checklist reduced to what Reconcile reads, so a store can
+// compute it with aggregate queries instead of loading every sighting.
+type Summary struct {
+	// Checklist carries Meta and SpeciesIndex; its Sightings may be empty.
+	Checklist *it.PersonalChecklist
+	// Rebuilt is RebuildSpeciesIndex over all sightings.
+	Rebuilt []it.SpeciesIndex
+	// FirstSighting is the position of each species' first sighting.
+	FirstSighting map[string]int
+	Sightings     int
+}
+
+// Summarize computes pc's Summary in memory.
+func Summarize(pc *it.PersonalChecklist) Summary {
+	first := map[string]int{}
+	for i, s := range pc.Sightings {
+		if _, ok := first[s.SpeciesCode]; !ok && s.SpeciesCode != "" {
+			first[s.SpeciesCode] = i
+		}
+	}
+	return Summary{Checklist: pc, Rebuilt: RebuildSpeciesIndex(pc.Sightings), FirstSighting: first, Sightings: len(pc.Sightings)}
+}
+
+// Reconcile rebuilds the index from pc.Sightings and merges it with
+// pc.SpeciesIndex. Merged entries take the earliest firstSeen, the latest
+// lastSeen, the larger totals and the union of locations. Species only in
+// the supplied index are kept without complaint: summarized exports index
+// species whose sightings they omit.
+func Reconcile(pc *it.PersonalChecklist) Reconciliation {
+	return ReconcileSummary(Summarize(pc))
+}
+
+// ReconcileSummary is Reconcile over a precomputed Summary.
+func ReconcileSummary(sum Summary) Reconciliation {
+	pc, rebuilt := sum.Checklist, sum.Rebuilt
+	r := Reconciliation{Provenance: make(map[string]string, len(rebuilt)+len(pc.SpeciesIndex)), Discrepancies: []Discrepancy{}}
+
+	merged := make(map[string]*it.SpeciesIndex, len(rebuilt))
+	for i := range rebuilt {
+		e := rebuilt[i]
+		merged[e.SpeciesCode] = &e
+		r.Provenance[e.SpeciesCode] = ProvenanceSightings
+	}
+
+	indexed := make(map[string]bool, len(pc.SpeciesIndex))
+	for i, si := range pc.SpeciesIndex {
+		if si.SpeciesCode == "" || indexed[si.SpeciesCode] {
+			continue // duplicate index entries: the first one wins
+		}
+		indexed[si.SpeciesCode] = true
+		path := fmt.Sprintf("$.speciesIndex[%d]", i)
+		m, ok := merged[si.SpeciesCode]
+		if !ok {
+			e := si
+			e.Locations = append([]string(nil), si.Locations...)
+			merged[si.SpeciesCode] = &e
+			r.Provenance[si.SpeciesCode] = ProvenanceIndex
+			continue
+		}
+		r.Provenance[si.SpeciesCode] = ProvenanceBoth
+
+		if si.FirstSeen != "" && m.FirstSeen != "" && dateOnly(si.FirstSeen) > m.FirstSeen {
+			r.add(si.SpeciesCode, DiscrepancyFirstSeenLater, path+".firstSeen",
+				"firstSeen %s is after the earliest sighting %s", si.FirstSeen, m.FirstSeen)
+		}
+		if si.LastSeen != "" && dateOnly(si.LastSeen) < m.LastSeen {
+			r.add(si.SpeciesCode, DiscrepancyLastSeenEarlier, path+".lastSeen",
+				"lastSeen %s is before the latest sighting %s", si.LastSeen, m.LastSeen)
+		}
+		if si.TotalChecklists < m.TotalChecklists {
+			r.add(si.SpeciesCode, DiscrepancyChecklistsUnder, path+".totalChecklists",
+				"totalChecklists %d is less than the %d checklists in sightings", si.TotalChecklists, m.TotalChecklists)
+		}
+
+		if si.CommonName != "" {
+			m.CommonName, m.SciName = si.CommonName, si.SciName
+		}
+		m.FirstSeen = minDate(m.FirstSeen, dateOnly(si.FirstSeen))
+		m.LastSeen = maxDate(m.LastSeen, dateOnly(si.LastSeen))
+		if si.TotalChecklists > m.TotalChecklists {
+			m.TotalChecklists = si.TotalChecklists
+		}
+		if si.TotalCount > m.TotalCount {
+			m.TotalCount = si.TotalCount
+		}
+		locs := map[string]bool{}
+		for _, id := range append(m.Locations, si.Locations...) {
+			locs[id] = true
+		}
+		m.Locations = sortedKeys(locs)
+	}
+
+	// A non-empty index that lacks a sighted species is stale: without
+	// reconciliation it would hide that lifer.
+	if len(pc.SpeciesIndex) > 0 {
+		var missing []string
+		for code, p := range r.Provenance {
+			if p == ProvenanceSightings {
+				missing = append(missing, code)
+			}
+		}
+		sort.Slice(missing, func(i, j int) bool { return sum.FirstSighting[missing[i]] < sum.FirstSighting[missing[j]] })
+		for _, code := range missing {
+			r.add(code, DiscrepancyMissingFromIndex, fmt.Sprintf("$.sightings[%d].speciesCode", sum.FirstSighting[code]),
+				"%s is missing from speciesIndex", code)
+		}
+	}
+
+	r.Index = make([]it.SpeciesIndex, 0, len(merged))
+	for _, e := range merged {
+		r.Index = append(r.Index, *e)
+	}
+	sort.Slice(r.Index, func(i, j int) bool { return r.Index[i].SpeciesCode < r.Index[j].SpeciesCode })
+	return r
+}
+
+func (r *Reconciliation) add(code, kind, path, format string, a ...any) {
+	r.Discrepancies = append(r.Discrepancies, Discrepancy{SpeciesCode: code, Kind: kind, Path: path, Message: fmt.Sprintf(format, a...)})
+}
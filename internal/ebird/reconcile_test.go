@@ -0,0 +1,66 @@
+package ebird
+
+import (
+	"testing"
+
+	it "github.com/kpb/wingit-mcp/internal/types"
+)
+
+func Test_reconcile_unions_index_and_sightings_with_provenance(t *testing.T) {
+	t.Parallel()
+
+	pc := &it.PersonalChecklist{
+		Sightings: []it.PersonalSighting{
+			{SpeciesCode: "clanut", ObsDt: "2025-09-12", ChecklistID: "S1", LocID: "L1", Count: 2},
+			{SpeciesCode: "clanut", ObsDt: "2025-09-20 07:30", ChecklistID: "S3", LocID: "L2", Count: 1},
+			{SpeciesCode: "lewo", ObsDt: "2025-10-01", ChecklistID: "S4", LocID: "L2", Count: 1},
+		},
+		SpeciesIndex: []it.SpeciesIndex{
+			{SpeciesCode: "clanut", FirstSeen: "2018-05-01", LastSeen: "2025-09-12", TotalChecklists: 1, TotalCount: 11, Locations: []string{"L0"}},
+			{SpeciesCode: "amgold", FirstSeen: "2019-01-01", LastSeen: "2024-06-10", TotalChecklists: 4},
+		},
+	}
+
+	r := Reconcile(pc)
+
+	wantProv := map[string]string{"clanut": ProvenanceBoth, "amgold": ProvenanceIndex, "lewo": ProvenanceSightings}
+	for code, want := range wantProv {
+		if got := r.Provenance[code]; got != want {
+			t.Errorf("provenance[%s] = %q, want %q", code, got, want)
+		}
+	}
+	if seen := r.SeenSet(); len(seen) != 3 {
+		t.Errorf("seen set = %v, want 3 species", seen)
+	}
+	if seen := BuildPersonalSeenSet(pc); len(seen) != 3 {
+		t.Errorf("BuildPersonalSeenSet = %v, want union of 3 species", seen)
+	}
+
+	if len(r.Index) != 3 || r.Index[1].SpeciesCode != "clanut" {
+		t.Fatalf("index = %+v", r.Index)
+	}
+	clanut := r.Index[1]
+	if clanut.FirstSeen != "2018-05-01" || clanut.LastSeen != "2025-09-20" || clanut.TotalChecklists != 2 || clanut.TotalCount != 11 {
+		t.Errorf("merged clanut = %+v", clanut)
+	}
+	if len(clanut.Locations) != 3 {
+		t.Errorf("merged clanut locations = %v, want L0 L1 L2", clanut.Locations)
+	}
+
+	kinds := map[string]string{}
+	for _, d := range r.Discrepancies {
+		kinds[d.Kind] = d.Path
+	}
+	if kinds[DiscrepancyMissingFromIndex] != "$.sightings[2].speciesCode" {
+		t.Errorf("missing-from-index path = %q (all: %+v)", kinds[DiscrepancyMissingFromIndex], r.Discrepancies)
+	}
+	if kinds[DiscrepancyLastSeenEarlier] != "$.speciesIndex[0].lastSeen" {
+		t.Errorf("lastSeen path = %q", kinds[DiscrepancyLastSeenEarlier])
+	}
+	if kinds[DiscrepancyChecklistsUnder] != "$.speciesIndex[0].totalChecklists" {
+		t.Errorf("totalChecklists path = %q", kinds[DiscrepancyChecklistsUnder])
+	}
+	if _, ok := kinds[DiscrepancyFirstSeenLater]; ok {
+		t.Errorf("unexpected firstSeen discrepancy: %+v", r.Discrepancies)
+	}
+}
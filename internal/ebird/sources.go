@@ -0,0 +1,508 @@
+// internal/ebird/sources.go
+package ebird
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/kpb/wingit-mcp/internal/taxonomy"
+	it "github.com/kpb/wingit-mcp/internal/types"
+)
+
+// Personal source types, as named in a sources manifest.
+const (
+	SourceEBirdJSON   = "ebird-json"   // normalized personal checklist JSON
+	SourceEBirdCSV    = "ebird-csv"    // eBird "Download My Data" CSV
+	SourceCSV         = "csv"          // any CSV, with a column mapping
+	SourceExtraLifers = "extra-lifers" // hand-maintained YAML list of lifers
+)
+
+// SourceSpec is one personal data source in a manifest.
+type SourceSpec struct {
+	Type string `json:"type"`
+	// Path is resolved against the manifest's directory when relative.
+	Path string `json:"path"`
+	// Tag is stored on every sighting from this source; defaults to the
+	// file name without extension.
+	Tag string `json:"tag,omitempty"`
+	// Columns maps sighting fields (speciesCode, commonName, sciName, obsDt,
+	// obsTime, locName, locId, countyCode, lat, lng, count, checklistId,
+	// heardOnly) to CSV header names. Only used by the "csv" type.
+	Columns map[string]string `json:"columns,omitempty"`
+	// DateFormat is the Go layout of the obsDt column (default 2006-01-02).
+	DateFormat string `json:"dateFormat,omitempty"`
+}
+
+// SourcesManifest lists personal data sources to merge into one checklist.
+// A JSON file with a top-level "sources" array is read as a manifest
+// wherever a personal checklist path is accepted.
+type SourcesManifest struct {
+	Owner   string       `json:"owner,omitempty"`
+	Sources []SourceSpec `json:"sources"`
+}
+
+// LoadPersonal loads a personal checklist from path, which may be a
+// normalized eBird JSON export, an eBird CSV (.csv), an extra-lifers YAML
+// file (.yaml/.yml) or a sources manifest merging any of these. tax maps
+// names to species codes for sources that lack them; it may be nil when
+// every source carries codes.
+func LoadPersonal(path string, tax *taxonomy.Taxonomy) (*it.PersonalChecklist, error) {
+	pc, _, err := LoadPersonalWithWarnings(path, tax)
+	return pc, err
+}
+
+// LoadPersonalWithWarnings is LoadPersonal that also returns what it
+// skipped and why, such as CSV rows for spuhs, slashes, hybrids and
+// domestics, which are not species and so never count toward a life list.
+func LoadPersonalWithWarnings(path string, tax *taxonomy.Taxonomy) (*it.PersonalChecklist, []string, error) {
+	var warnings []string
+	warn := func(format string, a ...any) { warnings = append(warnings, fmt.Sprintf(format, a...)) }
+	m, ok, err := readManifest(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var pc *it.PersonalChecklist
+	if !ok {
+		pc, err = loadSource(SourceSpec{Type: sourceTypeOf(path), Path: path}, tax, false, warn)
+	} else {
+		pc, err = loadManifest(m, filepath.Dir(path), tax, warn)
+	}
+	if err != nil {
+		return nil, warnings, err
+	}
+	return pc, warnings, nil
+}
+
+// PersonalSourceFiles lists every file LoadPersonal(path) reads: the path
+// itself plus, for a manifest, each source file.
+func PersonalSourceFiles(path string) ([]string, error) {
+	m, ok, err := readManifest(path)
+	if err != nil || !ok {
+		return []string{path}, err
+	}
+	files := []string{path}
+	for _, src := range m.Sources {
+		files = append(files, resolvePath(filepath.Dir(path), src.Path))
+	}
+	return files, nil
+}
+
+// readManifest decodes path as a manifest if it is JSON with a "sources" key.
+func readManifest(path string) (SourcesManifest, bool, error) {
+	var m SourcesManifest
+	if !strings.EqualFold(filepath.Ext(path), ".json") {
+		return m, false, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return m, false, fmt.Errorf("read personal checklist: %w", err)
+	}
+	var probe struct {
+		Sources json.RawMessage `json:"sources"`
+	}
+	if json.Unmarshal(b, &probe) != nil || probe.Sources == nil {
+		return m, false, nil
+	}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return m, false, fmt.Errorf("decode sources manifest: %w", err)
+	}
+	if len(m.Sources) == 0 {
+		return m, false, fmt.Errorf("sources manifest %s lists no sources", path)
+	}
+	return m, true, nil
+}
+
+func loadManifest(m SourcesManifest, dir string, tax *taxonomy.Taxonomy, warn warnFunc) (*it.PersonalChecklist, error) {
+	out := &it.PersonalChecklist{}
+	var names []string
+	indexed := map[string]bool{}
+	total := 0
+	for i, src := range m.Sources {
+		src.Path = resolvePath(dir, src.Path)
+		prefix := fmt.Sprintf("sources[%d] (%s): ", i, src.Path)
+		pc, err := loadSource(src, tax, true, func(format string, a ...any) { warn(prefix+format, a...) })
+		if err != nil {
+			return nil, fmt.Errorf("sources[%d] (%s): %w", i, src.Path, err)
+		}
+		names = append(names, tagOf(src))
+		if src.Type == SourceEBirdJSON && out.Meta.GeneratedAt == "" {
+			out.Meta = pc.Meta
+		}
+		// An eBird export's own total covers observations it doesn't list.
+		total += max(pc.Meta.TotalObservations, len(pc.Sightings))
+		out.Sightings = append(out.Sightings, pc.Sightings...)
+		for _, si := range pc.SpeciesIndex {
+			if !indexed[si.SpeciesCode] {
+				indexed[si.SpeciesCode] = true
+				out.SpeciesIndex = append(out.SpeciesIndex, si)
+			}
+		}
+	}
+
+	// The same sighting may arrive from several sources (JSON and CSV
+	// exports of one account): keep the first.
+	out, _ = Dedupe(out)
+
+	if m.Owner != "" {
+		out.Meta.Owner = m.Owner
+	}
+	out.Meta.Source = "merged: " + strings.Join(names, ", ")
+	out.Meta.TotalObservations = total
+	out.Meta.TotalSpecies = len(BuildPersonalSeenSet(out))
+	for _, s := range out.Sightings {
+		if d := dateOnly(s.ObsDt); d != "" {
+			out.Meta.FirstChecklistDate = minDate(out.Meta.FirstChecklistDate, d)
+		}
+	}
+	return out, nil
+}
+
+// warnFunc records something a loader skipped.
+type warnFunc func(format string, a ...any)
+
+// loadSource reads one source. Sightings are tagged only when merging.
+func loadSource(src SourceSpec, tax *taxonomy.Taxonomy, tag bool, warn warnFunc) (*it.PersonalChecklist, error) {
+	var pc *it.PersonalChecklist
+	var err error
+	switch src.Type {
+	case SourceEBirdJSON:
+		pc, err = LoadPersonalChecklist(src.Path)
+	case SourceEBirdCSV:
+		pc, err = loadCSV(src.Path, ebirdCSVColumns, "2006-01-02", tax, warn)
+	case SourceCSV:
+		if len(src.Columns) == 0 {
+			return nil, fmt.Errorf("csv source needs a columns mapping")
+		}
+		layout := src.DateFormat
+		if layout == "" {
+			layout = "2006-01-02"
+		}
+		pc, err = loadCSV(src.Path, src.Columns, layout, tax, warn)
+	case SourceExtraLifers:
+		pc, err = loadExtraLifers(src.Path, tax, warn)
+	default:
+		return nil, fmt.Errorf("unknown source type %q", src.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if tag {
+		name := tagOf(src)
+		for i := range pc.Sightings {
+			pc.Sightings[i].Source = name
+		}
+	}
+	return pc, nil
+}
+
+func sourceTypeOf(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return SourceEBirdCSV
+	case ".yaml", ".yml":
+		return SourceExtraLifers
+	default:
+		return SourceEBirdJSON
+	}
+}
+
+func tagOf(src SourceSpec) string {
+	if src.Tag != "" {
+		return src.Tag
+	}
+	base := filepath.Base(src.Path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+func resolvePath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// ebirdCSVColumns maps the eBird "Download My Data" CSV headers. That export
+// has no species codes, so names are resolved through the taxonomy; it also
+// names counties rather than coding them, so countyCode stays empty.
+var ebirdCSVColumns = map[string]string{
+	"checklistId": "Submission ID",
+	"commonName":  "Common Name",
+	"sciName":     "Scientific Name",
+	"count":       "Count",
+	"locId":       "Location ID",
+	"locName":     "Location",
+	"lat":         "Latitude",
+	"lng":         "Longitude",
+	"obsDt":       "Date",
+	"obsTime":     "Time",
+}
+
+// loadCSV reads a CSV whose headers are named by columns (sighting field →
+// header). Rows without a species code are resolved by scientific, then
+// common name; any name the taxonomy cannot resolve fails the load.
+// Subspecies and forms count as the species the taxonomy reports them as,
+// and rows for spuhs, slashes, hybrids and domestics are skipped with a
+// warning.
+func loadCSV(path string, columns map[string]string, dateLayout string, tax *taxonomy.Taxonomy, warn warnFunc) (*it.PersonalChecklist, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read csv: %w", err)
+	}
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(b, []byte("\ufeff"))))
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+	pos := map[string]int{}
+	for i, h := range header {
+		pos[strings.TrimSpace(h)] = i
+	}
+	col := map[string]int{}
+	for field, name := range columns {
+		i, ok := pos[name]
+		if !ok {
+			return nil, fmt.Errorf("csv has no %q column (mapped from %s)", name, field)
+		}
+		col[field] = i
+	}
+	if _, ok := col["speciesCode"]; !ok {
+		_, sci := col["sciName"]
+		_, com := col["commonName"]
+		if !sci && !com {
+			return nil, fmt.Errorf("csv mapping needs speciesCode, sciName or commonName")
+		}
+	}
+
+	pc := &it.PersonalChecklist{}
+	unresolved := map[string]bool{}
+	for line := 2; ; line++ {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read csv: %w", err)
+		}
+		get := func(field string) string {
+			if i, ok := col[field]; ok && i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+		s := it.PersonalSighting{
+			SpeciesCode: get("speciesCode"),
+			CommonName:  get("commonName"),
+			SciName:     get("sciName"),
+			LocName:     get("locName"),
+			LocID:       get("locId"),
+			CountyCode:  get("countyCode"),
+			ChecklistID: get("checklistId"),
+			ObsValid:    true,
+		}
+		if v := get("obsDt"); v != "" {
+			d, err := time.Parse(dateLayout, v)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: date %q does not match %q", line, v, dateLayout)
+			}
+			s.ObsDt = d.Format("2006-01-02")
+			if t, err := time.Parse("03:04 PM", get("obsTime")); err == nil {
+				s.ObsDt += t.Format(" 15:04")
+			}
+		}
+		// eBird uses "X" for present but uncounted.
+		s.Count, _ = strconv.Atoi(get("count"))
+		s.Lat, _ = strconv.ParseFloat(get("lat"), 64)
+		s.Lng, _ = strconv.ParseFloat(get("lng"), 64)
+		s.EnteredAsHeardOnly, _ = strconv.ParseBool(get("heardOnly"))
+
+		if kind := nonSpecies(tax, s); kind != "" {
+			warn("line %d: skipped %s %q, which is not a species", line, kind, firstNonEmpty(s.CommonName, s.SciName, s.SpeciesCode))
+			continue
+		}
+		if s.SpeciesCode == "" {
+			e, ok := resolveName(tax, s.SciName, s.CommonName)
+			if !ok {
+				unresolved[firstNonEmpty(s.SciName, s.CommonName)] = true
+				continue
+			}
+			if notSpecies[e.Category] {
+				warn("line %d: skipped %s %q, which is not a species", line, e.Category, firstNonEmpty(s.CommonName, s.SciName))
+				continue
+			}
+			s.SpeciesCode = e.SpeciesCode
+			if s.CommonName == "" {
+				s.CommonName = e.CommonName
+			}
+			if s.SciName == "" {
+				s.SciName = e.SciName
+			}
+		}
+		if p, ok := parentSpecies(tax, s.SpeciesCode); ok {
+			s.SpeciesCode, s.CommonName, s.SciName = p.SpeciesCode, p.CommonName, p.SciName
+		}
+		pc.Sightings = append(pc.Sightings, s)
+	}
+	if err := unresolvedError(unresolved, tax); err != nil {
+		return nil, err
+	}
+	return pc, nil
+}
+
+// extraLifers is the YAML shape of a hand-maintained lifer list.
+type extraLifers struct {
+	Lifers []struct {
+		// Species is a species code or a common or scientific name.
+		Species  string  `yaml:"species"`
+		Date     string  `yaml:"date"`
+		Location string  `yaml:"location"`
+		LocID    string  `yaml:"locId"`
+		County   string  `yaml:"countyCode"`
+		Lat      float64 `yaml:"lat"`
+		Lng      float64 `yaml:"lng"`
+		Count    int     `yaml:"count"`
+	} `yaml:"lifers"`
+}
+
+func loadExtraLifers(path string, tax *taxonomy.Taxonomy, warn warnFunc) (*it.PersonalChecklist, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read extra lifers: %w", err)
+	}
+	var doc extraLifers
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("decode extra lifers: %w", err)
+	}
+	pc := &it.PersonalChecklist{}
+	unresolved := map[string]bool{}
+	for i, l := range doc.Lifers {
+		if l.Species == "" {
+			return nil, fmt.Errorf("lifers[%d]: missing species", i)
+		}
+		s := it.PersonalSighting{
+			ObsDt: l.Date, LocName: l.Location, LocID: l.LocID, CountyCode: l.County,
+			Lat: l.Lat, Lng: l.Lng, Count: l.Count, ObsValid: true,
+		}
+		e, ok := tax.Lookup(l.Species)
+		if !ok {
+			e, ok = resolveName(tax, l.Species, l.Species)
+		}
+		if ok && notSpecies[e.Category] {
+			warn("lifers[%d]: skipped %s %q, which is not a species", i, e.Category, l.Species)
+			continue
+		}
+		if p, isForm := parentSpecies(tax, e.SpeciesCode); isForm {
+			e = p
+		}
+		if ok {
+			s.SpeciesCode, s.CommonName, s.SciName = e.SpeciesCode, e.CommonName, e.SciName
+		} else if tax == nil && !strings.Contains(l.Species, " ") {
+			s.SpeciesCode = l.Species // trust a bare code when there is no taxonomy
+		} else {
+			unresolved[l.Species] = true
+			continue
+		}
+		pc.Sightings = append(pc.Sightings, s)
+	}
+	if err := unresolvedError(unresolved, tax); err != nil {
+		return nil, err
+	}
+	return pc, nil
+}
+
+// minNameScore accepts exact and former-name matches but not fuzzy ones.
+const minNameScore = 0.9
+
+// notSpecies are the eBird taxonomy categories that never count toward a
+// life list.
+var notSpecies = map[string]bool{"spuh": true, "slash": true, "hybrid": true, "domestic": true}
+
+// nonSpecies returns the eBird category ("spuh", "slash", "hybrid" or
+// "domestic") of a sighting that is not of a species, from its taxonomy
+// entry or else from eBird's naming ("duck sp.", "Greater/Lesser Scaup",
+// "Mallard x ...", "Mallard (Domestic type)"), or "" for a species.
+func nonSpecies(tax *taxonomy.Taxonomy, s it.PersonalSighting) string {
+	if s.SpeciesCode != "" {
+		if e, ok := tax.Lookup(s.SpeciesCode); ok {
+			if notSpecies[e.Category] {
+				return e.Category
+			}
+			return ""
+		}
+	}
+	for _, name := range []string{s.CommonName, s.SciName} {
+		name = strings.ToLower(name)
+		switch {
+		case name == "":
+		case strings.HasSuffix(name, " sp.") || strings.HasSuffix(name, " sp"):
+			return "spuh"
+		case strings.Contains(name, "/"):
+			return "slash"
+		case strings.Contains(name, " x ") || strings.Contains(name, "(hybrid)"):
+			return "hybrid"
+		case strings.Contains(name, "(domestic type)"):
+			return "domestic"
+		}
+	}
+	return ""
+}
+
+// parentSpecies returns the species a subspecies, form or intergrade code
+// is reported as (its taxonomy reportAs), if it has one.
+func parentSpecies(tax *taxonomy.Taxonomy, code string) (taxonomy.Entry, bool) {
+	e, ok := tax.Lookup(code)
+	if !ok || e.ReportAs == "" {
+		return taxonomy.Entry{}, false
+	}
+	return tax.Lookup(e.ReportAs)
+}
+
+func resolveName(tax *taxonomy.Taxonomy, sciName, commonName string) (taxonomy.Entry, bool) {
+	for _, name := range []string{sciName, commonName} {
+		if name == "" {
+			continue
+		}
+		if m := tax.Resolve(name, 1); len(m) > 0 && m[0].Score >= minNameScore {
+			return m[0].Entry, true
+		}
+	}
+	return taxonomy.Entry{}, false
+}
+
+func unresolvedError(names map[string]bool, tax *taxonomy.Taxonomy) error {
+	if len(names) == 0 {
+		return nil
+	}
+	list := make([]string, 0, len(names))
+	for n := range names {
+		list = append(list, n)
+	}
+	sort.Strings(list)
+	if len(list) > 5 {
+		list = append(list[:5], "...")
+	}
+	if tax == nil {
+		return fmt.Errorf("%d species names need a taxonomy to resolve to codes (set WINGIT_TAXONOMY_JSON): %s", len(names), strings.Join(list, ", "))
+	}
+	return fmt.Errorf("%d species names not found in the taxonomy: %s", len(names), strings.Join(list, ", "))
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
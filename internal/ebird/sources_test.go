@@ -0,0 +1,138 @@
+package ebird
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kpb/wingit-mcp/internal/taxonomy"
+)
+
+func loadTestTaxonomy(t *testing.T) *taxonomy.Taxonomy {
+	t.Helper()
+	tax, err := taxonomy.Load(filepath.Join("..", "..", "data", "taxonomy_example.json"))
+	if err != nil {
+		t.Fatalf("taxonomy.Load: %v", err)
+	}
+	return tax
+}
+
+func Test_load_personal_merges_sources_with_tags(t *testing.T) {
+	t.Parallel()
+
+	pc, err := LoadPersonal(filepath.Join("testdata", "personal_sources_example.json"), loadTestTaxonomy(t))
+	if err != nil {
+		t.Fatalf("LoadPersonal: %v", err)
+	}
+
+	// clanut/S100000001 is in both the JSON and the CSV export: the JSON
+	// source is listed first, so its copy wins.
+	wantSource := map[string]string{
+		"clanut": "ebird",
+		"amgold": "ebird",
+		"pinsis": "personal_ebird_example",
+		"caltow": "personal_ebird_example",
+		"houspa": "notebook",
+		"rethaw": "pre-ebird",
+		"lewo":   "pre-ebird",
+	}
+	// The CSV's Harlan's Red-tailed Hawk is a second rethaw sighting.
+	if len(pc.Sightings) != len(wantSource)+1 {
+		t.Fatalf("expected %d sightings after dedupe, got %d", len(wantSource)+1, len(pc.Sightings))
+	}
+	for _, s := range pc.Sightings {
+		want := wantSource[s.SpeciesCode]
+		if s.SpeciesCode == "rethaw" && s.ChecklistID != "" {
+			want = "personal_ebird_example"
+		}
+		if got := s.Source; got != want {
+			t.Errorf("%s: expected source %q, got %q", s.SpeciesCode, want, got)
+		}
+	}
+
+	seen := BuildPersonalSeenSet(pc)
+	for code := range wantSource {
+		if _, ok := seen[code]; !ok {
+			t.Errorf("expected %s in merged seen set", code)
+		}
+	}
+	if pc.Meta.Owner != "Example Birder" || pc.Meta.TotalSpecies != len(wantSource) {
+		t.Errorf("unexpected meta: %+v", pc.Meta)
+	}
+	if pc.Meta.FirstChecklistDate != "2005-07-04" {
+		t.Errorf("expected first date from the extra lifers, got %q", pc.Meta.FirstChecklistDate)
+	}
+	if err := ValidatePersonalChecklist(pc).Err(); err != nil {
+		t.Errorf("merged checklist should validate: %v", err)
+	}
+}
+
+func Test_load_personal_ebird_csv_parses_rows(t *testing.T) {
+	t.Parallel()
+
+	pc, err := LoadPersonal(filepath.Join("testdata", "personal_ebird_example.csv"), loadTestTaxonomy(t))
+	if err != nil {
+		t.Fatalf("LoadPersonal: %v", err)
+	}
+	if len(pc.Sightings) != 4 {
+		t.Fatalf("expected 4 sightings, got %d", len(pc.Sightings))
+	}
+	s := pc.Sightings[1]
+	if s.SpeciesCode != "pinsis" || s.ObsDt != "2025-09-12 08:15" || s.Count != 0 || s.LocID != "L1000001" {
+		t.Errorf("unexpected sighting: %+v", s)
+	}
+	if s.Source != "" {
+		t.Errorf("a single source should not tag sightings, got %q", s.Source)
+	}
+	// A subspecies counts as the species its taxonomy entry reports as.
+	if s := pc.Sightings[3]; s.SpeciesCode != "rethaw" || s.CommonName != "Red-tailed Hawk" || s.SciName != "Buteo jamaicensis" {
+		t.Errorf("expected Harlan's Hawk reported as Red-tailed Hawk, got %+v", s)
+	}
+}
+
+func Test_load_personal_csv_skips_non_species_rows(t *testing.T) {
+	t.Parallel()
+
+	pc, warnings, err := LoadPersonalWithWarnings(filepath.Join("testdata", "personal_ebird_example.csv"), loadTestTaxonomy(t))
+	if err != nil {
+		t.Fatalf("LoadPersonalWithWarnings: %v", err)
+	}
+	if len(pc.Sightings) != 4 {
+		t.Fatalf("expected 4 sightings, got %d", len(pc.Sightings))
+	}
+	want := []string{
+		`line 5: skipped spuh "woodpecker sp.", which is not a species`,
+		`line 6: skipped slash "Western/Eastern Bluebird", which is not a species`,
+		`line 7: skipped hybrid "Mallard x Mexican Duck (hybrid)", which is not a species`,
+		`line 9: skipped domestic "Rock Pigeon (Feral Pigeon)", which is not a species`,
+	}
+	if strings.Join(warnings, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected warnings:\n%s", strings.Join(warnings, "\n"))
+	}
+}
+
+func Test_load_personal_csv_needs_taxonomy_for_names(t *testing.T) {
+	t.Parallel()
+
+	_, err := LoadPersonal(filepath.Join("testdata", "personal_ebird_example.csv"), nil)
+	if err == nil || !strings.Contains(err.Error(), "WINGIT_TAXONOMY_JSON") {
+		t.Fatalf("expected a taxonomy error, got %v", err)
+	}
+}
+
+func Test_personal_source_files_lists_manifest_sources(t *testing.T) {
+	t.Parallel()
+
+	files, err := PersonalSourceFiles(filepath.Join("testdata", "personal_sources_example.json"))
+	if err != nil {
+		t.Fatalf("PersonalSourceFiles: %v", err)
+	}
+	if len(files) != 5 || files[2] != filepath.Join("testdata", "personal_ebird_example.csv") {
+		t.Errorf("unexpected files: %v", files)
+	}
+
+	files, err = PersonalSourceFiles(filepath.Join("testdata", "personal_checklist_example.json"))
+	if err != nil || len(files) != 1 {
+		t.Errorf("plain export should list itself only: %v, %v", files, err)
+	}
+}
@@ -0,0 +1,316 @@
+// internal/ebird/validate.go
+package ebird
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	it "github.com/kpb/wingit-mcp/internal/types"
+)
+
+// Severity of a validation problem.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// dateLayouts are the obsDt / firstSeen formats eBird exports use.
+var dateLayouts = []string{"2006-01-02", "2006-01-02 15:04", time.RFC3339}
+
+// Problem is one validation finding, located by a JSON path such as
+// "$.sightings[3].obsDt".
+type Problem struct {
+	Path     string `json:"path"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s: %s", p.Severity, p.Path, p.Message)
+}
+
+// ValidationReport lists every problem found in a personal checklist.
+// Errors mean the data is corrupt; warnings mean it is inconsistent but usable.
+type ValidationReport struct {
+	Problems []Problem `json:"problems"`
+}
+
+func (r *ValidationReport) add(severity, path, format string, a ...any) {
+	r.Problems = append(r.Problems, Problem{Path: path, Severity: severity, Message: fmt.Sprintf(format, a...)})
+}
+
+// Errors returns the error-severity problems.
+func (r ValidationReport) Errors() []Problem { return r.filter(SeverityError) }
+
+// Warnings returns the warning-severity problems.
+func (r ValidationReport) Warnings() []Problem { return r.filter(SeverityWarning) }
+
+func (r ValidationReport) filter(severity string) []Problem {
+	var out []Problem
+	for _, p := range r.Problems {
+		if p.Severity == severity {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Err returns nil if the report has no errors, otherwise an error listing them.
+func (r ValidationReport) Err() error {
+	errs := r.Errors()
+	if len(errs) == 0 {
+		return nil
+	}
+	msgs := make([]string, 0, len(errs))
+	for _, p := range errs {
+		msgs = append(msgs, p.Path+": "+p.Message)
+	}
+	return fmt.Errorf("invalid personal checklist (%d errors): %s", len(errs), strings.Join(msgs, "; "))
+}
+
+// ValidatePersonalJSON decodes b field by field and validates the result.
+// Every value of the wrong type is reported as an error at its own path
+// (such as "$.sightings[0].lat") and unknown fields as warnings. A document
+// with any decode error, or that is not JSON at all, returns a nil checklist.
+func ValidatePersonalJSON(b []byte) (*it.PersonalChecklist, ValidationReport) {
+	var rep ValidationReport
+	if !json.Valid(b) {
+		var v any
+		rep.add(SeverityError, "$", "decode: %v", json.Unmarshal(b, &v))
+		return nil, rep
+	}
+	var pc it.PersonalChecklist
+	if !decodeStrict(b, "$", reflect.ValueOf(&pc).Elem(), &rep) {
+		return nil, rep
+	}
+	rep.Problems = append(rep.Problems, ValidatePersonalChecklist(&pc).Problems...)
+	return &pc, rep
+}
+
+// decodeStrict decodes the JSON object raw into the struct v one field at a
+// time, so each bad value is reported rather than only the first. It
+// reports whether there were no errors.
+func decodeStrict(raw json.RawMessage, path string, v reflect.Value, rep *ValidationReport) bool {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		rep.add(SeverityError, path, "expected object, got %s", jsonKind(raw))
+		return false
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	ok := true
+	for _, k := range keys {
+		field, found := jsonField(v.Type(), k)
+		if !found {
+			rep.add(SeverityWarning, path+"."+k, "unknown field %q", k)
+			continue
+		}
+		ok = decodeValue(fields[k], path+"."+k, v.FieldByIndex(field.Index), rep) && ok
+	}
+	return ok
+}
+
+// decodeValue decodes raw into v, descending into structs and slices of
+// structs so that nested errors keep their paths.
+func decodeValue(raw json.RawMessage, path string, v reflect.Value, rep *ValidationReport) bool {
+	if jsonKind(raw) == "null" {
+		return true
+	}
+	switch {
+	case v.Kind() == reflect.Struct:
+		return decodeStrict(raw, path, v, rep)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Struct:
+		var elems []json.RawMessage
+		if err := json.Unmarshal(raw, &elems); err != nil {
+			rep.add(SeverityError, path, "expected array, got %s", jsonKind(raw))
+			return false
+		}
+		out := reflect.MakeSlice(v.Type(), len(elems), len(elems))
+		ok := true
+		for i, e := range elems {
+			ok = decodeValue(e, fmt.Sprintf("%s[%d]", path, i), out.Index(i), rep) && ok
+		}
+		v.Set(out)
+		return ok
+	}
+	if err := json.Unmarshal(raw, v.Addr().Interface()); err != nil {
+		rep.add(SeverityError, path, "expected %s, got %s", v.Type(), jsonKind(raw))
+		return false
+	}
+	return true
+}
+
+// jsonField finds the field of t that encoding/json would decode key into:
+// an exact tag match, else a case-insensitive one.
+func jsonField(t reflect.Type, key string) (reflect.StructField, bool) {
+	var fold reflect.StructField
+	found := false
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if name == key {
+			return f, true
+		}
+		if !found && strings.EqualFold(name, key) {
+			fold, found = f, true
+		}
+	}
+	return fold, found
+}
+
+// jsonKind names the JSON type of raw for error messages.
+func jsonKind(raw json.RawMessage) string {
+	b := bytes.TrimSpace(raw)
+	if len(b) == 0 {
+		return "nothing"
+	}
+	switch b[0] {
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case '"':
+		return "string"
+	case 't', 'f':
+		return "bool"
+	case 'n':
+		return "null"
+	default:
+		return "number"
+	}
+}
+
+// ValidatePersonalFile reads and validates the personal checklist at path.
+func ValidatePersonalFile(path string) (*it.PersonalChecklist, ValidationReport, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, ValidationReport{}, fmt.Errorf("read personal checklist: %w", err)
+	}
+	pc, rep := ValidatePersonalJSON(b)
+	return pc, rep, nil
+}
+
+// ValidatePersonalChecklist checks pc and reports every problem found:
+// missing species codes, unparseable dates, out-of-range coordinates,
+// duplicate sightings, meta totals that don't match, and a SpeciesIndex that
+// disagrees with the sightings.
+func ValidatePersonalChecklist(pc *it.PersonalChecklist) ValidationReport {
+	var rep ValidationReport
+	if len(pc.Sightings) == 0 && len(pc.SpeciesIndex) == 0 {
+		rep.add(SeverityWarning, "$", "no sightings or species index")
+		return rep
+	}
+
+	if pc.Meta.GeneratedAt != "" && !validDate(pc.Meta.GeneratedAt) {
+		rep.add(SeverityError, "$.meta.generatedAt", "unparseable date %q", pc.Meta.GeneratedAt)
+	}
+	if pc.Meta.FirstChecklistDate != "" && !validDate(pc.Meta.FirstChecklistDate) {
+		rep.add(SeverityError, "$.meta.firstChecklistDate", "unparseable date %q", pc.Meta.FirstChecklistDate)
+	}
+
+	seenKey := map[string]int{}
+	earliest := ""
+	for i, s := range pc.Sightings {
+		path := fmt.Sprintf("$.sightings[%d]", i)
+		if s.SpeciesCode == "" {
+			rep.add(SeverityError, path+".speciesCode", "missing speciesCode")
+		}
+		switch {
+		case s.ObsDt == "":
+			rep.add(SeverityWarning, path+".obsDt", "missing date")
+		case !validDate(s.ObsDt):
+			rep.add(SeverityError, path+".obsDt", "unparseable date %q", s.ObsDt)
+		}
+		if s.Lat < -90 || s.Lat > 90 {
+			rep.add(SeverityError, path+".lat", "latitude %v out of range [-90, 90]", s.Lat)
+		}
+		if s.Lng < -180 || s.Lng > 180 {
+			rep.add(SeverityError, path+".lng", "longitude %v out of range [-180, 180]", s.Lng)
+		}
+		if s.Count < 0 {
+			rep.add(SeverityError, path+".count", "negative count %d", s.Count)
+		}
+		if s.SpeciesCode == "" {
+			continue
+		}
+		if s.ChecklistID != "" {
+			key := s.ChecklistID + "/" + s.SpeciesCode
+			if j, dup := seenKey[key]; dup {
+				rep.add(SeverityWarning, path, "duplicate of $.sightings[%d] (%s on %s)", j, s.SpeciesCode, s.ChecklistID)
+			} else {
+				seenKey[key] = i
+			}
+		}
+		earliest = minDate(earliest, dateOnly(s.ObsDt))
+	}
+
+	indexed := map[string]bool{}
+	for i, e := range pc.SpeciesIndex {
+		path := fmt.Sprintf("$.speciesIndex[%d]", i)
+		if e.SpeciesCode == "" {
+			rep.add(SeverityError, path+".speciesCode", "missing speciesCode")
+			continue
+		}
+		if indexed[e.SpeciesCode] {
+			rep.add(SeverityWarning, path+".speciesCode", "duplicate index entry for %s", e.SpeciesCode)
+		}
+		indexed[e.SpeciesCode] = true
+		for _, f := range []struct{ name, v string }{{"firstSeen", e.FirstSeen}, {"lastSeen", e.LastSeen}} {
+			if f.v != "" && !validDate(f.v) {
+				rep.add(SeverityError, path+"."+f.name, "unparseable date %q", f.v)
+			}
+		}
+		if e.FirstSeen != "" && e.LastSeen != "" && dateOnly(e.LastSeen) < dateOnly(e.FirstSeen) {
+			rep.add(SeverityWarning, path+".lastSeen", "lastSeen %s is before firstSeen %s", e.LastSeen, e.FirstSeen)
+		}
+	}
+	rec := Reconcile(pc)
+	for _, d := range rec.Discrepancies {
+		rep.add(SeverityWarning, d.Path, "%s", d.Message)
+	}
+
+	species := len(rec.Provenance)
+	if pc.Meta.TotalSpecies != 0 && pc.Meta.TotalSpecies != species {
+		rep.add(SeverityWarning, "$.meta.totalSpecies", "totalSpecies %d does not match %d species in the data", pc.Meta.TotalSpecies, species)
+	}
+	if pc.Meta.TotalObservations != 0 && pc.Meta.TotalObservations < len(pc.Sightings) {
+		rep.add(SeverityWarning, "$.meta.totalObservations", "totalObservations %d is less than the %d sightings", pc.Meta.TotalObservations, len(pc.Sightings))
+	}
+	if first := pc.Meta.FirstChecklistDate; first != "" && earliest != "" && dateOnly(first) > earliest {
+		rep.add(SeverityWarning, "$.meta.firstChecklistDate", "firstChecklistDate %s is after the earliest sighting %s", first, earliest)
+	}
+	return rep
+}
+
+func validDate(s string) bool {
+	for _, layout := range dateLayouts {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// dateOnly trims a timestamp to its YYYY-MM-DD prefix for comparisons.
+func dateOnly(s string) string {
+	if len(s) > 10 {
+		return s[:10]
+	}
+	return s
+}
@@ -0,0 +1,123 @@
+package ebird
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	it "github.com/kpb/wingit-mcp/internal/types"
+)
+
+func Test_validate_personal_example_has_no_errors(t *testing.T) {
+	t.Parallel()
+
+	pc, rep, err := ValidatePersonalFile(filepath.Join("testdata", "personal_checklist_example.json"))
+	if err != nil {
+		t.Fatalf("ValidatePersonalFile: %v", err)
+	}
+	if pc == nil {
+		t.Fatalf("expected decoded checklist")
+	}
+	if err := rep.Err(); err != nil {
+		t.Fatalf("unexpected errors: %v", err)
+	}
+}
+
+func Test_validate_personal_reports_every_problem_with_paths(t *testing.T) {
+	t.Parallel()
+
+	b, err := os.ReadFile(filepath.Join("testdata", "personal_checklist_invalid.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pc, rep := ValidatePersonalJSON(b)
+	if pc == nil {
+		t.Fatalf("unknown fields should not prevent decoding")
+	}
+
+	want := map[string]string{
+		"$.extra":                    SeverityWarning, // unknown field
+		"$.meta.generatedAt":         SeverityError,
+		"$.sightings[0].obsDt":       SeverityError,
+		"$.sightings[0].lat":         SeverityError,
+		"$.sightings[1].speciesCode": SeverityError,
+		"$.sightings[1].lng":         SeverityError,
+		"$.sightings[3]":             SeverityWarning, // duplicate
+		"$.sightings[2].speciesCode": SeverityWarning, // missing from index
+		"$.meta.totalSpecies":        SeverityWarning,
+		"$.meta.totalObservations":   SeverityWarning,
+	}
+	got := map[string]string{}
+	for _, p := range rep.Problems {
+		got[p.Path] = p.Severity
+	}
+	for path, sev := range want {
+		if got[path] != sev {
+			t.Errorf("%s: severity = %q, want %q (problems: %v)", path, got[path], sev, rep.Problems)
+		}
+	}
+	if len(rep.Errors()) != 5 {
+		t.Errorf("errors = %v, want 5", rep.Errors())
+	}
+	if rep.Err() == nil {
+		t.Errorf("expected Err() to be non-nil")
+	}
+}
+
+func Test_validate_personal_json_type_error_path(t *testing.T) {
+	t.Parallel()
+
+	pc, rep := ValidatePersonalJSON([]byte(`{"sightings":[{"speciesCode":"clanut","lat":"north"}]}`))
+	if pc != nil {
+		t.Fatalf("expected nil checklist on type error")
+	}
+	if len(rep.Problems) != 1 || rep.Problems[0].Path != "$.sightings[0].lat" {
+		t.Fatalf("problems = %v", rep.Problems)
+	}
+}
+
+func Test_validate_personal_json_reports_every_type_error(t *testing.T) {
+	t.Parallel()
+
+	pc, rep := ValidatePersonalJSON([]byte(`{
+		"meta": {"totalSpecies": "many"},
+		"sightings": [
+			{"speciesCode": "clanut", "lat": "north"},
+			{"speciesCode": "amgold"},
+			{"speciesCode": 7, "count": "3", "note": "x"}
+		],
+		"speciesIndex": {"clanut": {}}
+	}`))
+	if pc != nil {
+		t.Fatalf("expected nil checklist on type errors")
+	}
+	var errs, warns []string
+	for _, p := range rep.Problems {
+		if p.Severity == SeverityError {
+			errs = append(errs, p.Path)
+		} else {
+			warns = append(warns, p.Path)
+		}
+	}
+	want := []string{"$.meta.totalSpecies", "$.sightings[0].lat", "$.sightings[2].count", "$.sightings[2].speciesCode", "$.speciesIndex"}
+	if !reflect.DeepEqual(errs, want) {
+		t.Errorf("error paths = %v, want %v", errs, want)
+	}
+	if len(warns) != 1 || warns[0] != "$.sightings[2].note" {
+		t.Errorf("warning paths = %v, want [$.sightings[2].note]", warns)
+	}
+
+	if _, rep := ValidatePersonalJSON([]byte(`{"sightings": [`)); len(rep.Errors()) != 1 || rep.Errors()[0].Path != "$" {
+		t.Errorf("truncated document problems = %v", rep.Problems)
+	}
+}
+
+func Test_validate_personal_empty_checklist_is_a_warning(t *testing.T) {
+	t.Parallel()
+
+	rep := ValidatePersonalChecklist(&it.PersonalChecklist{})
+	if rep.Err() != nil || len(rep.Warnings()) != 1 || rep.Warnings()[0].Path != "$" {
+		t.Fatalf("expected one warning for a new user's empty checklist, got %+v", rep.Problems)
+	}
+}
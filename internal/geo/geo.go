@@ -0,0 +1,138 @@
+// Package geo holds the small amount of geodesy WingIt needs: distances,
+// "lat,lng" parsing and grid/geohash cells for binning sightings.
+package geo
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const earthRadiusKm = 6371.0
+
+// kmPerDegreeLat is the (near-constant) length of one degree of latitude.
+const kmPerDegreeLat = 111.32
+
+// DistanceKm returns the great-circle distance between two points.
+func DistanceKm(lat1, lng1, lat2, lng2 float64) float64 {
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLng := (lng2 - lng1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// ParseLatLng parses "lat,lng" (e.g. "35.6870,-105.9378").
+func ParseLatLng(s string) (lat, lng float64, err error) {
+	a, b, ok := strings.Cut(s, ",")
+	if !ok {
+		return 0, 0, fmt.Errorf("not a lat,lng pair: %q", s)
+	}
+	if lat, err = strconv.ParseFloat(strings.TrimSpace(a), 64); err != nil {
+		return 0, 0, fmt.Errorf("bad latitude in %q: %w", s, err)
+	}
+	if lng, err = strconv.ParseFloat(strings.TrimSpace(b), 64); err != nil {
+		return 0, 0, fmt.Errorf("bad longitude in %q: %w", s, err)
+	}
+	if !ValidLatLng(lat, lng) {
+		return 0, 0, fmt.Errorf("coordinates out of range: %q", s)
+	}
+	return lat, lng, nil
+}
+
+// ValidLatLng reports whether lat/lng are within their ranges.
+func ValidLatLng(lat, lng float64) bool {
+	return lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180
+}
+
+// Cell is a rectangular grid cell.
+type Cell struct {
+	ID     string
+	MinLat float64
+	MinLng float64
+	MaxLat float64
+	MaxLng float64
+}
+
+// Center returns the cell's midpoint.
+func (c Cell) Center() (lat, lng float64) {
+	return (c.MinLat + c.MaxLat) / 2, (c.MinLng + c.MaxLng) / 2
+}
+
+// Grid maps points to cells.
+type Grid interface {
+	CellOf(lat, lng float64) Cell
+}
+
+// KmGrid has cells roughly SizeKm on a side: rows are fixed bands of
+// latitude, and each row's longitude step widens with latitude to keep
+// cells near-square.
+type KmGrid struct {
+	SizeKm float64
+}
+
+func (g KmGrid) CellOf(lat, lng float64) Cell {
+	latStep := g.SizeKm / kmPerDegreeLat
+	row := math.Floor(lat / latStep)
+	minLat := row * latStep
+	midLat := minLat + latStep/2
+	lngStep := g.SizeKm / (kmPerDegreeLat * math.Max(math.Cos(midLat*math.Pi/180), 0.01))
+	col := math.Floor(lng / lngStep)
+	return Cell{
+		ID:     fmt.Sprintf("km%g:%d:%d", g.SizeKm, int64(row), int64(col)),
+		MinLat: minLat,
+		MaxLat: minLat + latStep,
+		MinLng: col * lngStep,
+		MaxLng: (col + 1) * lngStep,
+	}
+}
+
+// GeohashGrid uses geohash cells of the given precision (1-12 characters).
+type GeohashGrid struct {
+	Precision int
+}
+
+func (g GeohashGrid) CellOf(lat, lng float64) Cell {
+	return Geohash(lat, lng, g.Precision)
+}
+
+const geohashBase32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// Geohash encodes a point at precision characters and returns its cell.
+func Geohash(lat, lng float64, precision int) Cell {
+	precision = max(1, min(precision, 12))
+	c := Cell{MinLat: -90, MaxLat: 90, MinLng: -180, MaxLng: 180}
+	var b strings.Builder
+	even := true // geohash interleaves bits starting with longitude
+	bit, ch := 0, 0
+	for b.Len() < precision {
+		if even {
+			mid := (c.MinLng + c.MaxLng) / 2
+			if lng >= mid {
+				ch = ch<<1 | 1
+				c.MinLng = mid
+			} else {
+				ch <<= 1
+				c.MaxLng = mid
+			}
+		} else {
+			mid := (c.MinLat + c.MaxLat) / 2
+			if lat >= mid {
+				ch = ch<<1 | 1
+				c.MinLat = mid
+			} else {
+				ch <<= 1
+				c.MaxLat = mid
+			}
+		}
+		even = !even
+		if bit++; bit == 5 {
+			b.WriteByte(geohashBase32[ch])
+			bit, ch = 0, 0
+		}
+	}
+	c.ID = b.String()
+	return c
+}
@@ -0,0 +1,47 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func Test_distance_and_parse(t *testing.T) {
+	// Santa Fe to Albuquerque is roughly 90 km in a straight line.
+	if d := DistanceKm(35.687, -105.938, 35.084, -106.651); math.Abs(d-92) > 5 {
+		t.Fatalf("DistanceKm = %.1f, want ~92", d)
+	}
+	lat, lng, err := ParseLatLng(" 35.6870, -105.9378 ")
+	if err != nil || lat != 35.687 || lng != -105.9378 {
+		t.Fatalf("ParseLatLng = %v, %v, %v", lat, lng, err)
+	}
+	for _, bad := range []string{"Santa Fe, NM", "35.6", "95,10"} {
+		if _, _, err := ParseLatLng(bad); err == nil {
+			t.Errorf("ParseLatLng(%q) should fail", bad)
+		}
+	}
+}
+
+func Test_geohash_matches_reference(t *testing.T) {
+	// Reference value from the original geohash.org example.
+	c := Geohash(57.64911, 10.40744, 11)
+	if c.ID != "u4pruydqqvj" {
+		t.Fatalf("Geohash = %s, want u4pruydqqvj", c.ID)
+	}
+	if lat, lng := c.Center(); math.Abs(lat-57.64911) > 1e-4 || math.Abs(lng-10.40744) > 1e-4 {
+		t.Fatalf("center = %v,%v", lat, lng)
+	}
+}
+
+func Test_km_grid_cells_contain_point(t *testing.T) {
+	g := KmGrid{SizeKm: 5}
+	c := g.CellOf(35.687, -105.938)
+	if c.MinLat > 35.687 || c.MaxLat <= 35.687 || c.MinLng > -105.938 || c.MaxLng <= -105.938 {
+		t.Fatalf("cell %+v does not contain point", c)
+	}
+	if g.CellOf(35.6871, -105.9379).ID != c.ID {
+		t.Fatalf("nearby point should share a cell")
+	}
+	if h := DistanceKm(c.MinLat, c.MinLng, c.MaxLat, c.MinLng); math.Abs(h-5) > 0.1 {
+		t.Fatalf("cell height = %.2f km, want 5", h)
+	}
+}
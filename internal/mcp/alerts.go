@@ -0,0 +1,133 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/kpb/wingit-mcp/internal/alerts"
+	"github.com/kpb/wingit-mcp/internal/state"
+	"github.com/kpb/wingit-mcp/internal/users"
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// AlertsURI lists the calling user's alerts for newly reported needed species.
+const AlertsURI = "wingit://alerts"
+
+// alertsLimit caps how many alerts the resource returns.
+const alertsLimit = 50
+
+// RegisterAlerts adds the alerts resource, read from st.
+func RegisterAlerts(s *sdk.Server, reg *users.Registry, st *state.Store) {
+	s.AddResource(&sdk.Resource{
+		URI:         AlertsURI,
+		MIMEType:    "application/json",
+		Name:        "Target alerts",
+		Description: "Needed species newly reported for target_checklist queries run with your saved target profiles, newest first. Subscribe to hear about new ones.",
+	}, func(ctx context.Context, req *sdk.ReadResourceRequest) (*sdk.ReadResourceResult, error) {
+		u, err := reg.Resolve(RequestHeader(req), "")
+		if err != nil {
+			return nil, err
+		}
+		list, err := alerts.List(st, u.ID, alertsLimit)
+		if err != nil {
+			return nil, err
+		}
+		buf, err := json.MarshalIndent(struct {
+			User   string         `json:"user"`
+			Alerts []alerts.Alert `json:"alerts"`
+		}{u.ID, list}, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return &sdk.ReadResourceResult{
+			Contents: []*sdk.ResourceContents{
+				{URI: AlertsURI, MIMEType: "application/json", Text: string(buf)},
+			},
+		}, nil
+	})
+}
+
+// SessionUsers remembers which user each session last acted as, so alerts
+// are logged only to their own user's sessions.
+type SessionUsers struct {
+	mu    sync.Mutex
+	users map[*sdk.ServerSession]string
+}
+
+// NewSessionUsers returns an empty SessionUsers.
+func NewSessionUsers() *SessionUsers {
+	return &SessionUsers{users: map[*sdk.ServerSession]string{}}
+}
+
+// Middleware records the user behind every request that identifies one by
+// token, header or a tool's user argument (see users.Registry.Identify). A
+// session that only falls back to the default user gets no alerts.
+func (su *SessionUsers) Middleware(reg *users.Registry) sdk.Middleware {
+	return func(next sdk.MethodHandler) sdk.MethodHandler {
+		return func(ctx context.Context, method string, req sdk.Request) (sdk.Result, error) {
+			if ss, ok := req.GetSession().(*sdk.ServerSession); ok {
+				if u, ok := reg.Identify(RequestHeader(req), requestedUser(req)); ok {
+					su.mu.Lock()
+					su.users[ss] = u.ID
+					su.mu.Unlock()
+				}
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// requestedUser is the user argument of a tool call, if any.
+func requestedUser(req sdk.Request) string {
+	call, ok := req.(*sdk.CallToolRequest)
+	if !ok || call.Params == nil {
+		return ""
+	}
+	raw, ok := call.Params.Arguments.(json.RawMessage)
+	if !ok {
+		return ""
+	}
+	var args struct{ User string }
+	if json.Unmarshal(raw, &args) != nil {
+		return ""
+	}
+	return args.User
+}
+
+// NotifyAlerts logs each alert to its user's connected sessions and tells
+// subscribers the alerts resource changed. Sessions that have closed are
+// forgotten.
+func NotifyAlerts(ctx context.Context, s *sdk.Server, su *SessionUsers, list []alerts.Alert) error {
+	byUser := map[string][]alerts.Alert{}
+	for _, a := range list {
+		byUser[a.User] = append(byUser[a.User], a)
+	}
+
+	live := map[*sdk.ServerSession]bool{}
+	for ss := range s.Sessions() {
+		live[ss] = true
+	}
+	su.mu.Lock()
+	targets := map[*sdk.ServerSession]string{}
+	for ss, id := range su.users {
+		if !live[ss] {
+			delete(su.users, ss)
+			continue
+		}
+		targets[ss] = id
+	}
+	su.mu.Unlock()
+
+	for ss, id := range targets {
+		for _, a := range byUser[id] {
+			// Log only fails when the session has gone away.
+			_ = ss.Log(ctx, &sdk.LoggingMessageParams{
+				Level:  "notice",
+				Logger: "wingit-alerts",
+				Data:   map[string]any{"message": a.Message(), "alert": a},
+			})
+		}
+	}
+	return s.ResourceUpdated(ctx, &sdk.ResourceUpdatedNotificationParams{URI: AlertsURI})
+}
@@ -0,0 +1,88 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kpb/wingit-mcp/internal/alerts"
+	"github.com/kpb/wingit-mcp/internal/state"
+	"github.com/kpb/wingit-mcp/internal/users"
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func Test_alerts_resource_and_notifications(t *testing.T) {
+	t.Parallel()
+
+	st, err := state.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("state.Open: %v", err)
+	}
+	reg := testRegistry(t)
+	su := NewSessionUsers()
+	s := sdk.NewServer(&sdk.Implementation{Name: "wingit-test"}, &sdk.ServerOptions{
+		SubscribeHandler:   func(context.Context, *sdk.SubscribeRequest) error { return nil },
+		UnsubscribeHandler: func(context.Context, *sdk.UnsubscribeRequest) error { return nil },
+	})
+	s.AddReceivingMiddleware(su.Middleware(reg))
+	RegisterAlerts(s, reg, st)
+
+	logs := make(chan *sdk.LoggingMessageParams, 4)
+	updated := make(chan string, 4)
+	ctx := context.Background()
+	sst, ct := sdk.NewInMemoryTransports()
+	if _, err := s.Connect(ctx, sst, nil); err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	cs, err := sdk.NewClient(&sdk.Implementation{Name: "test"}, &sdk.ClientOptions{
+		LoggingMessageHandler: func(_ context.Context, req *sdk.LoggingMessageRequest) { logs <- req.Params },
+		ResourceUpdatedHandler: func(_ context.Context, req *sdk.ResourceUpdatedNotificationRequest) {
+			updated <- req.Params.URI
+		},
+	}).Connect(ctx, ct, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	t.Cleanup(func() { cs.Close() })
+
+	if err := cs.SetLoggingLevel(ctx, &sdk.SetLoggingLevelParams{Level: "info"}); err != nil {
+		t.Fatalf("SetLoggingLevel: %v", err)
+	}
+	if err := cs.Subscribe(ctx, &sdk.SubscribeParams{URI: AlertsURI}); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	a := alerts.Alert{At: time.Now(), User: users.DefaultUserID, Location: "Albuquerque, NM", SpeciesCode: "rethaw", CommonName: "Red-tailed Hawk"}
+	if err := st.Save(alerts.Bucket, a.User, a.At, a, alerts.Keep); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := NotifyAlerts(ctx, s, su, []alerts.Alert{a}); err != nil {
+		t.Fatalf("NotifyAlerts: %v", err)
+	}
+
+	select {
+	case p := <-logs:
+		if p.Logger != "wingit-alerts" || !strings.Contains(p.Data.(map[string]any)["message"].(string), "Red-tailed Hawk") {
+			t.Errorf("unexpected log: %+v", p)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no logging notification")
+	}
+	select {
+	case uri := <-updated:
+		if uri != AlertsURI {
+			t.Errorf("updated %s, want %s", uri, AlertsURI)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no resource-updated notification")
+	}
+
+	res, err := cs.ReadResource(ctx, &sdk.ReadResourceParams{URI: AlertsURI})
+	if err != nil {
+		t.Fatalf("ReadResource(%s): %v", AlertsURI, err)
+	}
+	if !strings.Contains(res.Contents[0].Text, `"speciesCode": "rethaw"`) {
+		t.Errorf("alerts resource = %s", res.Contents[0].Text)
+	}
+}
@@ -0,0 +1,23 @@
+package mcp
+
+import (
+	"github.com/kpb/wingit-mcp/internal/render"
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// RenderURIPrefix prefixes URIs of rendered documents embedded in tool results.
+const RenderURIPrefix = "wingit://render/"
+
+// DocumentContent wraps a rendered document as tool result content: Markdown
+// as plain text for any host, other formats as embedded resources so hosts
+// can save or display them by MIME type.
+func DocumentContent(doc render.Document) sdk.Content {
+	if doc.Format == render.FormatMarkdown {
+		return &sdk.TextContent{Text: doc.Text}
+	}
+	return &sdk.EmbeddedResource{Resource: &sdk.ResourceContents{
+		URI:      RenderURIPrefix + doc.Name,
+		MIMEType: doc.MIMEType,
+		Text:     doc.Text,
+	}}
+}
@@ -3,28 +3,71 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"net/http"
 
+	"github.com/kpb/wingit-mcp/internal/ebird"
+	"github.com/kpb/wingit-mcp/internal/render"
 	it "github.com/kpb/wingit-mcp/internal/types"
+	"github.com/kpb/wingit-mcp/internal/users"
 	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-func RegisterResources(s *sdk.Server, pc *it.PersonalChecklist) {
-	const personalURI = "wingit://personal-checklist"
+// RequestHeader returns the HTTP headers that carried req, or nil on stdio.
+func RequestHeader(req sdk.Request) http.Header {
+	if extra := req.GetExtra(); extra != nil {
+		return extra.Header
+	}
+	return nil
+}
+
+// PersonalURI is the resource URI of the calling user's personal checklist.
+const PersonalURI = "wingit://personal-checklist"
+
+// PersonalMapURI is the GeoJSON map of the calling user's personal sightings.
+const PersonalMapURI = "wingit://map/personal.geojson"
+
+// NotifyPersonalUpdated tells subscribed sessions the personal checklist changed.
+func NotifyPersonalUpdated(ctx context.Context, s *sdk.Server) error {
+	if err := s.ResourceUpdated(ctx, &sdk.ResourceUpdatedNotificationParams{URI: PersonalURI}); err != nil {
+		return err
+	}
+	return s.ResourceUpdated(ctx, &sdk.ResourceUpdatedNotificationParams{URI: PersonalMapURI})
+}
 
+func RegisterResources(s *sdk.Server, reg *users.Registry) {
 	s.AddResource(&sdk.Resource{
-		URI:         personalURI,
+		URI:         PersonalURI,
 		MIMEType:    "application/json",
 		Name:        "Personal eBird Checklist (normalized)",
-		Description: "Read-only view of the user's normalized personal eBird export.",
+		Description: "Read-only view of the calling user's normalized personal eBird export.",
 	}, func(ctx context.Context, req *sdk.ReadResourceRequest) (*sdk.ReadResourceResult, error) {
+		u, err := reg.Resolve(RequestHeader(req), "")
+		if err != nil {
+			return nil, err
+		}
+		// Serve the index reconciled with sightings, with where each species
+		// came from and where the exported index was stale. The store
+		// aggregates sightings per species rather than loading them all.
+		sum, err := u.Summary(ctx)
+		if err != nil {
+			return nil, err
+		}
+		rec := ebird.ReconcileSummary(sum)
+
 		payload := struct {
-			Meta           any               `json:"meta"`
-			SpeciesIndex   []it.SpeciesIndex `json:"speciesIndex,omitempty"`
-			CountSightings int               `json:"countSightings"`
+			User           string              `json:"user"`
+			Meta           any                 `json:"meta"`
+			SpeciesIndex   []it.SpeciesIndex   `json:"speciesIndex,omitempty"`
+			Provenance     map[string]string   `json:"provenance,omitempty"`
+			Discrepancies  []ebird.Discrepancy `json:"discrepancies,omitempty"`
+			CountSightings int                 `json:"countSightings"`
 		}{
-			Meta:           pc.Meta,
-			SpeciesIndex:   pc.SpeciesIndex,
-			CountSightings: len(pc.Sightings),
+			User:           u.ID,
+			Meta:           sum.Checklist.Meta,
+			SpeciesIndex:   rec.Index,
+			Provenance:     rec.Provenance,
+			Discrepancies:  rec.Discrepancies,
+			CountSightings: sum.Sightings,
 		}
 
 		buf, err := json.MarshalIndent(payload, "", "  ")
@@ -35,11 +78,36 @@ func RegisterResources(s *sdk.Server, pc *it.PersonalChecklist) {
 		return &sdk.ReadResourceResult{
 			Contents: []*sdk.ResourceContents{
 				{
-					URI:      personalURI,
+					URI:      PersonalURI,
 					MIMEType: "application/json",
 					Text:     string(buf),
 				},
 			},
 		}, nil
 	})
+
+	s.AddResource(&sdk.Resource{
+		URI:         PersonalMapURI,
+		MIMEType:    "application/geo+json",
+		Name:        "Personal sightings map (GeoJSON)",
+		Description: "The calling user's personal sighting locations as a GeoJSON FeatureCollection.",
+	}, func(ctx context.Context, req *sdk.ReadResourceRequest) (*sdk.ReadResourceResult, error) {
+		u, err := reg.Resolve(RequestHeader(req), "")
+		if err != nil {
+			return nil, err
+		}
+		locs, err := u.Locations(ctx)
+		if err != nil {
+			return nil, err
+		}
+		text, err := render.LocationsGeoJSON(locs)
+		if err != nil {
+			return nil, err
+		}
+		return &sdk.ReadResourceResult{
+			Contents: []*sdk.ResourceContents{
+				{URI: PersonalMapURI, MIMEType: "application/geo+json", Text: text},
+			},
+		}, nil
+	})
 }
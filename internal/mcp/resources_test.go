@@ -0,0 +1,34 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func Test_personal_resources(t *testing.T) {
+	t.Parallel()
+
+	s := sdk.NewServer(&sdk.Implementation{Name: "wingit-test"}, nil)
+	RegisterResources(s, testRegistry(t))
+	cs := connect(t, s)
+	ctx := context.Background()
+
+	res, err := cs.ReadResource(ctx, &sdk.ReadResourceParams{URI: PersonalURI})
+	if err != nil {
+		t.Fatalf("ReadResource(%s): %v", PersonalURI, err)
+	}
+	if !strings.Contains(res.Contents[0].Text, `"countSightings": 2`) {
+		t.Fatalf("personal checklist = %s", res.Contents[0].Text)
+	}
+
+	res, err = cs.ReadResource(ctx, &sdk.ReadResourceParams{URI: PersonalMapURI})
+	if err != nil {
+		t.Fatalf("ReadResource(%s): %v", PersonalMapURI, err)
+	}
+	if c := res.Contents[0]; c.MIMEType != "application/geo+json" || !strings.Contains(c.Text, `"FeatureCollection"`) {
+		t.Fatalf("personal map = %+v", c)
+	}
+}
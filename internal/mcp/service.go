@@ -0,0 +1,244 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/kpb/wingit-mcp/internal/alerts"
+	"github.com/kpb/wingit-mcp/internal/ebird"
+	"github.com/kpb/wingit-mcp/internal/prompts"
+	"github.com/kpb/wingit-mcp/internal/state"
+	"github.com/kpb/wingit-mcp/internal/taxonomy"
+	"github.com/kpb/wingit-mcp/internal/tools"
+	"github.com/kpb/wingit-mcp/internal/users"
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Service owns the server's tool handlers and everything they read, so the
+// stdio and HTTP servers, the command line and tests all run the same code
+// against whatever data is injected.
+type Service struct {
+	Users *users.Registry
+	// Recent supplies recent nearby observations, fetched on every call. A
+	// failure is logged and the call carries on with none.
+	Recent   alerts.Source
+	Taxonomy *taxonomy.Taxonomy // optional
+	Locales  *taxonomy.Locales  // optional
+	// State saves target results for since and alerts; nil saves nothing.
+	State *state.Store
+	// Profiles holds saved target query presets; nil has none.
+	Profiles *tools.TargetProfiles
+	// Defaults fill target query fields a call leaves unset.
+	Defaults tools.TargetDefaults
+	Clock    alerts.Clock // defaults to alerts.SystemClock
+	Logger   *log.Logger  // defaults to discarding
+
+	server   *sdk.Server
+	sessions *SessionUsers
+}
+
+// NewServer builds the MCP server with every prompt, resource and tool.
+// Call it once per Service: notifications go to the server it returns.
+func (svc *Service) NewServer(impl *sdk.Implementation) *sdk.Server {
+	s := sdk.NewServer(impl, &sdk.ServerOptions{
+		// Accept subscriptions so hosts hear about personal checklist reloads.
+		SubscribeHandler:   func(context.Context, *sdk.SubscribeRequest) error { return nil },
+		UnsubscribeHandler: func(context.Context, *sdk.UnsubscribeRequest) error { return nil },
+		CompletionHandler:  CompletionHandler(svc.Users, svc.Taxonomy),
+	})
+	svc.server = s
+
+	// Register prompts before tools so the host sees them on initialize.
+	prompts.Register(s)
+	RegisterResources(s, svc.Users)
+	RegisterResourceTemplates(s, svc.Users)
+	RegisterAlerts(s, svc.Users, svc.State)
+
+	// Remember which user each session acts as, to route alert logs.
+	svc.sessions = NewSessionUsers()
+	s.AddReceivingMiddleware(svc.sessions.Middleware(svc.Users))
+
+	svc.registerTools(s)
+	return s
+}
+
+// Poller re-runs saved target queries against Recent and notifies the
+// server's sessions of new alerts. Without State there is nothing to poll
+// and it returns nil.
+func (svc *Service) Poller() *alerts.Poller {
+	if svc.State == nil {
+		return nil
+	}
+	return &alerts.Poller{
+		State:  svc.State,
+		Source: svc.Recent,
+		Seen: func(id string) (map[string]struct{}, error) {
+			u, err := svc.Users.Get(id)
+			if err != nil {
+				return nil, err
+			}
+			return u.Seen(), nil
+		},
+		// Only the queries of the user's saved target profiles are watched,
+		// and only while the profile exists; one-off queries are not polled.
+		Watched: func(q tools.TargetSnapshot) bool {
+			presets, err := svc.Profiles.List(q.User)
+			if err != nil {
+				return false
+			}
+			key := tools.TargetQueryKey(q.User, q.Args)
+			for _, p := range presets {
+				if tools.TargetQueryKey(q.User, svc.Defaults.Apply(p.Args)) == key {
+					return true
+				}
+			}
+			return false
+		},
+		Clock: svc.clock(),
+		Notify: func(ctx context.Context, list []alerts.Alert) {
+			for _, a := range list {
+				svc.logf("alert for %q: %s", a.User, a.Message())
+			}
+			if svc.server == nil {
+				return
+			}
+			if err := NotifyAlerts(ctx, svc.server, svc.sessions, list); err != nil {
+				svc.logf("WARN: notify alerts: %v", err)
+			}
+		},
+		OnError: func(query string, err error) {
+			svc.logf("WARN: poll target query %q: %v", query, err)
+		},
+	}
+}
+
+// NotifyPersonalUpdated tells the server's subscribers a personal
+// checklist changed, e.g. after a file reload.
+func (svc *Service) NotifyPersonalUpdated(ctx context.Context) {
+	if svc.server == nil {
+		return
+	}
+	if err := NotifyPersonalUpdated(ctx, svc.server); err != nil {
+		svc.logf("WARN: notify personal updated: %v", err)
+	}
+}
+
+// TargetChecklist runs a target query for u end to end: saved preset and
+// defaults, fresh recent observations, engine, locale, sort and the saved
+// history behind since. It returns the completed arguments and the recent
+// observations it used, for hotspots.
+func (svc *Service) TargetChecklist(ctx context.Context, u *users.User, args tools.TargetArgs) (tools.TargetResult, tools.TargetArgs, []tools.RecentObservation, error) {
+	out, args, recent, err := svc.PreviewTargets(ctx, u, args)
+	if err != nil {
+		return out, args, nil, err
+	}
+	if out.Changes, err = svc.recordTargets(u, args, out); err != nil {
+		return out, args, nil, err
+	}
+	return out, args, recent, nil
+}
+
+// PreviewTargets is TargetChecklist without the history: the result is
+// neither saved nor compared with earlier runs, so since is ignored.
+func (svc *Service) PreviewTargets(ctx context.Context, u *users.User, args tools.TargetArgs) (tools.TargetResult, tools.TargetArgs, []tools.RecentObservation, error) {
+	args, err := svc.withProfile(u, args)
+	if err != nil {
+		return tools.TargetResult{}, args, nil, err
+	}
+	recent := svc.recent(ctx)
+	out, err := svc.runTargets(ctx, u, args, recent)
+	if err != nil {
+		return out, args, nil, err
+	}
+	return out, args, recent, nil
+}
+
+// SpeciesInfo is the species_info pipeline: the query is resolved first,
+// against the taxonomy or else the user's own species, and only that
+// species' sightings are then read.
+func (svc *Service) SpeciesInfo(ctx context.Context, u *users.User, args tools.SpeciesInfoArgs) (tools.SpeciesInfo, error) {
+	tax := svc.Taxonomy
+	if tax == nil {
+		var err error
+		if tax, err = u.PersonalTaxonomy(ctx); err != nil {
+			return tools.SpeciesInfo{}, err
+		}
+	}
+	out, err := tools.ResolveSpeciesInfo(args, tax)
+	if err != nil {
+		return out, err
+	}
+	pc, err := u.Subset(ctx, ebird.Filter{SpeciesCodes: []string{out.Species.SpeciesCode}})
+	if err != nil {
+		return out, err
+	}
+	return out.WithHistory(pc), nil
+}
+
+// withProfile fills the fields a call leaves unset from its saved preset,
+// then from the configured defaults.
+func (svc *Service) withProfile(u *users.User, args tools.TargetArgs) (tools.TargetArgs, error) {
+	if args.Profile != "" {
+		preset, err := svc.Profiles.Get(u.ID, args.Profile)
+		if err != nil {
+			return args, err
+		}
+		args = tools.ApplyProfile(preset, args)
+	}
+	return svc.Defaults.Apply(args), nil
+}
+
+// runTargets is the target_checklist pipeline: engine, locale, sort.
+func (svc *Service) runTargets(ctx context.Context, u *users.User, args tools.TargetArgs, recent []tools.RecentObservation) (tools.TargetResult, error) {
+	out, err := tools.BuildTargetChecklist(ctx, args, u.Seen(), recent)
+	if err != nil {
+		return out, err
+	}
+	if args.Locale != "" {
+		if !svc.Locales.Has(args.Locale) {
+			return out, fmt.Errorf("locale %q not loaded (available: %v)", args.Locale, svc.Locales.Available())
+		}
+		out = tools.LocalizeTargets(out, args.Locale, svc.Locales.CommonName)
+	}
+	return tools.SortTargets(out, args.SortOrder, svc.Taxonomy)
+}
+
+// recordTargets saves a target result for later diffs, returning the
+// changes when args.Since asks for them. Only a bad since fails the call.
+func (svc *Service) recordTargets(u *users.User, args tools.TargetArgs, out tools.TargetResult) (*tools.TargetChanges, error) {
+	changes, err := tools.RecordTargets(svc.State, u.ID, args, out, svc.clock().Now())
+	if err != nil {
+		if args.Since != "" && changes == nil {
+			return nil, err
+		}
+		svc.logf("WARN: save target result: %v", err)
+	}
+	return changes, nil
+}
+
+// recent fetches recent observations, failing softly.
+func (svc *Service) recent(ctx context.Context) []tools.RecentObservation {
+	if svc.Recent == nil {
+		return nil
+	}
+	rows, err := svc.Recent.Recent(ctx)
+	if err != nil {
+		svc.logf("WARN: recent observations: %v (continuing with empty recent)", err)
+		return nil
+	}
+	return rows
+}
+
+func (svc *Service) clock() alerts.Clock {
+	if svc.Clock == nil {
+		return alerts.SystemClock{}
+	}
+	return svc.Clock
+}
+
+func (svc *Service) logf(format string, a ...any) {
+	if svc.Logger != nil {
+		svc.Logger.Printf(format, a...)
+	}
+}
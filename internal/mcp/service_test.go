@@ -0,0 +1,326 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kpb/wingit-mcp/internal/alerts"
+	"github.com/kpb/wingit-mcp/internal/ebird"
+	"github.com/kpb/wingit-mcp/internal/state"
+	"github.com/kpb/wingit-mcp/internal/tools"
+	"github.com/kpb/wingit-mcp/internal/users"
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// fixedClock is always at one instant; the service never waits on it.
+type fixedClock struct{ now time.Time }
+
+func (c fixedClock) Now() time.Time                     { return c.now }
+func (fixedClock) After(time.Duration) <-chan time.Time { return nil }
+
+const testLocation = "35.687,-105.938"
+
+func testRecent() []tools.RecentObservation {
+	return []tools.RecentObservation{
+		{SpeciesCode: "lewwoo", CommonName: "Lewis's Woodpecker", SciName: "Melanerpes lewis", LocName: "Randall Davey Audubon Center", LocID: "L100", Lat: 35.69, Lng: -105.89, ObsDt: "2026-09-11"},
+		{SpeciesCode: "pinsis", CommonName: "Pine Siskin", SciName: "Spinus pinus", LocName: "Santa Fe Canyon Preserve", LocID: "L200", Lat: 35.68, Lng: -105.90, ObsDt: "2026-09-09"},
+		// Already seen: never a target.
+		{SpeciesCode: "clanut", CommonName: "Clark's Nutcracker", SciName: "Nucifraga columbiana", LocName: "Aspen Vista", LocID: "L654321", Lat: 35.76, Lng: -105.80, ObsDt: "2026-09-08"},
+	}
+}
+
+// testService serves a Service over the personal test checklist with
+// in-memory recent observations, state and profiles.
+func testService(t *testing.T, recent *[]tools.RecentObservation) (*Service, *sdk.Server) {
+	t.Helper()
+	st, err := state.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("state.Open: %v", err)
+	}
+	svc := &Service{
+		Users: testRegistry(t),
+		Recent: alerts.SourceFunc(func(context.Context) ([]tools.RecentObservation, error) {
+			return *recent, nil
+		}),
+		State:    st,
+		Profiles: tools.OpenTargetProfiles(filepath.Join(t.TempDir(), "profiles.json")),
+		Defaults: tools.BuiltinTargetDefaults(),
+		Clock:    fixedClock{time.Date(2026, 9, 12, 7, 0, 0, 0, time.UTC)},
+	}
+	return svc, svc.NewServer(&sdk.Implementation{Name: "wingit-test"})
+}
+
+func callTool(t *testing.T, cs *sdk.ClientSession, name string, args map[string]any) *sdk.CallToolResult {
+	t.Helper()
+	res, err := cs.CallTool(context.Background(), &sdk.CallToolParams{Name: name, Arguments: args})
+	if err != nil {
+		t.Fatalf("CallTool(%s): %v", name, err)
+	}
+	if res.IsError {
+		t.Fatalf("CallTool(%s) failed: %s", name, res.Content[0].(*sdk.TextContent).Text)
+	}
+	return res
+}
+
+// decodeStructured decodes a tool's structured content into v.
+func decodeStructured(t *testing.T, res *sdk.CallToolResult, v any) {
+	t.Helper()
+	b, err := json.Marshal(res.StructuredContent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		t.Fatalf("decode structured content: %v", err)
+	}
+}
+
+func Test_service_serves_every_tool(t *testing.T) {
+	t.Parallel()
+
+	recent := testRecent()
+	_, s := testService(t, &recent)
+	cs := connect(t, s)
+	ctx := context.Background()
+
+	calls := []struct {
+		tool string
+		args map[string]any
+		want string
+	}{
+		{"target_checklist", map[string]any{"location": testLocation, "format": "md"}, "2 candidate lifers; top: Lewis's Woodpecker"},
+		{"target_changes", map[string]any{"location": testLocation}, "WingIt-MCP: "},
+		{"create_target_profile", map[string]any{"name": "home patch", "location": testLocation, "radiusKm": 10}, `Saved target profile "home patch"`},
+		{"list_target_profiles", nil, "Saved target profiles: home patch"},
+		{"target_checklist", map[string]any{"profile": "home patch", "since": "last"}, "2 candidate lifers"},
+		{"delete_target_profile", map[string]any{"name": "home patch"}, `Deleted target profile "home patch"`},
+		{"group_targets", map[string]any{"location": testLocation, "users": []string{"default"}}, "2 species needed by the group"},
+		{"export_map", map[string]any{"location": testLocation, "mapFormat": "kml"}, "2 hotspots for 2 likely lifers"},
+		{"coverage_grid", nil, "birded cells"},
+		{"reload_personal", nil, "Reloaded personal checklist: 2 species seen"},
+		{"import_personal", map[string]any{"dryRun": true}, "Dry run: "},
+		{"life_stats", nil, "2 species on your life list"},
+		{"lifer_timeline", map[string]any{"onThisDay": true}, "On 09-12 you birded in 1 past years"},
+		{"species_info", map[string]any{"query": "clanut"}, "Clark's Nutcracker (Nucifraga columbiana): first seen"},
+	}
+
+	// checks assert the structured results of calls that only summarize
+	// in text.
+	checks := map[string]func(t *testing.T, res *sdk.CallToolResult){
+		"target_changes": func(t *testing.T, res *sdk.CallToolResult) {
+			var c tools.TargetChanges
+			decodeStructured(t, res, &c)
+			if c.Baseline != "2026-09-12T07:00:00Z" || c.Changed() || c.Unchanged != 2 {
+				t.Errorf("target_changes: expected 2 unchanged targets since the first run, got %+v", c)
+			}
+		},
+		"coverage_grid": func(t *testing.T, res *sdk.CallToolResult) {
+			var g tools.CoverageGrid
+			decodeStructured(t, res, &g)
+			if len(g.Cells) != 2 || g.Cells[0].Checklists != 1 || g.Cells[0].Species != 1 {
+				t.Errorf("coverage_grid: expected 2 cells of one checklist each, got %+v", g.Cells)
+			}
+			if len(g.Unbirded) != 1 || g.Unbirded[0].LiferCount != 1 || g.Unbirded[0].LiferSpecies[0] != "lewwoo" {
+				t.Errorf("coverage_grid: expected one unbirded cell with Lewis's Woodpecker, got %+v", g.Unbirded)
+			}
+		},
+		"import_personal": func(t *testing.T, res *sdk.CallToolResult) {
+			var r ebird.ChangeReport
+			decodeStructured(t, res, &r)
+			if r.Changed() || r.DuplicatesDropped != 0 || len(r.NewChecklists) != 0 || len(r.DeletedChecklists) != 0 {
+				t.Errorf("import_personal: re-importing the same export should change nothing, got %+v", r)
+			}
+		},
+	}
+
+	tools, err := cs.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTools: %v", err)
+	}
+	var listed, called []string
+	for _, tool := range tools.Tools {
+		listed = append(listed, tool.Name)
+	}
+	seen := map[string]bool{}
+	for _, c := range calls {
+		if !seen[c.tool] {
+			seen[c.tool] = true
+			called = append(called, c.tool)
+		}
+	}
+	sort.Strings(listed)
+	sort.Strings(called)
+	if strings.Join(listed, ",") != strings.Join(called, ",") {
+		t.Errorf("tools listed %v, tested %v", listed, called)
+	}
+
+	for _, c := range calls {
+		res := callTool(t, cs, c.tool, c.args)
+		if text := res.Content[0].(*sdk.TextContent).Text; !strings.Contains(text, c.want) {
+			t.Errorf("%s: expected %q in %q", c.tool, c.want, text)
+		}
+		if check := checks[c.tool]; check != nil {
+			check(t, res)
+		}
+	}
+}
+
+func Test_service_target_checklist_output(t *testing.T) {
+	t.Parallel()
+
+	recent := testRecent()
+	_, s := testService(t, &recent)
+	cs := connect(t, s)
+
+	res := callTool(t, cs, "target_checklist", map[string]any{"location": testLocation, "format": "csv"})
+	if len(res.Content) != 2 {
+		t.Fatalf("expected summary and document, got %d contents", len(res.Content))
+	}
+	var out tools.TargetResult
+	decodeStructured(t, res, &out)
+	if len(out.Targets) != 2 || out.Targets[0].SpeciesCode != "lewwoo" {
+		t.Errorf("unexpected targets: %+v", out.Targets)
+	}
+	if out.Filters.RadiusKm != tools.BuiltinTargetDefaults().RadiusKm {
+		t.Errorf("expected the default radius, got %g", out.Filters.RadiusKm)
+	}
+	if doc, ok := res.Content[1].(*sdk.EmbeddedResource); !ok || !strings.HasPrefix(doc.Resource.Text, "species_code,") {
+		t.Errorf("expected an embedded CSV document, got %+v", res.Content[1])
+	}
+}
+
+func Test_service_serves_prompts_and_resources(t *testing.T) {
+	t.Parallel()
+
+	recent := testRecent()
+	_, s := testService(t, &recent)
+	cs := connect(t, s)
+	ctx := context.Background()
+
+	p, err := cs.GetPrompt(ctx, &sdk.GetPromptParams{Name: "field_checklist", Arguments: map[string]string{"location": "Santa Fe"}})
+	if err != nil {
+		t.Fatalf("GetPrompt: %v", err)
+	}
+	if text := p.Messages[0].Content.(*sdk.TextContent).Text; !strings.Contains(text, "Santa Fe") {
+		t.Errorf("prompt does not name the location: %s", text)
+	}
+
+	resources, err := cs.ListResources(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListResources: %v", err)
+	}
+	if len(resources.Resources) != 3 {
+		t.Errorf("expected 3 resources, got %d", len(resources.Resources))
+	}
+	for _, r := range resources.Resources {
+		res, err := cs.ReadResource(ctx, &sdk.ReadResourceParams{URI: r.URI})
+		if err != nil {
+			t.Errorf("ReadResource(%s): %v", r.URI, err)
+			continue
+		}
+		if res.Contents[0].Text == "" {
+			t.Errorf("ReadResource(%s): empty", r.URI)
+		}
+	}
+
+	templates, err := cs.ListResourceTemplates(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListResourceTemplates: %v", err)
+	}
+	if len(templates.ResourceTemplates) != 3 {
+		t.Errorf("expected 3 resource templates, got %d", len(templates.ResourceTemplates))
+	}
+	for _, uri := range []string{"wingit://species/clanut", "wingit://location/L654321", "wingit://checklist/S100000001"} {
+		if _, err := cs.ReadResource(ctx, &sdk.ReadResourceParams{URI: uri}); err != nil {
+			t.Errorf("ReadResource(%s): %v", uri, err)
+		}
+	}
+}
+
+func Test_service_poller_alerts_on_new_needed_species(t *testing.T) {
+	t.Parallel()
+
+	recent := testRecent()
+	svc, s := testService(t, &recent)
+	cs := connect(t, s)
+
+	// Only the query run through a saved profile is watched.
+	callTool(t, cs, "create_target_profile", map[string]any{"name": "home", "location": testLocation, "radiusKm": 10})
+	callTool(t, cs, "target_checklist", map[string]any{"profile": "home"})
+	callTool(t, cs, "target_checklist", map[string]any{"location": testLocation, "radiusKm": 25})
+	recent = append(recent, tools.RecentObservation{
+		SpeciesCode: "grroad", CommonName: "Greater Roadrunner", SciName: "Geococcyx californianus",
+		LocName: "Santa Fe Canyon Preserve", LocID: "L200", Lat: 35.68, Lng: -105.90, ObsDt: "2026-09-12",
+	})
+	list, err := svc.Poller().Poll(context.Background())
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if len(list) != 1 || list[0].SpeciesCode != "grroad" {
+		t.Fatalf("expected one roadrunner alert, got %+v", list)
+	}
+	res, err := cs.ReadResource(context.Background(), &sdk.ReadResourceParams{URI: AlertsURI})
+	if err != nil {
+		t.Fatalf("ReadResource(%s): %v", AlertsURI, err)
+	}
+	if !strings.Contains(res.Contents[0].Text, "Greater Roadrunner") {
+		t.Errorf("alerts resource = %s", res.Contents[0].Text)
+	}
+
+	// Deleting the profile stops its alerts.
+	callTool(t, cs, "delete_target_profile", map[string]any{"name": "home"})
+	recent = append(recent, tools.RecentObservation{
+		SpeciesCode: "scrjay", CommonName: "Woodhouse's Scrub-Jay", SciName: "Aphelocoma woodhouseii",
+		LocName: "Santa Fe Canyon Preserve", LocID: "L200", Lat: 35.68, Lng: -105.90, ObsDt: "2026-09-12",
+	})
+	if list, err = svc.Poller().Poll(context.Background()); err != nil || len(list) != 0 {
+		t.Fatalf("after deleting the profile Poll = %+v, %v; want no alerts", list, err)
+	}
+}
+
+func Test_group_targets_needs_shared_life_lists(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	pc, err := os.ReadFile(filepath.Join("testdata", "personal_checklist_example.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "pc.json"), pc, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	profiles := `{"default":"alice","users":[
+		{"id":"alice","personalJson":"pc.json"},
+		{"id":"bob","personalJson":"pc.json","shareWith":["alice"]},
+		{"id":"carol","personalJson":"pc.json"}]}`
+	if err := os.WriteFile(filepath.Join(dir, users.ProfilesFile), []byte(profiles), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	reg, err := users.LoadDir(dir)
+	if err != nil {
+		t.Fatalf("LoadDir: %v", err)
+	}
+
+	recent := testRecent()
+	svc, s := testService(t, &recent)
+	svc.Users = reg
+	cs := connect(t, s)
+
+	// Calls resolve to alice, the default.
+	callTool(t, cs, "group_targets", map[string]any{"location": testLocation, "users": []string{"alice", "bob"}})
+	for _, group := range [][]string{{"bob"}, {"alice", "carol"}} {
+		res, err := cs.CallTool(context.Background(), &sdk.CallToolParams{Name: "group_targets",
+			Arguments: map[string]any{"location": testLocation, "users": group}})
+		if err != nil {
+			t.Fatalf("CallTool: %v", err)
+		}
+		if !res.IsError {
+			t.Errorf("group %v: expected an error", group)
+		}
+	}
+}
@@ -0,0 +1,415 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kpb/wingit-mcp/internal/render"
+	"github.com/kpb/wingit-mcp/internal/tools"
+	"github.com/kpb/wingit-mcp/internal/users"
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type reloadArgs struct {
+	User string `json:",omitempty"`
+}
+
+type profileListArgs struct {
+	User string `json:",omitempty"`
+}
+
+type profileList struct {
+	Profiles []tools.TargetProfile
+}
+
+type profileDeleteArgs struct {
+	Name string
+	User string `json:",omitempty"`
+}
+
+type importArgs struct {
+	// Path is the new export to ingest: the user's checklist file (the
+	// default) or a file in the users directory.
+	Path string `json:",omitempty"`
+	// DryRun reports the changes without applying them.
+	DryRun bool   `json:",omitempty"`
+	User   string `json:",omitempty"`
+}
+
+// registerTools adds every tool. The SDK infers each tool's JSON Schema
+// from its argument and result types.
+func (svc *Service) registerTools(s *sdk.Server) {
+	// Register the target_checklist tool.
+	sdk.AddTool(s, &sdk.Tool{
+		Name:        "target_checklist",
+		Description: "Return likely new lifers near a location by comparing recent eBird observations with your personal history. Use profile to run a saved preset (other arguments override it), and since (\"last\" or a date) to also get what changed since an earlier run of the same query.",
+	}, func(ctx context.Context, req *sdk.CallToolRequest, args tools.TargetArgs) (*sdk.CallToolResult, any, error) {
+		u, err := svc.Users.Resolve(RequestHeader(req), args.User)
+		if err != nil {
+			return nil, nil, err
+		}
+		out, args, recent, err := svc.TargetChecklist(ctx, u, args)
+		if err != nil {
+			return nil, nil, err
+		}
+		hotspots, _ := tools.TargetHotspots(out, recent)
+		doc, rendered, err := render.TargetChecklist(args.Format, out, hotspots)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Short text for the host UI; the result is the structured output.
+		summary := TargetSummary(out)
+		if out.Changes != nil {
+			summary += "; " + out.Changes.Summary()
+		}
+
+		res := &sdk.CallToolResult{
+			Content: []sdk.Content{
+				&sdk.TextContent{Text: summary},
+			},
+		}
+		if rendered {
+			res.Content = append(res.Content, DocumentContent(doc))
+		}
+
+		// Return both: user-facing text and structured JSON (engine result).
+		return res, out, nil
+	})
+
+	// Register the target_changes tool: the morning "what's new" view.
+	sdk.AddTool(s, &sdk.Tool{
+		Name:        "target_changes",
+		Description: "Run a target_checklist query and report what changed since its last run (or since a date): new targets, targets no longer reported and frequency changes.",
+	}, func(ctx context.Context, req *sdk.CallToolRequest, args tools.TargetArgs) (*sdk.CallToolResult, any, error) {
+		u, err := svc.Users.Resolve(RequestHeader(req), args.User)
+		if err != nil {
+			return nil, nil, err
+		}
+		if args, err = svc.withProfile(u, args); err != nil {
+			return nil, nil, err
+		}
+		if args.Since == "" {
+			args.Since = "last"
+		}
+		out, err := svc.runTargets(ctx, u, args, svc.recent(ctx))
+		if err != nil {
+			return nil, nil, err
+		}
+		changes, err := svc.recordTargets(u, args, out)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &sdk.CallToolResult{
+			Content: []sdk.Content{&sdk.TextContent{Text: "WingIt-MCP: " + changes.Summary()}},
+		}, changes, nil
+	})
+
+	// Register the target profile tools: named presets for target queries.
+	sdk.AddTool(s, &sdk.Tool{
+		Name:        "create_target_profile",
+		Description: "Save a named target query preset (e.g. \"home patch\") with a location, radius and filters; pass it as profile to target_checklist. Replaces a preset of the same name.",
+	}, func(ctx context.Context, req *sdk.CallToolRequest, args tools.TargetProfileArgs) (*sdk.CallToolResult, any, error) {
+		u, err := svc.Users.Resolve(RequestHeader(req), args.User)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := svc.Profiles.Put(u.ID, args.Name, args.TargetArgs()); err != nil {
+			return nil, nil, err
+		}
+		out := tools.TargetProfile{Name: args.Name, Args: args.TargetArgs()}
+		return &sdk.CallToolResult{
+			Content: []sdk.Content{&sdk.TextContent{Text: fmt.Sprintf("Saved target profile %q for %s", args.Name, args.Location)}},
+		}, out, nil
+	})
+
+	sdk.AddTool(s, &sdk.Tool{
+		Name:        "list_target_profiles",
+		Description: "List your saved target query presets.",
+	}, func(ctx context.Context, req *sdk.CallToolRequest, args profileListArgs) (*sdk.CallToolResult, any, error) {
+		u, err := svc.Users.Resolve(RequestHeader(req), args.User)
+		if err != nil {
+			return nil, nil, err
+		}
+		list, err := svc.Profiles.List(u.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		summary := "No saved target profiles"
+		if len(list) > 0 {
+			names := make([]string, len(list))
+			for i, p := range list {
+				names[i] = p.Name
+			}
+			summary = "Saved target profiles: " + strings.Join(names, ", ")
+		}
+		return &sdk.CallToolResult{
+			Content: []sdk.Content{&sdk.TextContent{Text: summary}},
+		}, profileList{Profiles: list}, nil
+	})
+
+	sdk.AddTool(s, &sdk.Tool{
+		Name:        "delete_target_profile",
+		Description: "Delete one of your saved target query presets.",
+	}, func(ctx context.Context, req *sdk.CallToolRequest, args profileDeleteArgs) (*sdk.CallToolResult, any, error) {
+		u, err := svc.Users.Resolve(RequestHeader(req), args.User)
+		if err != nil {
+			return nil, nil, err
+		}
+		ok, err := svc.Profiles.Delete(u.ID, args.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown target profile %q", args.Name)
+		}
+		return &sdk.CallToolResult{
+			Content: []sdk.Content{&sdk.TextContent{Text: fmt.Sprintf("Deleted target profile %q", args.Name)}},
+		}, nil, nil
+	})
+
+	// Register the group_targets tool: shared needs across several profiles.
+	sdk.AddTool(s, &sdk.Tool{
+		Name:        "group_targets",
+		Description: "Rank recent nearby species by how many birders in a group still need them, showing who needs each one and which stops help the most people. The group must include you, and the others must share their life lists with you (shareWith in users.json).",
+	}, func(ctx context.Context, req *sdk.CallToolRequest, args tools.GroupArgs) (*sdk.CallToolResult, any, error) {
+		// The caller must be in the group, and every other member must have
+		// shared their life list with the caller.
+		caller, err := svc.Users.Resolve(RequestHeader(req), "")
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(args.Users) == 0 {
+			return nil, nil, fmt.Errorf("users is required")
+		}
+		if !slices.Contains(args.Users, caller.ID) {
+			return nil, nil, fmt.Errorf("users must include you (%q)", caller.ID)
+		}
+		seenByUser := make(map[string]map[string]struct{}, len(args.Users))
+		for _, id := range args.Users {
+			u, err := svc.Users.Get(id)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !u.SharesWith(caller.ID) {
+				return nil, nil, fmt.Errorf("%w: %q has not shared their life list with %q", users.ErrUnauthorized, u.ID, caller.ID)
+			}
+			seenByUser[u.ID] = u.Seen()
+		}
+
+		out, err := tools.BuildGroupTargets(ctx, args, seenByUser, svc.recent(ctx))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		summary := "WingIt-MCP: no species needed by this group"
+		if n := len(out.Targets); n > 0 {
+			top := out.Targets[0]
+			summary = fmt.Sprintf("%d species needed by the group; top: %s (%d of %d)",
+				n, top.CommonName, top.NeededCount, len(out.Users))
+		}
+		return &sdk.CallToolResult{
+			Content: []sdk.Content{&sdk.TextContent{Text: summary}},
+		}, out, nil
+	})
+
+	// Register the export_map tool: target hotspots as GPX/KML for navigation.
+	sdk.AddTool(s, &sdk.Tool{
+		Name:        "export_map",
+		Description: "Export the hotspots where your likely lifers were recently reported as GPX waypoints or KML placemarks.",
+	}, func(ctx context.Context, req *sdk.CallToolRequest, args tools.ExportMapArgs) (*sdk.CallToolResult, any, error) {
+		u, err := svc.Users.Resolve(RequestHeader(req), args.User)
+		if err != nil {
+			return nil, nil, err
+		}
+		recent := svc.recent(ctx)
+		out, err := svc.runTargets(ctx, u, svc.Defaults.Apply(args.TargetArgs()), recent)
+		if err != nil {
+			return nil, nil, err
+		}
+		hotspots, skipped := tools.TargetHotspots(out, recent)
+		doc, err := render.Hotspots(args.MapFormat, "WingIt targets near "+out.Filters.Location, hotspots)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		summary := fmt.Sprintf("%d hotspots for %d likely lifers", len(hotspots), len(out.Targets))
+		if skipped > 0 {
+			summary += fmt.Sprintf(" (%d locations without coordinates skipped)", skipped)
+		}
+		return &sdk.CallToolResult{
+			Content: []sdk.Content{
+				&sdk.TextContent{Text: summary},
+				DocumentContent(doc),
+			},
+		}, nil, nil
+	})
+
+	// Register the coverage_grid tool: where you've birded and where you haven't.
+	sdk.AddTool(s, &sdk.Tool{
+		Name:        "coverage_grid",
+		Description: "Bin your personal sightings into a km or geohash grid with per-cell species and checklist counts, and list nearby cells you've never birded where lifers were recently reported.",
+	}, func(ctx context.Context, req *sdk.CallToolRequest, args tools.CoverageGridArgs) (*sdk.CallToolResult, any, error) {
+		u, err := svc.Users.Resolve(RequestHeader(req), args.User)
+		if err != nil {
+			return nil, nil, err
+		}
+		pc, err := u.Checklist(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		out, err := tools.BuildCoverageGrid(ctx, args, pc, u.Seen(), svc.recent(ctx))
+		if err != nil {
+			return nil, nil, err
+		}
+		summary := fmt.Sprintf("%d birded cells; %d unbirded cells with recent lifers within %.0f km", len(out.Cells), len(out.Unbirded), out.RadiusKm)
+		return &sdk.CallToolResult{
+			Content: []sdk.Content{&sdk.TextContent{Text: summary}},
+		}, out, nil
+	})
+
+	// Register the reload_personal tool for a manual refresh after exporting.
+	sdk.AddTool(s, &sdk.Tool{
+		Name:        "reload_personal",
+		Description: "Re-read your personal eBird checklist file now instead of waiting for the file watcher.",
+	}, func(ctx context.Context, req *sdk.CallToolRequest, args reloadArgs) (*sdk.CallToolResult, any, error) {
+		u, err := svc.Users.Resolve(RequestHeader(req), args.User)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := u.Reload(); err != nil {
+			return nil, nil, err
+		}
+		svc.NotifyPersonalUpdated(ctx)
+		n := len(u.Seen())
+		svc.logf("reloaded personal checklist for %q: species=%d", u.ID, n)
+		return &sdk.CallToolResult{
+			Content: []sdk.Content{&sdk.TextContent{Text: fmt.Sprintf("Reloaded personal checklist: %d species seen", n)}},
+		}, nil, nil
+	})
+
+	// Register the import_personal tool: ingest a new export with a change report.
+	sdk.AddTool(s, &sdk.Tool{
+		Name:        "import_personal",
+		Description: "Import a new personal eBird export (your checklist file, or a file you put in the users directory): drops duplicate sightings, detects new, edited and deleted checklists, and reports new lifers. The import is saved and replaces your life list; use dryRun to preview.",
+	}, func(ctx context.Context, req *sdk.CallToolRequest, args importArgs) (*sdk.CallToolResult, any, error) {
+		u, err := svc.Users.Resolve(RequestHeader(req), args.User)
+		if err != nil {
+			return nil, nil, err
+		}
+		report, err := u.Import(ctx, args.Path, args.DryRun)
+		if err != nil {
+			return nil, nil, err
+		}
+		summary := report.Summary()
+		if args.DryRun {
+			summary = "Dry run: " + summary
+		} else if report.Changed() {
+			svc.NotifyPersonalUpdated(ctx)
+			svc.logf("imported personal checklist for %q: %s", u.ID, summary)
+		}
+		return &sdk.CallToolResult{
+			Content: []sdk.Content{&sdk.TextContent{Text: summary}},
+		}, report, nil
+	})
+
+	// Register the life_stats tool.
+	sdk.AddTool(s, &sdk.Tool{
+		Name:        "life_stats",
+		Description: "Summarize your life list: species totals by country/state/county, by year, by family and order, cumulative growth and milestone dates.",
+	}, func(ctx context.Context, req *sdk.CallToolRequest, args tools.LifeStatsArgs) (*sdk.CallToolResult, any, error) {
+		u, err := svc.Users.Resolve(RequestHeader(req), args.User)
+		if err != nil {
+			return nil, nil, err
+		}
+		pc, err := u.FirstSightings(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		out, err := tools.BuildLifeStats(ctx, args, pc, svc.Taxonomy)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &sdk.CallToolResult{
+			Content: []sdk.Content{&sdk.TextContent{Text: LifeStatsSummary(out)}},
+		}, out, nil
+	})
+
+	// Register the lifer_timeline tool.
+	sdk.AddTool(s, &sdk.Tool{
+		Name:        "lifer_timeline",
+		Description: "List your lifers in chronological order with location and checklist, optionally by year or region, or show what you saw on this day in past years.",
+	}, func(ctx context.Context, req *sdk.CallToolRequest, args tools.LiferTimelineArgs) (*sdk.CallToolResult, any, error) {
+		u, err := svc.Users.Resolve(RequestHeader(req), args.User)
+		if err != nil {
+			return nil, nil, err
+		}
+		// On this day lists every sighting on the date; the timeline
+		// itself needs only first sightings.
+		load := u.FirstSightings
+		if args.OnThisDay {
+			load = u.Checklist
+		}
+		pc, err := load(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		out, err := tools.BuildLiferTimeline(ctx, args, pc, svc.clock().Now())
+		if err != nil {
+			return nil, nil, err
+		}
+		summary := fmt.Sprintf("%d lifers", len(out.Lifers))
+		if args.OnThisDay {
+			summary = fmt.Sprintf("On %s you birded in %d past years", out.MonthDay, len(out.OnThisDay))
+		}
+		return &sdk.CallToolResult{
+			Content: []sdk.Content{&sdk.TextContent{Text: summary}},
+		}, out, nil
+	})
+
+	// Register the species_info tool.
+	sdk.AddTool(s, &sdk.Tool{
+		Name:        "species_info",
+		Description: "Look up a species by common name, scientific name, banding code or eBird code (typos tolerated) and return its taxonomy and your personal history with it.",
+	}, func(ctx context.Context, req *sdk.CallToolRequest, args tools.SpeciesInfoArgs) (*sdk.CallToolResult, any, error) {
+		u, err := svc.Users.Resolve(RequestHeader(req), args.User)
+		if err != nil {
+			return nil, nil, err
+		}
+		out, err := svc.SpeciesInfo(ctx, u, args)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &sdk.CallToolResult{
+			Content: []sdk.Content{&sdk.TextContent{Text: SpeciesInfoSummary(out)}},
+		}, out, nil
+	})
+}
+
+// TargetSummary is the one-line target_checklist result, e.g.
+// "3 candidate lifers; top: Lewis's Woodpecker".
+func TargetSummary(out tools.TargetResult) string {
+	if len(out.Targets) == 0 {
+		return "WingIt-MCP: no candidate lifers"
+	}
+	return fmt.Sprintf("%d candidate lifers; top: %s", len(out.Targets), out.Targets[0].CommonName)
+}
+
+// LifeStatsSummary is the one-line life_stats result.
+func LifeStatsSummary(out tools.LifeStats) string {
+	if out.Region != "" {
+		return fmt.Sprintf("%d species seen in %s", out.TotalSpecies, out.Region)
+	}
+	return fmt.Sprintf("%d species on your life list", out.TotalSpecies)
+}
+
+// SpeciesInfoSummary is the one-line species_info result.
+func SpeciesInfoSummary(out tools.SpeciesInfo) string {
+	status := "not yet seen"
+	if out.Seen {
+		status = "first seen " + out.History.FirstSeen
+	}
+	return fmt.Sprintf("%s (%s): %s", out.Species.CommonName, out.Species.SciName, status)
+}
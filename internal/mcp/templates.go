@@ -0,0 +1,222 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/kpb/wingit-mcp/internal/ebird"
+	"github.com/kpb/wingit-mcp/internal/taxonomy"
+	it "github.com/kpb/wingit-mcp/internal/types"
+	"github.com/kpb/wingit-mcp/internal/users"
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	SpeciesTemplate   = "wingit://species/{speciesCode}"
+	LocationTemplate  = "wingit://location/{locId}"
+	ChecklistTemplate = "wingit://checklist/{checklistId}"
+)
+
+// maxCompletions is the MCP cap on values in one completion response.
+const maxCompletions = 100
+
+// entityTemplate ties a URI template to its lookup and completion source.
+type entityTemplate struct {
+	template    string
+	variable    string
+	name        string
+	description string
+	// scope narrows the user's data to what lookup needs, so store-backed
+	// users answer from indexes instead of the whole life list.
+	scope  func(ctx context.Context, u *users.User, id string) (*it.PersonalChecklist, error)
+	lookup func(pc *it.PersonalChecklist, id string) (any, bool)
+	// values lists the IDs to complete, from the store's indexes for a
+	// store-backed user.
+	values func(u *users.User, ctx context.Context) ([]string, error)
+}
+
+var entityTemplates = []entityTemplate{
+	{
+		template:    SpeciesTemplate,
+		variable:    "speciesCode",
+		name:        "Species life history",
+		description: "Your sightings of one species: first/last dates, counts and locations.",
+		scope: func(ctx context.Context, u *users.User, id string) (*it.PersonalChecklist, error) {
+			return u.Subset(ctx, ebird.Filter{SpeciesCodes: []string{id}})
+		},
+		lookup: func(pc *it.PersonalChecklist, id string) (any, bool) {
+			return ebird.FindSpeciesHistory(pc, id)
+		},
+		values: (*users.User).SpeciesCodes,
+	},
+	{
+		template:    LocationTemplate,
+		variable:    "locId",
+		name:        "Location history",
+		description: "Your checklists and species tallies at one eBird location.",
+		scope: func(ctx context.Context, u *users.User, id string) (*it.PersonalChecklist, error) {
+			return u.Subset(ctx, ebird.Filter{LocID: id})
+		},
+		lookup: func(pc *it.PersonalChecklist, id string) (any, bool) {
+			return ebird.FindLocationHistory(pc, id)
+		},
+		values: (*users.User).LocationIDs,
+	},
+	{
+		template:    ChecklistTemplate,
+		variable:    "checklistId",
+		name:        "Checklist",
+		description: "The species you recorded on one checklist, including lifers.",
+		scope:       checklistScope,
+		lookup: func(pc *it.PersonalChecklist, id string) (any, bool) {
+			return ebird.FindChecklistHistory(pc, id)
+		},
+		values: (*users.User).ChecklistIDs,
+	},
+}
+
+// checklistScope is the checklist's sightings plus the full history of each
+// species on it, which lifer detection needs.
+func checklistScope(ctx context.Context, u *users.User, id string) (*it.PersonalChecklist, error) {
+	list, err := u.Subset(ctx, ebird.Filter{ChecklistID: id})
+	if err != nil || len(list.Sightings) == 0 {
+		return list, err
+	}
+	codes := make([]string, 0, len(list.Sightings))
+	for _, s := range list.Sightings {
+		codes = append(codes, s.SpeciesCode)
+	}
+	return u.Subset(ctx, ebird.Filter{SpeciesCodes: codes})
+}
+
+// prefix is the URI template up to its single variable.
+func (t entityTemplate) prefix() string {
+	return t.template[:strings.Index(t.template, "{")]
+}
+
+// RegisterResourceTemplates adds the per-species, per-location and
+// per-checklist history templates, scoped to the calling user.
+func RegisterResourceTemplates(s *sdk.Server, reg *users.Registry) {
+	for _, et := range entityTemplates {
+		s.AddResourceTemplate(&sdk.ResourceTemplate{
+			URITemplate: et.template,
+			MIMEType:    "application/json",
+			Name:        et.name,
+			Description: et.description,
+		}, func(ctx context.Context, req *sdk.ReadResourceRequest) (*sdk.ReadResourceResult, error) {
+			uri := req.Params.URI
+			id, err := url.PathUnescape(strings.TrimPrefix(uri, et.prefix()))
+			if err != nil || id == "" {
+				return nil, sdk.ResourceNotFoundError(uri)
+			}
+			u, err := reg.Resolve(RequestHeader(req), "")
+			if err != nil {
+				return nil, err
+			}
+			pc, err := et.scope(ctx, u, id)
+			if err != nil {
+				return nil, err
+			}
+			payload, ok := et.lookup(pc, id)
+			if !ok {
+				return nil, sdk.ResourceNotFoundError(uri)
+			}
+
+			buf, err := json.MarshalIndent(payload, "", "  ")
+			if err != nil {
+				return nil, err
+			}
+			return &sdk.ReadResourceResult{
+				Contents: []*sdk.ResourceContents{
+					{URI: uri, MIMEType: "application/json", Text: string(buf)},
+				},
+			}, nil
+		})
+	}
+}
+
+// CompletionHandler completes the variables of the history resource
+// templates from the calling user's data. Species are also matched by
+// common, scientific or banding name through tax (which may be nil).
+func CompletionHandler(reg *users.Registry, tax *taxonomy.Taxonomy) func(context.Context, *sdk.CompleteRequest) (*sdk.CompleteResult, error) {
+	return func(ctx context.Context, req *sdk.CompleteRequest) (*sdk.CompleteResult, error) {
+		res := &sdk.CompleteResult{Completion: sdk.CompletionResultDetails{Values: []string{}}}
+		ref := req.Params.Ref
+		if ref == nil || ref.Type != "ref/resource" {
+			return res, nil
+		}
+		for _, et := range entityTemplates {
+			if ref.URI != et.template || req.Params.Argument.Name != et.variable {
+				continue
+			}
+			u, err := reg.Resolve(RequestHeader(req), "")
+			if err != nil {
+				return nil, err
+			}
+			values, err := et.values(u, ctx)
+			if err != nil {
+				return nil, err
+			}
+			value := req.Params.Argument.Value
+			if et.template == SpeciesTemplate {
+				names := tax
+				if names == nil && value != "" {
+					if names, err = u.PersonalTaxonomy(ctx); err != nil {
+						return nil, err
+					}
+				}
+				res.Completion = completeValues(completeSpecies(values, names, value), "")
+				continue
+			}
+			res.Completion = completeValues(values, value)
+		}
+		return res, nil
+	}
+}
+
+// completeSpecies returns the user's species codes (mine) matching value by
+// code prefix first, then by name via tax (if not nil), best first.
+func completeSpecies(mine []string, tax *taxonomy.Taxonomy, value string) []string {
+	have := make(map[string]bool, len(mine))
+	var out []string
+	for _, c := range mine {
+		have[c] = true
+		if strings.HasPrefix(c, strings.ToLower(value)) {
+			out = append(out, c)
+		}
+	}
+	if value == "" || tax == nil {
+		return out
+	}
+	added := make(map[string]bool, len(out))
+	for _, c := range out {
+		added[c] = true
+	}
+	for _, m := range tax.Resolve(value, 0) {
+		if code := m.Entry.SpeciesCode; have[code] && !added[code] {
+			added[code] = true
+			out = append(out, code)
+		}
+	}
+	return out
+}
+
+// completeValues returns candidates with the given prefix (case-insensitive).
+func completeValues(candidates []string, prefix string) sdk.CompletionResultDetails {
+	prefix = strings.ToLower(prefix)
+	var matches []string
+	for _, c := range candidates {
+		if strings.HasPrefix(strings.ToLower(c), prefix) {
+			matches = append(matches, c)
+		}
+	}
+	out := sdk.CompletionResultDetails{Values: []string{}, Total: len(matches)}
+	if len(matches) > maxCompletions {
+		matches = matches[:maxCompletions]
+		out.HasMore = true
+	}
+	out.Values = append(out.Values, matches...)
+	return out
+}
@@ -0,0 +1,139 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kpb/wingit-mcp/internal/ebird"
+	"github.com/kpb/wingit-mcp/internal/store"
+	"github.com/kpb/wingit-mcp/internal/tools"
+	"github.com/kpb/wingit-mcp/internal/users"
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// connect serves s over in-memory transports and returns a client session.
+func connect(t *testing.T, s *sdk.Server) *sdk.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+	st, ct := sdk.NewInMemoryTransports()
+	if _, err := s.Connect(ctx, st, nil); err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	cs, err := sdk.NewClient(&sdk.Implementation{Name: "test"}, nil).Connect(ctx, ct, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	t.Cleanup(func() { cs.Close() })
+	return cs
+}
+
+func testRegistry(t *testing.T) *users.Registry {
+	t.Helper()
+	path := filepath.Join("testdata", "personal_checklist_example.json")
+	pc, err := ebird.LoadPersonalChecklist(path)
+	if err != nil {
+		t.Fatalf("LoadPersonalChecklist: %v", err)
+	}
+	return users.NewSingle(users.NewUser(users.DefaultUserID, "Example", path, pc))
+}
+
+func Test_resource_templates_and_completion(t *testing.T) {
+	t.Parallel()
+
+	reg := testRegistry(t)
+	s := sdk.NewServer(&sdk.Implementation{Name: "wingit-test"}, &sdk.ServerOptions{
+		CompletionHandler: CompletionHandler(reg, nil),
+	})
+	RegisterResourceTemplates(s, reg)
+	cs := connect(t, s)
+	ctx := context.Background()
+
+	res, err := cs.ReadResource(ctx, &sdk.ReadResourceParams{URI: "wingit://species/clanut"})
+	if err != nil {
+		t.Fatalf("ReadResource species: %v", err)
+	}
+	var sh ebird.SpeciesHistory
+	if err := json.Unmarshal([]byte(res.Contents[0].Text), &sh); err != nil {
+		t.Fatalf("decode species history: %v", err)
+	}
+	if sh.CommonName != "Clark's Nutcracker" || sh.FirstSeen != "2018-05-01" {
+		t.Fatalf("species history = %+v", sh)
+	}
+
+	if _, err := cs.ReadResource(ctx, &sdk.ReadResourceParams{URI: "wingit://location/L998877"}); err != nil {
+		t.Fatalf("ReadResource location: %v", err)
+	}
+	if _, err := cs.ReadResource(ctx, &sdk.ReadResourceParams{URI: "wingit://checklist/S100000001"}); err != nil {
+		t.Fatalf("ReadResource checklist: %v", err)
+	}
+	if _, err := cs.ReadResource(ctx, &sdk.ReadResourceParams{URI: "wingit://species/nope"}); err == nil {
+		t.Fatalf("expected not found for unknown species")
+	}
+
+	// ClientSession.Complete panics in go-sdk v0.3.0, so call the handler directly.
+	comp, err := CompletionHandler(reg, nil)(ctx, &sdk.CompleteRequest{Params: &sdk.CompleteParams{
+		Ref:      &sdk.CompleteReference{Type: "ref/resource", URI: SpeciesTemplate},
+		Argument: sdk.CompleteParamsArgument{Name: "speciesCode", Value: "CL"},
+	}})
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if want := []string{"clanut"}; !reflect.DeepEqual(comp.Completion.Values, want) {
+		t.Fatalf("completion = %v, want %v", comp.Completion.Values, want)
+	}
+
+	// Names resolve to codes too.
+	comp, err = CompletionHandler(reg, nil)(ctx, &sdk.CompleteRequest{Params: &sdk.CompleteParams{
+		Ref:      &sdk.CompleteReference{Type: "ref/resource", URI: SpeciesTemplate},
+		Argument: sdk.CompleteParamsArgument{Name: "speciesCode", Value: "goldfinch"},
+	}})
+	if err != nil {
+		t.Fatalf("Complete(goldfinch): %v", err)
+	}
+	if want := []string{"amgold"}; !reflect.DeepEqual(comp.Completion.Values, want) {
+		t.Fatalf("completion = %v, want %v", comp.Completion.Values, want)
+	}
+}
+
+func Test_store_backed_user_completes_and_looks_up_species(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	st, err := store.Open(filepath.Join(t.TempDir(), "store.db"))
+	if err != nil {
+		t.Fatalf("store.Open: %v", err)
+	}
+	t.Cleanup(func() { st.Close() })
+	u, err := users.OpenUser(ctx, users.DefaultUserID, "", filepath.Join("testdata", "personal_checklist_example.json"), users.Options{Store: st})
+	if err != nil {
+		t.Fatalf("OpenUser: %v", err)
+	}
+	reg := users.NewSingle(u)
+
+	for variable, want := range map[string][]string{"speciesCode": {"clanut"}, "checklistId": {"S100000001", "S100000002"}} {
+		template := SpeciesTemplate
+		value := "cl"
+		if variable == "checklistId" {
+			template, value = ChecklistTemplate, "S1"
+		}
+		comp, err := CompletionHandler(reg, nil)(ctx, &sdk.CompleteRequest{Params: &sdk.CompleteParams{
+			Ref:      &sdk.CompleteReference{Type: "ref/resource", URI: template},
+			Argument: sdk.CompleteParamsArgument{Name: variable, Value: value},
+		}})
+		if err != nil || !reflect.DeepEqual(comp.Completion.Values, want) {
+			t.Errorf("complete %s = %+v, %v; want %v", variable, comp, err, want)
+		}
+	}
+
+	svc := &Service{Users: reg}
+	info, err := svc.SpeciesInfo(ctx, u, tools.SpeciesInfoArgs{Query: "Clark's Nutcracker"})
+	if err != nil {
+		t.Fatalf("SpeciesInfo: %v", err)
+	}
+	if info.Species.SpeciesCode != "clanut" || !info.Seen || info.History.FirstSeen != "2018-05-01" {
+		t.Errorf("species info = %+v", info)
+	}
+}
@@ -1,11 +1,35 @@
 package prompts
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
+
+// languageNames labels common eBird locales for the model; unknown codes are
+// passed through as-is.
+var languageNames = map[string]string{
+	"de": "German",
+	"en": "English",
+	"es": "Spanish",
+	"fr": "French",
+	"it": "Italian",
+	"ja": "Japanese",
+	"nl": "Dutch",
+	"pt": "Portuguese",
+	"zh": "Chinese",
+}
 
 // BuildFieldChecklistPrompt returns the model-facing text for the
 // "field_checklist" prompt. Tolerates empty inputs by supplying
 // friendly defaults so callers don't have to pre-validate.
 func BuildFieldChecklistPrompt(location, dayRange string) string {
+	return BuildLocalizedFieldChecklistPrompt(location, dayRange, "")
+}
+
+// BuildLocalizedFieldChecklistPrompt is BuildFieldChecklistPrompt with an
+// optional eBird locale (e.g. "es", "fr_CA"). A non-English locale asks for
+// the checklist in that language using the localized names from the tool.
+func BuildLocalizedFieldChecklistPrompt(location, dayRange, locale string) string {
 	if location == "" {
 		location = "this area"
 	}
@@ -20,9 +44,25 @@ Using the tool output provided in this conversation (JSON with "targets" and "fi
 
 - Focus only on likely lifers (the "targets" array).
 - Group species by approximate recent frequency (high / medium / low) based on "recentFrequency".
+- If the output includes "families", keep the species order given and print each family name as a heading instead.
 - For each species, show: common name, scientific name, and a short note like "seen recently at <locName>" if present.
 - Keep it compact, suitable for printing or quick reference in the field.
 - Do not reprint the raw JSON; summarize it.
 
-If there are no targets, explain that there are no likely new lifers for this query and suggest broadening radius or daysBack.`, location, dayRange)
+If there are no targets, explain that there are no likely new lifers for this query and suggest broadening radius or daysBack.`, location, dayRange) + localeInstructions(locale)
+}
+
+func localeInstructions(locale string) string {
+	lang, _, _ := strings.Cut(strings.ReplaceAll(strings.TrimSpace(locale), "-", "_"), "_")
+	lang = strings.ToLower(lang)
+	if lang == "" || lang == "en" {
+		return ""
+	}
+	name, ok := languageNames[lang]
+	if !ok {
+		name = locale
+	}
+	return fmt.Sprintf(`
+
+Write the checklist in %s. Call "target_checklist" with "Locale": %q so "commonName" is already localized; use it as printed, and show the English name ("englishName") and scientific name after it.`, name, locale)
 }
@@ -61,3 +61,15 @@ outer:
 	}
 	return -1
 }
+
+func Test_BuildLocalizedFieldChecklistPrompt_names_language(t *testing.T) {
+	got := BuildLocalizedFieldChecklistPrompt("Bosque del Apache", "last 7 days", "es_MX")
+	if !containsAll(got, "Bosque del Apache", "in Spanish", `"Locale": "es_MX"`, "englishName") {
+		t.Fatalf("localized prompt missing language guidance:\n%s", got)
+	}
+
+	// English (or no locale) leaves the base prompt unchanged.
+	if BuildLocalizedFieldChecklistPrompt("x", "y", "en") != BuildFieldChecklistPrompt("x", "y") {
+		t.Fatalf("English locale should not add instructions")
+	}
+}
@@ -14,14 +14,16 @@ func Register(s *mcp.Server) {
 		Arguments: []*mcp.PromptArgument{
 			{Name: "location", Description: "Name of the birding location or area.", Required: true},
 			{Name: "dayRange", Description: "Time window label (e.g., 'last 7 days').", Required: false},
+			{Name: "locale", Description: "eBird locale for common names (e.g., 'es', 'fr').", Required: false},
 		},
 	}
 
 	handler := func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
 		loc := req.Params.Arguments["location"]
 		day := req.Params.Arguments["dayRange"]
+		locale := req.Params.Arguments["locale"]
 
-		text := BuildFieldChecklistPrompt(loc, day)
+		text := BuildLocalizedFieldChecklistPrompt(loc, day, locale)
 
 		return &mcp.GetPromptResult{
 			Description: prompt.Description,
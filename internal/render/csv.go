@@ -0,0 +1,34 @@
+package render
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+
+	"github.com/kpb/wingit-mcp/internal/tools"
+)
+
+var csvHeader = []string{
+	"species_code", "common_name", "english_name", "sci_name", "family",
+	"recent_frequency", "band", "last_seen_nearby",
+}
+
+// CSV renders a target checklist with one row per target, in result order.
+func CSV(res tools.TargetResult) (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(csvHeader); err != nil {
+		return "", err
+	}
+	for _, t := range res.Targets {
+		rec := []string{
+			t.SpeciesCode, t.CommonName, t.EnglishName, t.SciName, t.Family,
+			strconv.FormatFloat(t.RecentFrequency, 'f', -1, 64), Band(t.RecentFrequency), t.LastSeenNearby,
+		}
+		if err := w.Write(rec); err != nil {
+			return "", err
+		}
+	}
+	w.Flush()
+	return buf.String(), w.Error()
+}
@@ -0,0 +1,92 @@
+package render
+
+import (
+	"encoding/json"
+
+	"github.com/kpb/wingit-mcp/internal/ebird"
+	"github.com/kpb/wingit-mcp/internal/tools"
+	it "github.com/kpb/wingit-mcp/internal/types"
+)
+
+// FormatGeoJSON renders points as an RFC 7946 FeatureCollection.
+const FormatGeoJSON = "geojson"
+
+const geoJSONMIME = "application/geo+json"
+
+type featureCollection struct {
+	Type     string    `json:"type"`
+	Features []feature `json:"features"`
+}
+
+type feature struct {
+	Type       string         `json:"type"`
+	Geometry   point          `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+type point struct {
+	Type string `json:"type"`
+	// Coordinates are [lng, lat] per RFC 7946.
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+func newFeature(lat, lng float64, props map[string]any) feature {
+	return feature{Type: "Feature", Geometry: point{Type: "Point", Coordinates: [2]float64{lng, lat}}, Properties: props}
+}
+
+func marshalFeatures(fs []feature) (string, error) {
+	if fs == nil {
+		fs = []feature{}
+	}
+	b, err := json.MarshalIndent(featureCollection{Type: "FeatureCollection", Features: fs}, "", "  ")
+	return string(b), err
+}
+
+// TargetsGeoJSON renders target hotspots as one point per location, with the
+// lifers reported there as properties.
+func TargetsGeoJSON(hotspots []tools.Hotspot) (string, error) {
+	fs := make([]feature, 0, len(hotspots))
+	for _, h := range hotspots {
+		species := make([]map[string]any, 0, len(h.Species))
+		for _, s := range h.Species {
+			species = append(species, map[string]any{
+				"speciesCode": s.SpeciesCode,
+				"commonName":  s.CommonName,
+				"sciName":     s.SciName,
+				"lastSeen":    s.LastSeen,
+			})
+		}
+		fs = append(fs, newFeature(h.Lat, h.Lng, map[string]any{
+			"locId":        h.LocID,
+			"locName":      h.LocName,
+			"speciesCount": len(h.Species),
+			"species":      species,
+		}))
+	}
+	return marshalFeatures(fs)
+}
+
+// PersonalGeoJSON renders personal sightings as one point per location with
+// the species, checklist count and visit dates there. Sightings without
+// coordinates are skipped.
+func PersonalGeoJSON(sightings []it.PersonalSighting) (string, error) {
+	return LocationsGeoJSON(ebird.SummarizeLocations(sightings))
+}
+
+// LocationsGeoJSON renders location summaries as one point each.
+func LocationsGeoJSON(locs []ebird.LocationSummary) (string, error) {
+	fs := make([]feature, 0, len(locs))
+	for _, l := range locs {
+		fs = append(fs, newFeature(l.Lat, l.Lng, map[string]any{
+			"locId":          l.LocID,
+			"locName":        l.LocName,
+			"countyCode":     l.CountyCode,
+			"speciesCount":   len(l.Species),
+			"species":        l.Species,
+			"checklistCount": l.Checklists,
+			"firstVisit":     l.FirstVisit,
+			"lastVisit":      l.LastVisit,
+		}))
+	}
+	return marshalFeatures(fs)
+}
@@ -0,0 +1,48 @@
+package render
+
+import (
+	"encoding/json"
+	"testing"
+
+	it "github.com/kpb/wingit-mcp/internal/types"
+)
+
+func Test_targets_geojson_points_are_lng_lat(t *testing.T) {
+	doc, ok, err := TargetChecklist("geojson", sampleResult(), sampleHotspots())
+	if !ok || err != nil || doc.MIMEType != "application/geo+json" {
+		t.Fatalf("TargetChecklist(geojson) = %+v, %v, %v", doc, ok, err)
+	}
+	var fc featureCollection
+	if err := json.Unmarshal([]byte(doc.Text), &fc); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if fc.Type != "FeatureCollection" || len(fc.Features) != 1 {
+		t.Fatalf("collection = %+v", fc)
+	}
+	f := fc.Features[0]
+	if f.Geometry.Coordinates != [2]float64{-105.8367, 35.7312} || f.Properties["speciesCount"] != float64(1) {
+		t.Fatalf("feature = %+v", f)
+	}
+}
+
+func Test_personal_geojson_aggregates_by_location(t *testing.T) {
+	got, err := PersonalGeoJSON([]it.PersonalSighting{
+		{SpeciesCode: "clanut", LocID: "L1", LocName: "Aspen Vista", Lat: 35.76, Lng: -105.80, ObsDt: "2025-09-12", ChecklistID: "S1"},
+		{SpeciesCode: "amgold", LocID: "L1", LocName: "Aspen Vista", Lat: 35.76, Lng: -105.80, ObsDt: "2024-06-10", ChecklistID: "S2"},
+		{SpeciesCode: "amgold", LocID: "L2", LocName: "Nowhere"},
+	})
+	if err != nil {
+		t.Fatalf("PersonalGeoJSON: %v", err)
+	}
+	var fc featureCollection
+	if err := json.Unmarshal([]byte(got), &fc); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(fc.Features) != 1 {
+		t.Fatalf("features = %d, want 1 (no-coordinate location skipped)", len(fc.Features))
+	}
+	p := fc.Features[0].Properties
+	if p["speciesCount"] != float64(2) || p["checklistCount"] != float64(2) || p["firstVisit"] != "2024-06-10" || p["lastVisit"] != "2025-09-12" {
+		t.Fatalf("properties = %+v", p)
+	}
+}
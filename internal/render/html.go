@@ -0,0 +1,62 @@
+package render
+
+import (
+	"bytes"
+	"html/template"
+
+	"github.com/kpb/wingit-mcp/internal/tools"
+)
+
+// htmlPage is self-contained (inline CSS, no scripts or remote assets) so it
+// can be saved and printed offline.
+var htmlPage = template.Must(template.New("checklist").Funcs(template.FuncMap{"band": Band}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: Georgia, serif; margin: 1.5cm; color: #111; }
+h1 { font-size: 1.4em; margin-bottom: 0.2em; }
+h2 { font-size: 1.1em; border-bottom: 1px solid #999; margin-top: 1.2em; }
+p.filters { font-style: italic; color: #444; }
+ul { list-style: none; padding: 0; }
+li { padding: 0.2em 0; break-inside: avoid; }
+li input { margin-right: 0.5em; }
+.sci { font-style: italic; color: #444; }
+.band { font-size: 0.8em; padding: 0 0.4em; border: 1px solid #999; border-radius: 0.3em; }
+.band-high { background: #d9f2d9; }
+.band-medium { background: #fff3cc; }
+.band-low { background: #eee; }
+.seen { font-size: 0.85em; color: #666; }
+@media print { body { margin: 1cm; } input { -webkit-print-color-adjust: exact; } }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="filters">{{.Filters}}</p>
+{{- if not .Sections}}
+<p>No likely new lifers for this query. Try a larger radius or more days back.</p>
+{{- end}}
+{{- range .Sections}}
+<h2>{{.Title}}</h2>
+<ul>
+{{- range .Targets}}
+<li><label><input type="checkbox"> <strong>{{.CommonName}}</strong>{{if .EnglishName}} ({{.EnglishName}}){{end}}{{if .SciName}} <span class="sci">{{.SciName}}</span>{{end}}</label>
+ <span class="band band-{{band .RecentFrequency}}">{{band .RecentFrequency}}</span>{{if .LastSeenNearby}} <span class="seen">last seen {{.LastSeenNearby}}</span>{{end}}</li>
+{{- end}}
+</ul>
+{{- end}}
+</body>
+</html>
+`))
+
+// HTML renders a target checklist as a printable HTML page with checkboxes.
+func HTML(res tools.TargetResult) (string, error) {
+	var buf bytes.Buffer
+	err := htmlPage.Execute(&buf, struct {
+		Title    string
+		Filters  string
+		Sections []section
+	}{title(res), filtersLine(res), sections(res)})
+	return buf.String(), err
+}
@@ -0,0 +1,117 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/kpb/wingit-mcp/internal/tools"
+)
+
+// Map export formats accepted by Hotspots.
+const (
+	FormatGPX = "gpx"
+	FormatKML = "kml"
+)
+
+// Hotspots renders target hotspots as GPX waypoints, KML placemarks or
+// GeoJSON points, each described by the lifers recently reported there.
+func Hotspots(format, name string, hotspots []tools.Hotspot) (Document, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", FormatGPX:
+		text, err := GPX(name, hotspots)
+		return Document{Format: FormatGPX, MIMEType: "application/gpx+xml", Name: "target_hotspots.gpx", Text: text}, err
+	case FormatKML:
+		text, err := KML(name, hotspots)
+		return Document{Format: FormatKML, MIMEType: "application/vnd.google-earth.kml+xml", Name: "target_hotspots.kml", Text: text}, err
+	case FormatGeoJSON:
+		text, err := TargetsGeoJSON(hotspots)
+		return Document{Format: FormatGeoJSON, MIMEType: geoJSONMIME, Name: "target_hotspots.geojson", Text: text}, err
+	default:
+		return Document{}, fmt.Errorf("unknown map format %q (want gpx, kml or geojson)", format)
+	}
+}
+
+// hotspotDescription lists the lifers at h, one per line.
+func hotspotDescription(h tools.Hotspot) string {
+	lines := make([]string, 0, len(h.Species))
+	for _, s := range h.Species {
+		line := s.CommonName
+		if s.SciName != "" {
+			line += " (" + s.SciName + ")"
+		}
+		if s.LastSeen != "" {
+			line += ", last seen " + s.LastSeen
+		}
+		lines = append(lines, line)
+	}
+	return fmt.Sprintf("Possible lifers (%d):\n%s", len(h.Species), strings.Join(lines, "\n"))
+}
+
+type gpxDoc struct {
+	XMLName   xml.Name      `xml:"gpx"`
+	Version   string        `xml:"version,attr"`
+	Creator   string        `xml:"creator,attr"`
+	Xmlns     string        `xml:"xmlns,attr"`
+	Name      string        `xml:"metadata>name"`
+	Waypoints []gpxWaypoint `xml:"wpt"`
+}
+
+type gpxWaypoint struct {
+	Lat  float64 `xml:"lat,attr"`
+	Lon  float64 `xml:"lon,attr"`
+	Name string  `xml:"name"`
+	Desc string  `xml:"desc"`
+	Sym  string  `xml:"sym"`
+}
+
+// GPX renders hotspots as GPX 1.1 waypoints.
+func GPX(name string, hotspots []tools.Hotspot) (string, error) {
+	doc := gpxDoc{Version: "1.1", Creator: "wingit-mcp", Xmlns: "http://www.topografix.com/GPX/1/1", Name: name}
+	for _, h := range hotspots {
+		doc.Waypoints = append(doc.Waypoints, gpxWaypoint{
+			Lat: h.Lat, Lon: h.Lng, Name: h.LocName, Desc: hotspotDescription(h), Sym: "Flag, Green",
+		})
+	}
+	return marshalXML(doc)
+}
+
+type kmlDoc struct {
+	XMLName xml.Name  `xml:"kml"`
+	Xmlns   string    `xml:"xmlns,attr"`
+	Name    string    `xml:"Document>name"`
+	Marks   []kmlMark `xml:"Document>Placemark"`
+}
+
+type kmlMark struct {
+	Name        string `xml:"name"`
+	Description string `xml:"description"`
+	Coordinates string `xml:"Point>coordinates"`
+}
+
+// KML renders hotspots as KML 2.2 placemarks.
+func KML(name string, hotspots []tools.Hotspot) (string, error) {
+	doc := kmlDoc{Xmlns: "http://www.opengis.net/kml/2.2", Name: name}
+	for _, h := range hotspots {
+		doc.Marks = append(doc.Marks, kmlMark{
+			Name:        h.LocName,
+			Description: hotspotDescription(h),
+			// KML coordinates are lon,lat[,alt].
+			Coordinates: fmt.Sprintf("%g,%g", h.Lng, h.Lat),
+		})
+	}
+	return marshalXML(doc)
+}
+
+func marshalXML(v any) (string, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	buf.WriteByte('\n')
+	return buf.String(), nil
+}
@@ -0,0 +1,54 @@
+package render
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/kpb/wingit-mcp/internal/tools"
+)
+
+func sampleHotspots() []tools.Hotspot {
+	return []tools.Hotspot{{
+		LocID: "L123456", LocName: "Hyde Park Rd & Co.", Lat: 35.7312, Lng: -105.8367,
+		Species: []tools.HotspotSpecies{{SpeciesCode: "lewo", CommonName: "Lewis's Woodpecker", SciName: "Melanerpes lewis", LastSeen: "2025-10-06"}},
+	}}
+}
+
+func Test_gpx_waypoints_describe_lifers(t *testing.T) {
+	doc, err := Hotspots("gpx", "Santa Fe targets", sampleHotspots())
+	if err != nil {
+		t.Fatalf("Hotspots(gpx): %v", err)
+	}
+	if doc.MIMEType != "application/gpx+xml" || doc.Name != "target_hotspots.gpx" {
+		t.Fatalf("doc = %+v", doc)
+	}
+	var parsed gpxDoc
+	if err := xml.Unmarshal([]byte(doc.Text), &parsed); err != nil {
+		t.Fatalf("gpx not well-formed: %v\n%s", err, doc.Text)
+	}
+	w := parsed.Waypoints
+	if len(w) != 1 || w[0].Lat != 35.7312 || w[0].Lon != -105.8367 || w[0].Name != "Hyde Park Rd & Co." {
+		t.Fatalf("waypoints = %+v", w)
+	}
+	if !strings.Contains(w[0].Desc, "Lewis's Woodpecker (Melanerpes lewis), last seen 2025-10-06") {
+		t.Fatalf("desc = %q", w[0].Desc)
+	}
+}
+
+func Test_kml_uses_lon_lat_order(t *testing.T) {
+	doc, err := Hotspots("KML", "Santa Fe targets", sampleHotspots())
+	if err != nil {
+		t.Fatalf("Hotspots(kml): %v", err)
+	}
+	var parsed kmlDoc
+	if err := xml.Unmarshal([]byte(doc.Text), &parsed); err != nil {
+		t.Fatalf("kml not well-formed: %v", err)
+	}
+	if len(parsed.Marks) != 1 || parsed.Marks[0].Coordinates != "-105.8367,35.7312" {
+		t.Fatalf("placemarks = %+v", parsed.Marks)
+	}
+	if _, err := Hotspots("shp", "x", nil); err == nil {
+		t.Fatalf("expected error for unknown map format")
+	}
+}
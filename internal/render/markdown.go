@@ -0,0 +1,42 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kpb/wingit-mcp/internal/tools"
+)
+
+// Markdown renders a target checklist as a Markdown task list.
+func Markdown(res tools.TargetResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", title(res))
+	fmt.Fprintf(&b, "_%s_\n", filtersLine(res))
+
+	if len(res.Targets) == 0 {
+		b.WriteString("\nNo likely new lifers for this query. Try a larger radius or more days back.\n")
+		return b.String()
+	}
+	for _, s := range sections(res) {
+		fmt.Fprintf(&b, "\n## %s\n\n", s.Title)
+		for _, t := range s.Targets {
+			fmt.Fprintf(&b, "- [ ] **%s**", mdEscape(t.CommonName))
+			if t.EnglishName != "" {
+				fmt.Fprintf(&b, " (%s)", mdEscape(t.EnglishName))
+			}
+			if t.SciName != "" {
+				fmt.Fprintf(&b, " _%s_", mdEscape(t.SciName))
+			}
+			fmt.Fprintf(&b, " · %s", Band(t.RecentFrequency))
+			if t.LastSeenNearby != "" {
+				fmt.Fprintf(&b, " · last seen %s", t.LastSeenNearby)
+			}
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+var mdEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `_`, `\_`, "`", "\\`", `[`, `\[`, `]`, `\]`)
+
+func mdEscape(s string) string { return mdEscaper.Replace(s) }
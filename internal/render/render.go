@@ -0,0 +1,145 @@
+// Package render turns engine results into deterministic, host-independent
+// documents (Markdown, CSV, printable HTML) so formatting does not depend on
+// the LLM.
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kpb/wingit-mcp/internal/tools"
+)
+
+// Formats accepted by Render.
+const (
+	FormatJSON     = "json"
+	FormatMarkdown = "md"
+	FormatCSV      = "csv"
+	FormatHTML     = "html"
+)
+
+// Frequency bands used to group targets.
+const (
+	BandHigh   = "high"
+	BandMedium = "medium"
+	BandLow    = "low"
+)
+
+// Bands lists the frequency bands from most to least likely.
+var Bands = []string{BandHigh, BandMedium, BandLow}
+
+// Band maps a recent frequency (0..1) to high / medium / low.
+func Band(freq float64) string {
+	switch {
+	case freq >= 0.5:
+		return BandHigh
+	case freq >= 0.2:
+		return BandMedium
+	default:
+		return BandLow
+	}
+}
+
+// Document is a rendered result.
+type Document struct {
+	Format   string
+	MIMEType string
+	// Name is a suggested file name, e.g. "target_checklist.csv".
+	Name string
+	Text string
+}
+
+// NormalizeFormat maps aliases ("markdown", "htm", "") to a Format constant.
+func NormalizeFormat(format string) (string, error) {
+	switch f := strings.ToLower(strings.TrimSpace(format)); f {
+	case "", FormatJSON:
+		return FormatJSON, nil
+	case FormatMarkdown, "markdown":
+		return FormatMarkdown, nil
+	case FormatCSV:
+		return FormatCSV, nil
+	case FormatHTML, "htm":
+		return FormatHTML, nil
+	case FormatGeoJSON:
+		return FormatGeoJSON, nil
+	default:
+		return "", fmt.Errorf("unknown format %q (want json, md, csv, html or geojson)", format)
+	}
+}
+
+// TargetChecklist renders res in format. JSON yields no document (ok=false):
+// the structured tool output already carries it. hotspots (see
+// tools.TargetHotspots) are only used by GeoJSON.
+func TargetChecklist(format string, res tools.TargetResult, hotspots []tools.Hotspot) (doc Document, ok bool, err error) {
+	f, err := NormalizeFormat(format)
+	if err != nil {
+		return Document{}, false, err
+	}
+	const base = "target_checklist"
+	switch f {
+	case FormatMarkdown:
+		return Document{Format: f, MIMEType: "text/markdown", Name: base + ".md", Text: Markdown(res)}, true, nil
+	case FormatCSV:
+		text, err := CSV(res)
+		return Document{Format: f, MIMEType: "text/csv", Name: base + ".csv", Text: text}, err == nil, err
+	case FormatHTML:
+		text, err := HTML(res)
+		return Document{Format: f, MIMEType: "text/html", Name: base + ".html", Text: text}, err == nil, err
+	case FormatGeoJSON:
+		text, err := TargetsGeoJSON(hotspots)
+		return Document{Format: f, MIMEType: geoJSONMIME, Name: base + ".geojson", Text: text}, err == nil, err
+	}
+	return Document{}, false, nil
+}
+
+// section is a heading over a run of targets, shared by the renderers.
+type section struct {
+	Title   string
+	Targets []tools.TargetRow
+}
+
+// sections groups targets under family headings when the result has them
+// (taxonomic orders), otherwise under frequency bands.
+func sections(res tools.TargetResult) []section {
+	if len(res.Families) > 0 {
+		byCode := make(map[string]tools.TargetRow, len(res.Targets))
+		for _, t := range res.Targets {
+			byCode[t.SpeciesCode] = t
+		}
+		out := make([]section, 0, len(res.Families))
+		for _, f := range res.Families {
+			s := section{Title: f.FamilyComName}
+			if f.FamilySciName != "" {
+				s.Title += " (" + f.FamilySciName + ")"
+			}
+			for _, code := range f.Species {
+				s.Targets = append(s.Targets, byCode[code])
+			}
+			out = append(out, s)
+		}
+		return out
+	}
+
+	byBand := map[string][]tools.TargetRow{}
+	for _, t := range res.Targets {
+		b := Band(t.RecentFrequency)
+		byBand[b] = append(byBand[b], t)
+	}
+	var out []section
+	for _, b := range Bands {
+		if len(byBand[b]) > 0 {
+			out = append(out, section{Title: strings.ToUpper(b[:1]) + b[1:] + " frequency", Targets: byBand[b]})
+		}
+	}
+	return out
+}
+
+func title(res tools.TargetResult) string {
+	return "Target checklist: " + res.Filters.Location
+}
+
+func filtersLine(res tools.TargetResult) string {
+	f := res.Filters
+	return fmt.Sprintf("Within %g km, last %d days, %d likely lifers (%d already seen)",
+		f.RadiusKm, f.DaysBack, len(res.Targets), res.ExcludedBecauseAlreadySeen)
+}
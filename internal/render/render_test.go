@@ -0,0 +1,81 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kpb/wingit-mcp/internal/tools"
+)
+
+func sampleResult() tools.TargetResult {
+	var res tools.TargetResult
+	res.Filters.Location = "Santa Fe, NM"
+	res.Filters.RadiusKm = 20
+	res.Filters.DaysBack = 7
+	res.ExcludedBecauseAlreadySeen = 1
+	res.Targets = []tools.TargetRow{
+		{SpeciesCode: "lewo", CommonName: "Lewis's Woodpecker", SciName: "Melanerpes lewis", RecentFrequency: 0.6, LastSeenNearby: "2025-10-06"},
+		{SpeciesCode: "pinsis", CommonName: "Pine Siskin", SciName: "Spinus pinus", RecentFrequency: 0.2, LastSeenNearby: "2025-10-04"},
+		{SpeciesCode: "odd", CommonName: `Bird, "with" <tags>`, RecentFrequency: 0.05},
+	}
+	return res
+}
+
+func Test_markdown_groups_by_band_with_checkboxes(t *testing.T) {
+	got := Markdown(sampleResult())
+	for _, want := range []string{
+		"# Target checklist: Santa Fe, NM",
+		"## High frequency\n\n- [ ] **Lewis's Woodpecker** _Melanerpes lewis_ · high · last seen 2025-10-06",
+		"## Medium frequency",
+		"## Low frequency",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("markdown missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func Test_csv_quotes_fields(t *testing.T) {
+	got, err := CSV(sampleResult())
+	if err != nil {
+		t.Fatalf("CSV: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(got), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "species_code,common_name") {
+		t.Fatalf("csv = %q", got)
+	}
+	if lines[1] != "lewo,Lewis's Woodpecker,,Melanerpes lewis,,0.6,high,2025-10-06" {
+		t.Fatalf("csv row = %q", lines[1])
+	}
+	if !strings.Contains(lines[3], `"Bird, ""with"" <tags>"`) {
+		t.Fatalf("csv quoting = %q", lines[3])
+	}
+}
+
+func Test_html_is_escaped_and_printable(t *testing.T) {
+	got, err := HTML(sampleResult())
+	if err != nil {
+		t.Fatalf("HTML: %v", err)
+	}
+	for _, want := range []string{`<input type="checkbox">`, "@media print", `band-high`, "&lt;tags&gt;"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("html missing %q", want)
+		}
+	}
+	if strings.Contains(got, "<tags>") {
+		t.Fatalf("html not escaped")
+	}
+}
+
+func Test_target_checklist_formats(t *testing.T) {
+	if _, ok, err := TargetChecklist("", sampleResult(), nil); ok || err != nil {
+		t.Fatalf("json format should yield no document, got ok=%v err=%v", ok, err)
+	}
+	doc, ok, err := TargetChecklist("markdown", sampleResult(), nil)
+	if !ok || err != nil || doc.MIMEType != "text/markdown" || doc.Name != "target_checklist.md" {
+		t.Fatalf("markdown doc = %+v, %v, %v", doc, ok, err)
+	}
+	if _, _, err := TargetChecklist("pdf", sampleResult(), nil); err == nil {
+		t.Fatalf("expected error for unknown format")
+	}
+}
@@ -0,0 +1,173 @@
+// Package state persists small JSON documents between server runs, such as
+// the last target results of each saved query. Each bucket is one JSON file
+// in the state directory, rewritten atomically on every save.
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Snapshot is one saved value of a key.
+type Snapshot struct {
+	SavedAt time.Time       `json:"savedAt"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// Store is a directory of buckets. A nil *Store saves nothing and finds
+// nothing, so callers need not check whether state is configured.
+type Store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// Open returns a store in dir, creating the directory if needed.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create state dir: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Dir is the directory holding the store's buckets.
+func (s *Store) Dir() string {
+	if s == nil {
+		return ""
+	}
+	return s.dir
+}
+
+// Save appends v as the newest snapshot of key in bucket, keeping at most
+// keep snapshots per key (all of them when keep <= 0).
+func (s *Store) Save(bucket, key string, at time.Time, v any, keep int) error {
+	if s == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encode %s/%s: %w", bucket, key, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := s.read(bucket)
+	if err != nil {
+		return err
+	}
+	snaps := append(b[key], Snapshot{SavedAt: at.UTC(), Data: data})
+	sort.SliceStable(snaps, func(i, j int) bool { return snaps[i].SavedAt.After(snaps[j].SavedAt) })
+	if keep > 0 && len(snaps) > keep {
+		snaps = snaps[:keep]
+	}
+	b[key] = snaps
+	return s.write(bucket, b)
+}
+
+// Snapshots returns the snapshots of key in bucket, newest first.
+func (s *Store) Snapshots(bucket, key string) ([]Snapshot, error) {
+	if s == nil {
+		return nil, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := s.read(bucket)
+	if err != nil {
+		return nil, err
+	}
+	return b[key], nil
+}
+
+// Keys lists the keys in bucket, sorted.
+func (s *Store) Keys(bucket string) ([]string, error) {
+	if s == nil {
+		return nil, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := s.read(bucket)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(b))
+	for k := range b {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Delete removes every snapshot of key in bucket.
+func (s *Store) Delete(bucket, key string) error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := s.read(bucket)
+	if err != nil {
+		return err
+	}
+	if _, ok := b[key]; !ok {
+		return nil
+	}
+	delete(b, key)
+	return s.write(bucket, b)
+}
+
+func (s *Store) path(bucket string) string {
+	return filepath.Join(s.dir, bucket+".json")
+}
+
+func (s *Store) read(bucket string) (map[string][]Snapshot, error) {
+	b := map[string][]Snapshot{}
+	raw, err := os.ReadFile(s.path(bucket))
+	if errors.Is(err, os.ErrNotExist) {
+		return b, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read state %s: %w", bucket, err)
+	}
+	if err := json.Unmarshal(raw, &b); err != nil {
+		return nil, fmt.Errorf("decode state %s: %w", bucket, err)
+	}
+	return b, nil
+}
+
+func (s *Store) write(bucket string, b map[string][]Snapshot) error {
+	raw, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode state %s: %w", bucket, err)
+	}
+	if err := WriteFile(s.path(bucket), raw); err != nil {
+		return fmt.Errorf("write state %s: %w", bucket, err)
+	}
+	return nil
+}
+
+// WriteFile replaces path with data by writing a temporary file in the same
+// directory and renaming it, so a crash never leaves the file torn.
+func WriteFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_save_keeps_newest_snapshots_first(t *testing.T) {
+	t.Parallel()
+
+	st, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	day := time.Date(2025, 10, 20, 7, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		if err := st.Save("targets", "q", day.AddDate(0, 0, i), i, 3); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+	snaps, err := st.Snapshots("targets", "q")
+	if err != nil {
+		t.Fatalf("Snapshots: %v", err)
+	}
+	if len(snaps) != 3 {
+		t.Fatalf("expected 3 kept snapshots, got %d", len(snaps))
+	}
+	if string(snaps[0].Data) != "3" || string(snaps[2].Data) != "1" {
+		t.Errorf("expected newest first, got %s..%s", snaps[0].Data, snaps[2].Data)
+	}
+
+	// A reopened store sees the same data.
+	again, err := Open(st.Dir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if keys, _ := again.Keys("targets"); len(keys) != 1 || keys[0] != "q" {
+		t.Errorf("unexpected keys after reopen: %v", keys)
+	}
+	if err := again.Delete("targets", "q"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if snaps, _ := st.Snapshots("targets", "q"); len(snaps) != 0 {
+		t.Errorf("expected no snapshots after Delete, got %d", len(snaps))
+	}
+}
+
+func Test_nil_store_is_a_no_op(t *testing.T) {
+	t.Parallel()
+
+	var st *Store
+	if err := st.Save("targets", "q", time.Now(), 1, 0); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if snaps, err := st.Snapshots("targets", "q"); err != nil || snaps != nil {
+		t.Errorf("expected nothing from a nil store, got %v, %v", snaps, err)
+	}
+}
@@ -0,0 +1,650 @@
+// Package store keeps users' personal observations in an embedded SQLite
+// database, so large life lists are imported once and then queried through
+// indexes instead of being decoded from JSON on every start.
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // pure-Go driver, registers "sqlite"
+
+	"github.com/kpb/wingit-mcp/internal/ebird"
+	"github.com/kpb/wingit-mcp/internal/taxonomy"
+	it "github.com/kpb/wingit-mcp/internal/types"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS users (
+	user_id              TEXT PRIMARY KEY,
+	owner                TEXT NOT NULL DEFAULT '',
+	source               TEXT NOT NULL DEFAULT '',
+	generated_at         TEXT NOT NULL DEFAULT '',
+	total_observations   INTEGER NOT NULL DEFAULT 0,
+	total_species        INTEGER NOT NULL DEFAULT 0,
+	first_checklist_date TEXT NOT NULL DEFAULT ''
+);
+CREATE TABLE IF NOT EXISTS sightings (
+	user_id      TEXT NOT NULL,
+	sighting_key TEXT NOT NULL,
+	row_hash     TEXT NOT NULL,
+	seq          INTEGER NOT NULL,
+	species_code TEXT NOT NULL,
+	common_name  TEXT NOT NULL DEFAULT '',
+	sci_name     TEXT NOT NULL DEFAULT '',
+	obs_dt       TEXT NOT NULL DEFAULT '',
+	obs_date     TEXT NOT NULL DEFAULT '',
+	loc_name     TEXT NOT NULL DEFAULT '',
+	loc_id       TEXT NOT NULL DEFAULT '',
+	county_code  TEXT NOT NULL DEFAULT '',
+	lat          REAL NOT NULL DEFAULT 0,
+	lng          REAL NOT NULL DEFAULT 0,
+	count        INTEGER NOT NULL DEFAULT 0,
+	obs_valid    INTEGER NOT NULL DEFAULT 0,
+	obs_reviewed INTEGER NOT NULL DEFAULT 0,
+	media        INTEGER NOT NULL DEFAULT 0,
+	heard_only   INTEGER NOT NULL DEFAULT 0,
+	checklist_id TEXT NOT NULL DEFAULT '',
+	source       TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY (user_id, sighting_key)
+);
+CREATE INDEX IF NOT EXISTS sightings_species   ON sightings (user_id, species_code);
+CREATE INDEX IF NOT EXISTS sightings_date      ON sightings (user_id, obs_date);
+CREATE INDEX IF NOT EXISTS sightings_location  ON sightings (user_id, loc_id);
+CREATE INDEX IF NOT EXISTS sightings_county    ON sightings (user_id, county_code);
+CREATE INDEX IF NOT EXISTS sightings_checklist ON sightings (user_id, checklist_id);
+CREATE TABLE IF NOT EXISTS species_index (
+	user_id          TEXT NOT NULL,
+	species_code     TEXT NOT NULL,
+	seq              INTEGER NOT NULL,
+	common_name      TEXT NOT NULL DEFAULT '',
+	sci_name         TEXT NOT NULL DEFAULT '',
+	first_seen       TEXT NOT NULL DEFAULT '',
+	last_seen        TEXT NOT NULL DEFAULT '',
+	total_checklists INTEGER NOT NULL DEFAULT 0,
+	total_count      INTEGER NOT NULL DEFAULT 0,
+	locations        TEXT NOT NULL DEFAULT '[]',
+	PRIMARY KEY (user_id, species_code)
+);
+CREATE TABLE IF NOT EXISTS imports (
+	user_id     TEXT NOT NULL,
+	source      TEXT NOT NULL,
+	fingerprint TEXT NOT NULL,
+	imported_at TEXT NOT NULL,
+	PRIMARY KEY (user_id, source)
+);
+`
+
+const sightingColumns = `species_code, common_name, sci_name, obs_dt, loc_name, loc_id, county_code,
+	lat, lng, count, obs_valid, obs_reviewed, media, heard_only, checklist_id, source`
+
+// migrations bring databases created by older versions up to the schema.
+// Each runs once; "duplicate column" errors mean it already has.
+var migrations = []string{
+	`ALTER TABLE sightings ADD COLUMN source TEXT NOT NULL DEFAULT ''`,
+}
+
+// ErrNoUser is returned when the store holds no data for a user.
+var ErrNoUser = errors.New("no personal data imported for user")
+
+// Store is a SQLite database of personal observations for any number of users.
+type Store struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// ImportStats counts what one import changed, by sighting row.
+type ImportStats struct {
+	// Skipped is true when the source was unchanged since the last import.
+	Skipped   bool `json:"skipped,omitempty"`
+	Sightings int  `json:"sightings"`
+	Inserted  int  `json:"inserted"`
+	Updated   int  `json:"updated"`
+	Deleted   int  `json:"deleted"`
+	Unchanged int  `json:"unchanged"`
+}
+
+// Open opens (creating if needed) the database at path and applies the schema.
+func Open(path string) (*Store, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("create store dir: %w", err)
+		}
+	}
+	db, err := sql.Open("sqlite", path+"?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("open store: %w", err)
+	}
+	// One writer at a time; SQLite serializes writes anyway.
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("apply store schema: %w", err)
+	}
+	for _, m := range migrations {
+		if _, err := db.Exec(m); err != nil && !strings.Contains(err.Error(), "duplicate column") {
+			db.Close()
+			return nil, fmt.Errorf("migrate store: %w", err)
+		}
+	}
+	return &Store{db: db, now: time.Now}, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error { return s.db.Close() }
+
+// ImportFile imports the personal checklist at path for userID: an eBird
+// JSON export or any source ebird.LoadPersonal accepts, with tax resolving
+// names to codes. Nothing is decoded when the contents of every file involved
+// match the last import from the same path. Checklists with validation
+// errors are rejected.
+func (s *Store) ImportFile(ctx context.Context, userID, path string, tax *taxonomy.Taxonomy) (ImportStats, error) {
+	files, err := ebird.PersonalSourceFiles(path)
+	if err != nil {
+		return ImportStats{}, err
+	}
+	h := sha256.New()
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return ImportStats{}, fmt.Errorf("read personal checklist: %w", err)
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", f, len(b))
+		h.Write(b)
+	}
+	fingerprint := hex.EncodeToString(h.Sum(nil))
+
+	var last string
+	err = s.db.QueryRowContext(ctx, `SELECT fingerprint FROM imports WHERE user_id = ? AND source = ?`, userID, path).Scan(&last)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return ImportStats{}, fmt.Errorf("read import record: %w", err)
+	}
+	if last == fingerprint {
+		return ImportStats{Skipped: true}, nil
+	}
+
+	pc, err := ebird.LoadPersonal(path, tax)
+	if err != nil {
+		return ImportStats{}, err
+	}
+	if err := ebird.ValidatePersonalChecklist(pc).Err(); err != nil {
+		return ImportStats{}, err
+	}
+	// An empty file starts a new user but never wipes a stored life list.
+	if len(pc.Sightings) == 0 && len(pc.SpeciesIndex) == 0 {
+		seen, err := s.SeenSet(ctx, userID)
+		if err != nil {
+			return ImportStats{}, err
+		}
+		if len(seen) > 0 {
+			return ImportStats{}, fmt.Errorf("no sightings or species index; keeping the stored life list")
+		}
+	}
+	stats, err := s.Import(ctx, userID, pc)
+	if err != nil {
+		return stats, err
+	}
+	_, err = s.db.ExecContext(ctx, `INSERT INTO imports (user_id, source, fingerprint, imported_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (user_id, source) DO UPDATE SET fingerprint = excluded.fingerprint, imported_at = excluded.imported_at`,
+		userID, path, fingerprint, s.now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return stats, fmt.Errorf("record import: %w", err)
+	}
+	return stats, nil
+}
+
+// Import replaces userID's data with pc, writing only what changed: new
+// sightings are inserted, edited ones updated and ones no longer present
+// deleted. Sightings are keyed by checklist and species, so duplicates in pc
+// collapse to one row.
+func (s *Store) Import(ctx context.Context, userID string, pc *it.PersonalChecklist) (ImportStats, error) {
+	var stats ImportStats
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return stats, fmt.Errorf("begin import: %w", err)
+	}
+	defer tx.Rollback()
+
+	existing := map[string]string{}
+	rows, err := tx.QueryContext(ctx, `SELECT sighting_key, row_hash FROM sightings WHERE user_id = ?`, userID)
+	if err != nil {
+		return stats, fmt.Errorf("read existing sightings: %w", err)
+	}
+	for rows.Next() {
+		var key, hash string
+		if err := rows.Scan(&key, &hash); err != nil {
+			rows.Close()
+			return stats, fmt.Errorf("read existing sightings: %w", err)
+		}
+		existing[key] = hash
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return stats, fmt.Errorf("read existing sightings: %w", err)
+	}
+
+	upsert, err := tx.PrepareContext(ctx, `INSERT INTO sightings (user_id, sighting_key, row_hash, seq, obs_date, `+sightingColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id, sighting_key) DO UPDATE SET
+			row_hash = excluded.row_hash, seq = excluded.seq, species_code = excluded.species_code,
+			common_name = excluded.common_name, sci_name = excluded.sci_name, obs_dt = excluded.obs_dt,
+			obs_date = excluded.obs_date, loc_name = excluded.loc_name, loc_id = excluded.loc_id,
+			county_code = excluded.county_code, lat = excluded.lat, lng = excluded.lng, count = excluded.count,
+			obs_valid = excluded.obs_valid, obs_reviewed = excluded.obs_reviewed, media = excluded.media,
+			heard_only = excluded.heard_only, checklist_id = excluded.checklist_id, source = excluded.source`)
+	if err != nil {
+		return stats, fmt.Errorf("prepare sighting upsert: %w", err)
+	}
+	defer upsert.Close()
+	reseq, err := tx.PrepareContext(ctx, `UPDATE sightings SET seq = ? WHERE user_id = ? AND sighting_key = ? AND seq != ?`)
+	if err != nil {
+		return stats, fmt.Errorf("prepare sighting reorder: %w", err)
+	}
+	defer reseq.Close()
+
+	incoming := make(map[string]bool, len(pc.Sightings))
+	for i, sg := range pc.Sightings {
+		key := ebird.SightingKey(sg)
+		if sg.SpeciesCode == "" || incoming[key] {
+			continue
+		}
+		incoming[key] = true
+		stats.Sightings++
+		hash := rowHash(sg)
+		prev, ok := existing[key]
+		switch {
+		case !ok:
+			stats.Inserted++
+		case prev != hash:
+			stats.Updated++
+		default:
+			stats.Unchanged++
+			if _, err := reseq.ExecContext(ctx, i, userID, key, i); err != nil {
+				return stats, fmt.Errorf("reorder sighting %s: %w", key, err)
+			}
+			continue
+		}
+		_, err := upsert.ExecContext(ctx, userID, key, hash, i, obsDate(sg.ObsDt),
+			sg.SpeciesCode, sg.CommonName, sg.SciName, sg.ObsDt, sg.LocName, sg.LocID, sg.CountyCode,
+			sg.Lat, sg.Lng, sg.Count, sg.ObsValid, sg.ObsReviewed, sg.Media, sg.EnteredAsHeardOnly, sg.ChecklistID, sg.Source)
+		if err != nil {
+			return stats, fmt.Errorf("write sighting %s: %w", key, err)
+		}
+	}
+	for key := range existing {
+		if incoming[key] {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM sightings WHERE user_id = ? AND sighting_key = ?`, userID, key); err != nil {
+			return stats, fmt.Errorf("delete sighting %s: %w", key, err)
+		}
+		stats.Deleted++
+	}
+
+	// The index and meta are small: replace them wholesale.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM species_index WHERE user_id = ?`, userID); err != nil {
+		return stats, fmt.Errorf("clear species index: %w", err)
+	}
+	for i, si := range pc.SpeciesIndex {
+		if si.SpeciesCode == "" {
+			continue
+		}
+		locs, err := json.Marshal(nonNil(si.Locations))
+		if err != nil {
+			return stats, err
+		}
+		_, err = tx.ExecContext(ctx, `INSERT OR IGNORE INTO species_index
+			(user_id, species_code, seq, common_name, sci_name, first_seen, last_seen, total_checklists, total_count, locations)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			userID, si.SpeciesCode, i, si.CommonName, si.SciName, si.FirstSeen, si.LastSeen, si.TotalChecklists, si.TotalCount, string(locs))
+		if err != nil {
+			return stats, fmt.Errorf("write species index %s: %w", si.SpeciesCode, err)
+		}
+	}
+	m := pc.Meta
+	_, err = tx.ExecContext(ctx, `INSERT INTO users
+		(user_id, owner, source, generated_at, total_observations, total_species, first_checklist_date)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET owner = excluded.owner, source = excluded.source,
+			generated_at = excluded.generated_at, total_observations = excluded.total_observations,
+			total_species = excluded.total_species, first_checklist_date = excluded.first_checklist_date`,
+		userID, m.Owner, m.Source, m.GeneratedAt, m.TotalObservations, m.TotalSpecies, m.FirstChecklistDate)
+	if err != nil {
+		return stats, fmt.Errorf("write meta: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return stats, fmt.Errorf("commit import: %w", err)
+	}
+	return stats, nil
+}
+
+// HasUser reports whether any data has been imported for userID.
+func (s *Store) HasUser(ctx context.Context, userID string) (bool, error) {
+	var n int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users WHERE user_id = ?`, userID).Scan(&n); err != nil {
+		return false, fmt.Errorf("look up user: %w", err)
+	}
+	return n > 0, nil
+}
+
+// SeenSet returns every species userID has recorded in sightings or the index.
+func (s *Store) SeenSet(ctx context.Context, userID string) (map[string]struct{}, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT species_code FROM sightings WHERE user_id = ?
+		UNION SELECT species_code FROM species_index WHERE user_id = ?`, userID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query seen set: %w", err)
+	}
+	defer rows.Close()
+	seen := map[string]struct{}{}
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return nil, fmt.Errorf("query seen set: %w", err)
+		}
+		seen[code] = struct{}{}
+	}
+	return seen, rows.Err()
+}
+
+// SpeciesCodes returns every species code userID has recorded, sorted, as
+// ebird.SpeciesCodes does, from the indexes alone.
+func (s *Store) SpeciesCodes(ctx context.Context, userID string) ([]string, error) {
+	return s.values(ctx, `SELECT species_code FROM sightings WHERE user_id = ? AND species_code != ''
+		UNION SELECT species_code FROM species_index WHERE user_id = ? AND species_code != '' ORDER BY 1`, userID, userID)
+}
+
+// LocationIDs returns userID's distinct location IDs, sorted.
+func (s *Store) LocationIDs(ctx context.Context, userID string) ([]string, error) {
+	return s.values(ctx, `SELECT DISTINCT loc_id FROM sightings WHERE user_id = ? AND loc_id != '' ORDER BY loc_id`, userID)
+}
+
+// ChecklistIDs returns userID's distinct checklist IDs, sorted.
+func (s *Store) ChecklistIDs(ctx context.Context, userID string) ([]string, error) {
+	return s.values(ctx, `SELECT DISTINCT checklist_id FROM sightings WHERE user_id = ? AND checklist_id != '' ORDER BY checklist_id`, userID)
+}
+
+// Checklist materializes userID's full personal checklist, in import order.
+func (s *Store) Checklist(ctx context.Context, userID string) (*it.PersonalChecklist, error) {
+	return s.Subset(ctx, userID, ebird.Filter{})
+}
+
+// Subset returns userID's sightings matching f through the store's indexes,
+// with the same semantics as ebird.Subset: SpeciesIndex entries are included
+// for an unfiltered or species-only query.
+func (s *Store) Subset(ctx context.Context, userID string, f ebird.Filter) (*it.PersonalChecklist, error) {
+	pc, err := s.meta(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	where, args := filterClause(userID, f)
+	if pc.Sightings, err = s.sightings(ctx, `SELECT `+sightingColumns+` FROM sightings WHERE `+where+` ORDER BY seq`, args...); err != nil {
+		return nil, err
+	}
+	if !f.Empty() && !f.SpeciesOnly() {
+		return pc, nil
+	}
+	if pc.SpeciesIndex, err = s.speciesIndex(ctx, userID, f.SpeciesCodes); err != nil {
+		return nil, err
+	}
+	return pc, nil
+}
+
+// FirstSightings returns userID's checklist reduced to the earliest sighting
+// of each species in each county and year, plus the species index. Life
+// lists, regional and yearly species counts come out the same as from the
+// full checklist, without reading every repeat sighting.
+func (s *Store) FirstSightings(ctx context.Context, userID string) (*it.PersonalChecklist, error) {
+	pc, err := s.meta(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	pc.Sightings, err = s.sightings(ctx, `SELECT `+sightingColumns+` FROM (
+		SELECT *, ROW_NUMBER() OVER (PARTITION BY species_code, county_code, substr(obs_dt, 1, 4) ORDER BY obs_dt, seq) AS rn
+		FROM sightings WHERE user_id = ?) WHERE rn = 1 ORDER BY seq`, userID)
+	if err != nil {
+		return nil, err
+	}
+	if pc.SpeciesIndex, err = s.speciesIndex(ctx, userID, nil); err != nil {
+		return nil, err
+	}
+	return pc, nil
+}
+
+// Summary aggregates userID's sightings per species for ebird.ReconcileSummary.
+func (s *Store) Summary(ctx context.Context, userID string) (ebird.Summary, error) {
+	pc, err := s.meta(ctx, userID)
+	if err != nil {
+		return ebird.Summary{}, err
+	}
+	if pc.SpeciesIndex, err = s.speciesIndex(ctx, userID, nil); err != nil {
+		return ebird.Summary{}, err
+	}
+	sum := ebird.Summary{Checklist: pc, FirstSighting: map[string]int{}}
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sightings WHERE user_id = ?`, userID).Scan(&sum.Sightings); err != nil {
+		return ebird.Summary{}, fmt.Errorf("count sightings: %w", err)
+	}
+
+	// Names come from each species' first sighting; its position is its
+	// rank in import order, as in the materialized checklist.
+	rows, err := s.db.QueryContext(ctx, `WITH ranked AS (
+			SELECT *, ROW_NUMBER() OVER (ORDER BY seq) - 1 AS pos FROM sightings WHERE user_id = ?)
+		SELECT f.species_code, f.common_name, f.sci_name, a.first_seen, a.last_seen, a.total_checklists, a.total_count, a.locations, a.first_pos
+		FROM (SELECT species_code, MIN(pos) AS first_pos, MIN(NULLIF(obs_date, '')) AS first_seen, MAX(obs_date) AS last_seen,
+				COUNT(DISTINCT NULLIF(checklist_id, '')) AS total_checklists, SUM(count) AS total_count,
+				group_concat(DISTINCT NULLIF(loc_id, '')) AS locations
+			FROM ranked GROUP BY species_code) a
+		JOIN ranked f ON f.pos = a.first_pos
+		ORDER BY f.species_code`, userID)
+	if err != nil {
+		return ebird.Summary{}, fmt.Errorf("summarize sightings: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var si it.SpeciesIndex
+		var first, locs sql.NullString
+		var pos int
+		if err := rows.Scan(&si.SpeciesCode, &si.CommonName, &si.SciName, &first, &si.LastSeen,
+			&si.TotalChecklists, &si.TotalCount, &locs, &pos); err != nil {
+			return ebird.Summary{}, fmt.Errorf("summarize sightings: %w", err)
+		}
+		si.FirstSeen = first.String
+		si.Locations = splitSorted(locs.String)
+		sum.Rebuilt = append(sum.Rebuilt, si)
+		sum.FirstSighting[si.SpeciesCode] = pos
+	}
+	return sum, rows.Err()
+}
+
+// Locations aggregates userID's sightings per location, with the same
+// grouping and order as ebird.SummarizeLocations.
+func (s *Store) Locations(ctx context.Context, userID string) ([]ebird.LocationSummary, error) {
+	if _, err := s.meta(ctx, userID); err != nil {
+		return nil, err
+	}
+	rows, err := s.db.QueryContext(ctx, `WITH located AS (
+			SELECT *, CASE WHEN loc_id != '' THEN loc_id ELSE loc_name END AS loc_key
+			FROM sightings WHERE user_id = ? AND NOT (lat = 0 AND lng = 0))
+		SELECT f.loc_id, f.loc_name, f.county_code, f.lat, f.lng, a.first_visit, a.last_visit, a.species, a.checklists
+		FROM (SELECT loc_key, MIN(seq) AS first_seq, MIN(NULLIF(obs_dt, '')) AS first_visit, MAX(obs_dt) AS last_visit,
+				group_concat(DISTINCT species_code) AS species, COUNT(DISTINCT NULLIF(checklist_id, '')) AS checklists
+			FROM located GROUP BY loc_key) a
+		JOIN located f ON f.seq = a.first_seq
+		ORDER BY a.loc_key`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("summarize locations: %w", err)
+	}
+	defer rows.Close()
+	out := []ebird.LocationSummary{}
+	for rows.Next() {
+		var l ebird.LocationSummary
+		var first, species sql.NullString
+		if err := rows.Scan(&l.LocID, &l.LocName, &l.CountyCode, &l.Lat, &l.Lng, &first, &l.LastVisit, &species, &l.Checklists); err != nil {
+			return nil, fmt.Errorf("summarize locations: %w", err)
+		}
+		l.FirstVisit = first.String
+		l.Species = splitSorted(species.String)
+		out = append(out, l)
+	}
+	return out, rows.Err()
+}
+
+// meta returns a checklist holding only userID's meta, or ErrNoUser.
+func (s *Store) meta(ctx context.Context, userID string) (*it.PersonalChecklist, error) {
+	pc := &it.PersonalChecklist{}
+	m := &pc.Meta
+	err := s.db.QueryRowContext(ctx, `SELECT owner, source, generated_at, total_observations, total_species, first_checklist_date
+		FROM users WHERE user_id = ?`, userID).
+		Scan(&m.Owner, &m.Source, &m.GeneratedAt, &m.TotalObservations, &m.TotalSpecies, &m.FirstChecklistDate)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %q", ErrNoUser, userID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read meta: %w", err)
+	}
+	return pc, nil
+}
+
+// values runs a query selecting one string column.
+func (s *Store) values(ctx context.Context, query string, args ...any) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query values: %w", err)
+	}
+	defer rows.Close()
+	out := []string{}
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, fmt.Errorf("query values: %w", err)
+		}
+		out = append(out, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query values: %w", err)
+	}
+	return out, nil
+}
+
+// sightings runs a query selecting sightingColumns.
+func (s *Store) sightings(ctx context.Context, query string, args ...any) ([]it.PersonalSighting, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query sightings: %w", err)
+	}
+	defer rows.Close()
+	var out []it.PersonalSighting
+	for rows.Next() {
+		var sg it.PersonalSighting
+		if err := rows.Scan(&sg.SpeciesCode, &sg.CommonName, &sg.SciName, &sg.ObsDt, &sg.LocName, &sg.LocID, &sg.CountyCode,
+			&sg.Lat, &sg.Lng, &sg.Count, &sg.ObsValid, &sg.ObsReviewed, &sg.Media, &sg.EnteredAsHeardOnly, &sg.ChecklistID, &sg.Source); err != nil {
+			return nil, fmt.Errorf("query sightings: %w", err)
+		}
+		out = append(out, sg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query sightings: %w", err)
+	}
+	return out, nil
+}
+
+// speciesIndex returns userID's stored index, limited to codes if any.
+func (s *Store) speciesIndex(ctx context.Context, userID string, codes []string) ([]it.SpeciesIndex, error) {
+	query := `SELECT species_code, common_name, sci_name, first_seen, last_seen, total_checklists, total_count, locations
+		FROM species_index WHERE user_id = ?`
+	args := []any{userID}
+	if len(codes) > 0 {
+		query += ` AND species_code IN (` + placeholders(len(codes)) + `)`
+		for _, c := range codes {
+			args = append(args, c)
+		}
+	}
+	rows, err := s.db.QueryContext(ctx, query+` ORDER BY seq`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query species index: %w", err)
+	}
+	defer rows.Close()
+	var out []it.SpeciesIndex
+	for rows.Next() {
+		var si it.SpeciesIndex
+		var locs string
+		if err := rows.Scan(&si.SpeciesCode, &si.CommonName, &si.SciName, &si.FirstSeen, &si.LastSeen,
+			&si.TotalChecklists, &si.TotalCount, &locs); err != nil {
+			return nil, fmt.Errorf("query species index: %w", err)
+		}
+		if err := json.Unmarshal([]byte(locs), &si.Locations); err != nil {
+			return nil, fmt.Errorf("decode locations for %s: %w", si.SpeciesCode, err)
+		}
+		out = append(out, si)
+	}
+	return out, rows.Err()
+}
+
+func rowHash(s it.PersonalSighting) string {
+	b, _ := json.Marshal(s)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:8])
+}
+
+func filterClause(userID string, f ebird.Filter) (string, []any) {
+	clauses := []string{"user_id = ?"}
+	args := []any{userID}
+	if len(f.SpeciesCodes) > 0 {
+		clauses = append(clauses, "species_code IN ("+placeholders(len(f.SpeciesCodes))+")")
+		for _, c := range f.SpeciesCodes {
+			args = append(args, c)
+		}
+	}
+	for _, c := range []struct{ column, v string }{
+		{"loc_id = ?", f.LocID},
+		{"checklist_id = ?", f.ChecklistID},
+		{"county_code = ?", f.CountyCode},
+		{"obs_date >= ?", f.From},
+		{"obs_date <= ?", f.To},
+	} {
+		if c.v != "" {
+			clauses = append(clauses, c.column)
+			args = append(args, c.v)
+		}
+	}
+	return strings.Join(clauses, " AND "), args
+}
+
+// splitSorted splits a group_concat list into sorted values.
+func splitSorted(list string) []string {
+	if list == "" {
+		return []string{}
+	}
+	out := strings.Split(list, ",")
+	sort.Strings(out)
+	return out
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// obsDate is the YYYY-MM-DD part of an obsDt, for date-range indexes.
+func obsDate(obsDt string) string {
+	if len(obsDt) > 10 {
+		return obsDt[:10]
+	}
+	return obsDt
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
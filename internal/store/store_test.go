@@ -0,0 +1,224 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kpb/wingit-mcp/internal/ebird"
+	it "github.com/kpb/wingit-mcp/internal/types"
+)
+
+func openTemp(t *testing.T) *Store {
+	t.Helper()
+	st, err := Open(filepath.Join(t.TempDir(), "wingit.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { st.Close() })
+	return st
+}
+
+func Test_import_file_then_query(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	st := openTemp(t)
+
+	path := filepath.Join(t.TempDir(), "personal.json")
+	b, err := os.ReadFile(filepath.Join("testdata", "personal_checklist_example.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := st.ImportFile(ctx, "alice", path, nil)
+	if err != nil {
+		t.Fatalf("ImportFile: %v", err)
+	}
+	if stats.Inserted != 2 || stats.Skipped {
+		t.Fatalf("first import = %+v, want 2 inserted", stats)
+	}
+	if stats, err = st.ImportFile(ctx, "alice", path, nil); err != nil || !stats.Skipped {
+		t.Fatalf("re-import = %+v, %v; want skipped", stats, err)
+	}
+
+	want, err := ebird.LoadPersonalChecklist(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := st.Checklist(ctx, "alice")
+	if err != nil {
+		t.Fatalf("Checklist: %v", err)
+	}
+	if got.Meta != want.Meta || len(got.Sightings) != len(want.Sightings) || len(got.SpeciesIndex) != len(want.SpeciesIndex) {
+		t.Fatalf("materialized checklist differs:\n got %+v\nwant %+v", got, want)
+	}
+	for i := range want.Sightings {
+		if got.Sightings[i] != want.Sightings[i] {
+			t.Errorf("sighting %d = %+v, want %+v", i, got.Sightings[i], want.Sightings[i])
+		}
+	}
+
+	seen, err := st.SeenSet(ctx, "alice")
+	if err != nil || len(seen) != len(ebird.BuildPersonalSeenSet(want)) {
+		t.Fatalf("SeenSet = %v, %v", seen, err)
+	}
+
+	// Indexed queries agree with the in-memory filter.
+	for _, f := range []ebird.Filter{
+		{SpeciesCodes: []string{"clanut"}},
+		{LocID: "L998877"},
+		{CountyCode: "US-NM-049", From: "2025-01-01"},
+		{ChecklistID: "S100000002"},
+	} {
+		sub, err := st.Subset(ctx, "alice", f)
+		if err != nil {
+			t.Fatalf("Subset(%+v): %v", f, err)
+		}
+		mem := ebird.Subset(want, f)
+		if len(sub.Sightings) != len(mem.Sightings) || len(sub.SpeciesIndex) != len(mem.SpeciesIndex) {
+			t.Errorf("Subset(%+v) = %d sightings/%d index, want %d/%d",
+				f, len(sub.Sightings), len(sub.SpeciesIndex), len(mem.Sightings), len(mem.SpeciesIndex))
+		}
+	}
+
+	if _, err := st.Checklist(ctx, "bob"); !errors.Is(err, ErrNoUser) {
+		t.Fatalf("Checklist(bob) err = %v, want ErrNoUser", err)
+	}
+}
+
+func Test_import_writes_only_changes(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	st := openTemp(t)
+
+	pc := &it.PersonalChecklist{Sightings: []it.PersonalSighting{
+		{SpeciesCode: "clanut", ChecklistID: "S1", ObsDt: "2025-01-01", Count: 1},
+		{SpeciesCode: "amgold", ChecklistID: "S1", ObsDt: "2025-01-01", Count: 3},
+		{SpeciesCode: "amgold", ChecklistID: "S1", ObsDt: "2025-01-01", Count: 3}, // duplicate
+	}}
+	stats, err := st.Import(ctx, "alice", pc)
+	if err != nil || stats.Inserted != 2 || stats.Sightings != 2 {
+		t.Fatalf("Import = %+v, %v; want 2 inserted", stats, err)
+	}
+
+	pc.Sightings = []it.PersonalSighting{
+		{SpeciesCode: "amgold", ChecklistID: "S1", ObsDt: "2025-01-01", Count: 4},
+		{SpeciesCode: "lewo", ChecklistID: "S2", ObsDt: "2025-02-01", Count: 1},
+	}
+	stats, err = st.Import(ctx, "alice", pc)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	want := ImportStats{Sightings: 2, Inserted: 1, Updated: 1, Deleted: 1}
+	if stats != want {
+		t.Fatalf("Import = %+v, want %+v", stats, want)
+	}
+	seen, err := st.SeenSet(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := seen["clanut"]; ok || len(seen) != 2 {
+		t.Fatalf("seen = %v, want amgold and lewo", seen)
+	}
+}
+
+func Test_aggregates_match_the_full_checklist(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	st := openTemp(t)
+
+	pc := &it.PersonalChecklist{
+		Sightings: []it.PersonalSighting{
+			{SpeciesCode: "amgold", CommonName: "American Goldfinch", ObsDt: "2024-05-02 08:00", CountyCode: "US-NM-049", LocID: "L1", LocName: "Pond", Lat: 35.6, Lng: -105.9, Count: 2, ChecklistID: "S1"},
+			{SpeciesCode: "clanut", CommonName: "Clark's Nutcracker", ObsDt: "2024-05-02 08:00", CountyCode: "US-NM-049", LocID: "L1", LocName: "Pond", Lat: 35.6, Lng: -105.9, Count: 1, ChecklistID: "S1"},
+			{SpeciesCode: "amgold", CommonName: "American Goldfinch", ObsDt: "2023-11-20 07:30", CountyCode: "US-NM-049", LocID: "L2", LocName: "Ridge", Lat: 35.7, Lng: -105.8, Count: 5, ChecklistID: "S2"},
+			{SpeciesCode: "amgold", CommonName: "American Goldfinch", ObsDt: "2024-06-01 09:00", CountyCode: "US-CO-031", LocID: "L3", LocName: "Park", Lat: 39.7, Lng: -105.0, Count: 1, ChecklistID: "S3"},
+			{SpeciesCode: "lewo", CommonName: "Lewis's Woodpecker", ObsDt: "2024-06-01 09:00", CountyCode: "US-CO-031", LocID: "L3", LocName: "Park", Lat: 39.7, Lng: -105.0, Count: 1, ChecklistID: "S3"},
+			{SpeciesCode: "lewo", CommonName: "Lewis's Woodpecker", ObsDt: "", CountyCode: "US-CO-031", LocName: "Somewhere", Count: 1},
+			{SpeciesCode: "clanut", CommonName: "Clark's Nutcracker", ObsDt: "2024-05-03 06:00", CountyCode: "US-NM-049", LocID: "L1", LocName: "Pond", Lat: 35.6, Lng: -105.9, Count: 3, ChecklistID: "S4"},
+		},
+		SpeciesIndex: []it.SpeciesIndex{
+			{SpeciesCode: "amgold", CommonName: "American Goldfinch", FirstSeen: "2024-05-02", LastSeen: "2024-01-01", TotalChecklists: 1},
+			{SpeciesCode: "rotr", CommonName: "Rock Wren", FirstSeen: "2010-04-01"},
+		},
+	}
+	if _, err := st.Import(ctx, "alice", pc); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	full, err := st.Checklist(ctx, "alice")
+	if err != nil {
+		t.Fatalf("Checklist: %v", err)
+	}
+
+	first, err := st.FirstSightings(ctx, "alice")
+	if err != nil {
+		t.Fatalf("FirstSightings: %v", err)
+	}
+	if len(first.Sightings) >= len(full.Sightings) {
+		t.Fatalf("FirstSightings kept %d of %d sightings", len(first.Sightings), len(full.Sightings))
+	}
+	if got, want := ebird.LifeList(first), ebird.LifeList(full); !reflect.DeepEqual(got, want) {
+		t.Errorf("LifeList(first sightings) = %+v\nwant %+v", got, want)
+	}
+	for _, key := range []func(it.PersonalSighting) string{
+		func(s it.PersonalSighting) string { return s.CountyCode },
+		func(s it.PersonalSighting) string { return strings.Split(s.ObsDt, "-")[0] },
+	} {
+		if got, want := speciesBy(first.Sightings, key), speciesBy(full.Sightings, key); !reflect.DeepEqual(got, want) {
+			t.Errorf("species by group = %v, want %v", got, want)
+		}
+	}
+
+	sum, err := st.Summary(ctx, "alice")
+	if err != nil {
+		t.Fatalf("Summary: %v", err)
+	}
+	if got, want := ebird.ReconcileSummary(sum), ebird.Reconcile(full); !reflect.DeepEqual(got, want) {
+		t.Errorf("ReconcileSummary = %+v\nwant %+v", got, want)
+	}
+	if sum.Sightings != len(full.Sightings) {
+		t.Errorf("Summary.Sightings = %d, want %d", sum.Sightings, len(full.Sightings))
+	}
+
+	locs, err := st.Locations(ctx, "alice")
+	if err != nil {
+		t.Fatalf("Locations: %v", err)
+	}
+	if want := ebird.SummarizeLocations(full.Sightings); !reflect.DeepEqual(locs, want) {
+		t.Errorf("Locations = %+v\nwant %+v", locs, want)
+	}
+
+	for _, c := range []struct {
+		name  string
+		query func(context.Context, string) ([]string, error)
+		want  []string
+	}{
+		{"SpeciesCodes", st.SpeciesCodes, ebird.SpeciesCodes(full)},
+		{"LocationIDs", st.LocationIDs, ebird.LocationIDs(full)},
+		{"ChecklistIDs", st.ChecklistIDs, ebird.ChecklistIDs(full)},
+	} {
+		if got, err := c.query(ctx, "alice"); err != nil || !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s = %v, %v; want %v", c.name, got, err, c.want)
+		}
+	}
+}
+
+// speciesBy groups sightings by key into sets of species codes.
+func speciesBy(sightings []it.PersonalSighting, key func(it.PersonalSighting) string) map[string]map[string]bool {
+	out := map[string]map[string]bool{}
+	for _, s := range sightings {
+		k := key(s)
+		if out[k] == nil {
+			out[k] = map[string]bool{}
+		}
+		out[k][s.SpeciesCode] = true
+	}
+	return out
+}
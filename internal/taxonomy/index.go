@@ -0,0 +1,247 @@
+// internal/taxonomy/index.go
+package taxonomy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Key kinds, in the order they are reported in Match.MatchedOn.
+const (
+	KindSpeciesCode = "speciesCode"
+	KindBandingCode = "bandingCode"
+	KindComName     = "comName"
+	KindSciName     = "sciName"
+	KindFormerName  = "formerName"
+)
+
+// searchKey is one searchable string for a taxon.
+type searchKey struct {
+	text    string // normalized: lowercase words separated by single spaces
+	compact string // text without spaces, so "redtail" finds "red tailed hawk"
+	kind    string
+	entry   int
+}
+
+// Index is an in-memory search index over taxonomy names and codes.
+// It is immutable once built and safe for concurrent use.
+type Index struct {
+	entries []Entry
+	keys    []searchKey
+	// exact maps normalized text to key positions (codes and full names).
+	exact map[string][]int
+	// byCompact holds key positions sorted by compact text for prefix scans.
+	byCompact []int
+	// trigrams maps a trigram of compact text to key positions for fuzzy search.
+	trigrams map[string][]int
+}
+
+// NewIndex builds a search index over entries, including each entry's
+// FormerNames.
+func NewIndex(entries []Entry) *Index {
+	ix := &Index{entries: entries, exact: map[string][]int{}, trigrams: map[string][]int{}}
+	for i, e := range entries {
+		ix.add(i, KindSpeciesCode, e.SpeciesCode)
+		for _, c := range e.BandingCodes {
+			ix.add(i, KindBandingCode, c)
+		}
+		ix.add(i, KindComName, e.CommonName)
+		ix.add(i, KindSciName, e.SciName)
+		for _, n := range e.FormerNames {
+			ix.add(i, KindFormerName, n)
+		}
+	}
+
+	ix.byCompact = make([]int, len(ix.keys))
+	for k := range ix.keys {
+		ix.byCompact[k] = k
+	}
+	sort.Slice(ix.byCompact, func(a, b int) bool {
+		return ix.keys[ix.byCompact[a]].compact < ix.keys[ix.byCompact[b]].compact
+	})
+	return ix
+}
+
+func (ix *Index) add(entry int, kind, raw string) {
+	text := normalize(raw)
+	if text == "" {
+		return
+	}
+	k := len(ix.keys)
+	compact := strings.ReplaceAll(text, " ", "")
+	ix.keys = append(ix.keys, searchKey{text: text, compact: compact, kind: kind, entry: entry})
+	ix.exact[text] = append(ix.exact[text], k)
+	if kind == KindSpeciesCode || kind == KindBandingCode {
+		return // codes only match exactly or by prefix
+	}
+	for _, tg := range trigramsOf(compact) {
+		ix.trigrams[tg] = append(ix.trigrams[tg], k)
+	}
+}
+
+// Search returns entries matching query, best first. At most limit matches
+// are returned (all if limit <= 0).
+//
+// Scores: species code 1.0; banding code or exact name 0.95 (former name
+// 0.9); name prefix 0.85; run-together prefix 0.8 ("redtail"); every query
+// word starting a name word 0.75 ("tail hawk"); substring 0.7; within an edit
+// distance of about one per four characters 0.6 less 0.05 per edit.
+func (ix *Index) Search(query string, limit int) []Match {
+	q := normalize(query)
+	if ix == nil || q == "" {
+		return nil
+	}
+	qc := strings.ReplaceAll(q, " ", "")
+
+	best := map[int]Match{}
+	consider := func(k int, score float64) {
+		key := ix.keys[k]
+		if m, ok := best[key.entry]; ok && m.Score >= score {
+			return
+		}
+		best[key.entry] = Match{Entry: ix.entries[key.entry], Score: score, MatchedOn: key.kind}
+	}
+
+	for _, k := range ix.exact[q] {
+		switch ix.keys[k].kind {
+		case KindSpeciesCode:
+			consider(k, 1)
+		case KindFormerName:
+			consider(k, 0.9)
+		default:
+			consider(k, 0.95)
+		}
+	}
+
+	// Prefix scan over compact keys.
+	start := sort.Search(len(ix.byCompact), func(i int) bool {
+		return ix.keys[ix.byCompact[i]].compact >= qc
+	})
+	for i := start; i < len(ix.byCompact); i++ {
+		k := ix.byCompact[i]
+		key := ix.keys[k]
+		if !strings.HasPrefix(key.compact, qc) {
+			break
+		}
+		if strings.HasPrefix(key.text, q) {
+			consider(k, 0.85)
+		} else {
+			consider(k, 0.8)
+		}
+	}
+
+	// Word-prefix, substring and fuzzy matches among trigram candidates.
+	// Short queries are too ambiguous for anything but prefixes.
+	if len(qc) >= 3 {
+		for _, k := range ix.candidates(q) {
+			key := ix.keys[k]
+			switch {
+			case wordPrefixes(key.text, q):
+				consider(k, 0.75)
+			case strings.Contains(key.compact, qc):
+				consider(k, 0.7)
+			case len(qc) >= 4:
+				if d := levenshtein(key.compact, qc); d <= len(qc)/4 {
+					consider(k, 0.6-0.05*float64(d))
+				}
+			}
+		}
+	}
+
+	out := make([]Match, 0, len(best))
+	for _, m := range best {
+		out = append(out, m)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		if out[i].Entry.TaxonOrder != out[j].Entry.TaxonOrder {
+			return out[i].Entry.TaxonOrder < out[j].Entry.TaxonOrder
+		}
+		return out[i].Entry.SpeciesCode < out[j].Entry.SpeciesCode
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// candidates returns name keys sharing enough trigrams with q to possibly
+// match it by substring or within the allowed edit distance.
+func (ix *Index) candidates(q string) []int {
+	qc := strings.ReplaceAll(q, " ", "")
+	tgs := trigramsOf(qc)
+	hits := map[int]int{}
+	for _, tg := range tgs {
+		for _, k := range ix.trigrams[tg] {
+			hits[k]++
+		}
+	}
+	// Each edit destroys at most three trigrams.
+	need := len(tgs) - 3*(len(qc)/4)
+	if strings.Contains(q, " ") || need < 1 {
+		// Word prefixes ("tail hawk") share few trigrams with the name.
+		need = 1
+	}
+	out := make([]int, 0, len(hits))
+	for k, n := range hits {
+		if n >= need {
+			out = append(out, k)
+		}
+	}
+	sort.Ints(out)
+	return out
+}
+
+// wordPrefixes reports whether every word of q starts some word of text.
+func wordPrefixes(text, q string) bool {
+	words := strings.Fields(text)
+	for _, qw := range strings.Fields(q) {
+		found := false
+		for _, w := range words {
+			if strings.HasPrefix(w, qw) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func trigramsOf(s string) []string {
+	r := []rune(s)
+	if len(r) < 3 {
+		return nil
+	}
+	seen := map[string]bool{}
+	out := make([]string, 0, len(r)-2)
+	for i := 0; i+3 <= len(r); i++ {
+		tg := string(r[i : i+3])
+		if !seen[tg] {
+			seen[tg] = true
+			out = append(out, tg)
+		}
+	}
+	return out
+}
+
+// LoadAltNames reads a JSON object mapping species codes to alternate or
+// former names (e.g. names used before a split or lump).
+func LoadAltNames(path string) (map[string][]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read alternate names: %w", err)
+	}
+	var alt map[string][]string
+	if err := json.Unmarshal(b, &alt); err != nil {
+		return nil, fmt.Errorf("decode alternate names: %w", err)
+	}
+	return alt, nil
+}
@@ -0,0 +1,47 @@
+package taxonomy
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func Test_index_search_ranks_codes_names_and_alternates(t *testing.T) {
+	tax, err := Load(filepath.Join("testdata", "taxonomy_example.json"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	alt, err := LoadAltNames(filepath.Join("testdata", "alt_names_example.json"))
+	if err != nil {
+		t.Fatalf("LoadAltNames: %v", err)
+	}
+	tax = tax.WithAltNames(alt)
+
+	cases := []struct {
+		query, want, on string
+	}{
+		{"RTHA", "rethaw", KindBandingCode},
+		{"redtail", "rethaw", KindFormerName},
+		{"red-tailed", "rethaw", KindComName},
+		{"tail hawk", "rethaw", KindComName},
+		{"Buteo jamaicensis", "rethaw", KindSciName},
+		{"Buteo jamiacensis", "rethaw", KindSciName},
+		{"brown towhee", "caltow", KindFormerName},
+		{"nutcracker", "clanut", KindComName},
+	}
+	for _, c := range cases {
+		got := tax.Resolve(c.query, 1)
+		if len(got) == 0 || got[0].Entry.SpeciesCode != c.want || got[0].MatchedOn != c.on {
+			t.Errorf("Resolve(%q) = %+v, want %s via %s", c.query, got, c.want, c.on)
+		}
+	}
+
+	// A shared prefix ranks both finches, in taxonomic order.
+	got := tax.Resolve("spinus", 0)
+	if len(got) != 2 || got[0].Entry.SpeciesCode != "pinsis" || got[1].Entry.SpeciesCode != "amgold" {
+		t.Fatalf("Resolve(spinus) = %+v", got)
+	}
+	// Exact code beats a name prefix.
+	if got := tax.Resolve("lewo", 0); got[0].Score != 1 {
+		t.Fatalf("Resolve(lewo) score = %v, want 1", got[0].Score)
+	}
+}
@@ -0,0 +1,92 @@
+// internal/taxonomy/locale.go
+package taxonomy
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Locales holds localized common names keyed by eBird locale code
+// ("es", "fr", "pt_BR", ...) and species code.
+type Locales struct {
+	names map[string]map[string]string
+}
+
+// LoadLocalesDir reads every <locale>.json file in dir. Each file is an
+// eBird taxonomy export fetched with that locale, so comName is localized.
+func LoadLocalesDir(dir string) (*Locales, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("list locales: %w", err)
+	}
+	l := &Locales{names: make(map[string]map[string]string, len(paths))}
+	for _, p := range paths {
+		tax, err := Load(p)
+		if err != nil {
+			return nil, fmt.Errorf("locale %s: %w", filepath.Base(p), err)
+		}
+		names := make(map[string]string, tax.Len())
+		for _, e := range tax.Entries() {
+			if e.CommonName != "" {
+				names[e.SpeciesCode] = e.CommonName
+			}
+		}
+		l.names[NormalizeLocale(strings.TrimSuffix(filepath.Base(p), ".json"))] = names
+	}
+	return l, nil
+}
+
+// NormalizeLocale maps "es-MX", "ES_mx" and the like to eBird's "es_MX".
+func NormalizeLocale(locale string) string {
+	locale = strings.ReplaceAll(strings.TrimSpace(locale), "-", "_")
+	lang, region, ok := strings.Cut(locale, "_")
+	if !ok {
+		return strings.ToLower(lang)
+	}
+	return strings.ToLower(lang) + "_" + strings.ToUpper(region)
+}
+
+// Has reports whether names for locale (or its base language) are loaded.
+func (l *Locales) Has(locale string) bool {
+	_, ok := l.table(locale)
+	return ok
+}
+
+// Available returns the loaded locale codes, sorted.
+func (l *Locales) Available() []string {
+	if l == nil {
+		return nil
+	}
+	out := make([]string, 0, len(l.names))
+	for k := range l.names {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// CommonName returns the localized common name for a species. A regional
+// locale such as "es_MX" falls back to "es" when not loaded itself.
+func (l *Locales) CommonName(locale, code string) (string, bool) {
+	t, ok := l.table(locale)
+	if !ok {
+		return "", false
+	}
+	name, ok := t[code]
+	return name, ok
+}
+
+func (l *Locales) table(locale string) (map[string]string, bool) {
+	if l == nil {
+		return nil, false
+	}
+	locale = NormalizeLocale(locale)
+	if t, ok := l.names[locale]; ok {
+		return t, true
+	}
+	lang, _, _ := strings.Cut(locale, "_")
+	t, ok := l.names[lang]
+	return t, ok
+}
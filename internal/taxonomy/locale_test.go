@@ -0,0 +1,30 @@
+package taxonomy
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_locales_lookup_with_regional_fallback(t *testing.T) {
+	l, err := LoadLocalesDir(filepath.Join("testdata", "locales"))
+	if err != nil {
+		t.Fatalf("LoadLocalesDir: %v", err)
+	}
+	if got, want := l.Available(), []string{"es", "fr"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Available = %v, want %v", got, want)
+	}
+	if name, ok := l.CommonName("fr", "lewo"); !ok || name != "Pic de Lewis" {
+		t.Fatalf("CommonName(fr, lewo) = %q, %v", name, ok)
+	}
+	if name, ok := l.CommonName("es-MX", "pinsis"); !ok || name != "Jilguero Pinero" {
+		t.Fatalf("CommonName(es-MX, pinsis) = %q, %v", name, ok)
+	}
+	if l.Has("de") {
+		t.Fatalf("unexpected German locale")
+	}
+	var none *Locales
+	if _, ok := none.CommonName("es", "lewo"); ok {
+		t.Fatalf("nil Locales should have no names")
+	}
+}
@@ -0,0 +1,72 @@
+// internal/taxonomy/resolve.go
+package taxonomy
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Match is a taxonomy entry matched by Resolve, best first.
+type Match struct {
+	Entry Entry `json:"entry"`
+	// Score is in (0,1]; 1 is an exact species code match.
+	Score float64 `json:"score"`
+	// MatchedOn names the field that matched (speciesCode, bandingCode,
+	// comName, sciName, formerName).
+	MatchedOn string `json:"matchedOn"`
+}
+
+// Resolve finds taxa for a free-form query: an eBird species code, a
+// 4-letter banding code, or a common, scientific or former name, tolerating
+// typos. See Index.Search for ranking. At most limit matches are returned
+// (all if limit <= 0).
+func (t *Taxonomy) Resolve(query string, limit int) []Match {
+	if t == nil {
+		return nil
+	}
+	return t.Index().Search(query, limit)
+}
+
+// normalize lowercases s and folds punctuation to single spaces, so
+// "Lewis's Woodpecker" and "lewiss woodpecker" compare equal.
+func normalize(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+		case r == '\'' || r == '’':
+			// Drop apostrophes without splitting words.
+		default:
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	prev := make([]int, len(br)+1)
+	cur := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		cur[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(br)]
+}
@@ -0,0 +1,34 @@
+package taxonomy
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func Test_resolve_codes_names_and_typos(t *testing.T) {
+	tax, err := Load(filepath.Join("testdata", "taxonomy_example.json"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	cases := []struct {
+		query, want, on string
+	}{
+		{"clanut", "clanut", "speciesCode"},
+		{"RTHA", "rethaw", "bandingCode"},
+		{"Lewis's Woodpecker", "lewo", "comName"},
+		{"buteo jamaicensis", "rethaw", "sciName"},
+		{"Pine Siskn", "pinsis", "comName"},
+		{"canyon", "caltow", "comName"},
+	}
+	for _, c := range cases {
+		got := tax.Resolve(c.query, 1)
+		if len(got) == 0 || got[0].Entry.SpeciesCode != c.want || got[0].MatchedOn != c.on {
+			t.Errorf("Resolve(%q) = %+v, want %s via %s", c.query, got, c.want, c.on)
+		}
+	}
+
+	if got := tax.Resolve("zzzz", 0); len(got) != 0 {
+		t.Fatalf("Resolve(zzzz) = %+v, want none", got)
+	}
+}
@@ -0,0 +1,114 @@
+// internal/taxonomy/taxonomy.go
+package taxonomy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Entry is one taxon from the eBird taxonomy (ref/taxonomy/ebird?fmt=json).
+type Entry struct {
+	SpeciesCode   string   `json:"speciesCode"`
+	CommonName    string   `json:"comName"`
+	SciName       string   `json:"sciName"`
+	Category      string   `json:"category"`
+	TaxonOrder    float64  `json:"taxonOrder"`
+	BandingCodes  []string `json:"bandingCodes,omitempty"`
+	ComNameCodes  []string `json:"comNameCodes,omitempty"`
+	SciNameCodes  []string `json:"sciNameCodes,omitempty"`
+	Order         string   `json:"order"`
+	FamilyCode    string   `json:"familyCode"`
+	FamilyComName string   `json:"familyComName"`
+	FamilySciName string   `json:"familySciName"`
+	ReportAs      string   `json:"reportAs,omitempty"`
+	Extinct       bool     `json:"extinct,omitempty"`
+	// ExoticCategory is eBird's exotic status where known: N (naturalized),
+	// P (provisional) or X (escapee). Empty means native.
+	ExoticCategory string `json:"exoticCategory,omitempty"`
+	// FormerNames are earlier or alternate common names, e.g. before a split.
+	FormerNames []string `json:"formerNames,omitempty"`
+}
+
+// Taxonomy indexes taxonomy entries by species code.
+type Taxonomy struct {
+	entries []Entry
+	byCode  map[string]int
+
+	indexOnce sync.Once
+	index     *Index
+}
+
+// New builds a Taxonomy from entries, ordered by TaxonOrder.
+func New(entries []Entry) *Taxonomy {
+	t := &Taxonomy{entries: append([]Entry(nil), entries...), byCode: make(map[string]int, len(entries))}
+	sort.SliceStable(t.entries, func(i, j int) bool { return t.entries[i].TaxonOrder < t.entries[j].TaxonOrder })
+	for i, e := range t.entries {
+		t.byCode[e.SpeciesCode] = i
+	}
+	return t
+}
+
+// Load reads an eBird taxonomy JSON file at path.
+func Load(path string) (*Taxonomy, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read taxonomy: %w", err)
+	}
+	var entries []Entry
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if err := dec.Decode(&entries); err != nil {
+		return nil, fmt.Errorf("decode taxonomy: %w", err)
+	}
+	return New(entries), nil
+}
+
+// WithAltNames returns a copy of t with alternate names (species code ->
+// names) appended to each entry's FormerNames.
+func (t *Taxonomy) WithAltNames(alt map[string][]string) *Taxonomy {
+	entries := make([]Entry, len(t.entries))
+	for i, e := range t.entries {
+		if names := alt[e.SpeciesCode]; len(names) > 0 {
+			e.FormerNames = append(append([]string(nil), e.FormerNames...), names...)
+		}
+		entries[i] = e
+	}
+	return New(entries)
+}
+
+// Index returns the search index over t, building it on first use.
+func (t *Taxonomy) Index() *Index {
+	t.indexOnce.Do(func() { t.index = NewIndex(t.entries) })
+	return t.index
+}
+
+// Lookup returns the entry for a species code. A nil Taxonomy finds nothing.
+func (t *Taxonomy) Lookup(code string) (Entry, bool) {
+	if t == nil {
+		return Entry{}, false
+	}
+	i, ok := t.byCode[code]
+	if !ok {
+		return Entry{}, false
+	}
+	return t.entries[i], true
+}
+
+// Entries returns all entries in taxonomic order.
+func (t *Taxonomy) Entries() []Entry {
+	if t == nil {
+		return nil
+	}
+	return t.entries
+}
+
+// Len reports the number of taxa.
+func (t *Taxonomy) Len() int {
+	if t == nil {
+		return 0
+	}
+	return len(t.entries)
+}
@@ -0,0 +1,28 @@
+package taxonomy
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func Test_load_and_lookup(t *testing.T) {
+	tax, err := Load(filepath.Join("testdata", "taxonomy_example.json"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	e, ok := tax.Lookup("clanut")
+	if !ok || e.FamilySciName != "Corvidae" || e.Order != "Passeriformes" {
+		t.Fatalf("Lookup(clanut) = %+v, %v", e, ok)
+	}
+	entries := tax.Entries()
+	for i := 1; i < len(entries); i++ {
+		if entries[i-1].TaxonOrder > entries[i].TaxonOrder {
+			t.Fatalf("entries not in taxonomic order at %d", i)
+		}
+	}
+
+	var nilTax *Taxonomy
+	if _, ok := nilTax.Lookup("clanut"); ok || nilTax.Len() != 0 {
+		t.Fatalf("nil taxonomy should be empty")
+	}
+}
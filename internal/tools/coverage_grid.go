@@ -0,0 +1,222 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/kpb/wingit-mcp/internal/geo"
+	it "github.com/kpb/wingit-mcp/internal/types"
+)
+
+type coverageGridArgs struct {
+	// CellKm is the grid cell size (default 5 km). Ignored with GeohashPrecision.
+	CellKm float64 `json:",omitempty"`
+	// GeohashPrecision (1-12) bins by geohash cell instead of a km grid.
+	GeohashPrecision int `json:",omitempty"`
+	// Location is a "lat,lng" center for the unbirded-cell search; defaults to
+	// the centroid of the user's sightings.
+	Location string `json:",omitempty"`
+	// RadiusKm bounds the unbirded-cell search (default 50 km).
+	RadiusKm         float64 `json:",omitempty"`
+	IncludeHeardOnly bool    `json:",omitempty"`
+	// MaxCells caps each returned list (default 100).
+	MaxCells int    `json:",omitempty"`
+	User     string `json:",omitempty"`
+}
+
+type GridCell struct {
+	ID         string
+	CenterLat  float64
+	CenterLng  float64
+	MinLat     float64
+	MinLng     float64
+	MaxLat     float64
+	MaxLng     float64
+	Species    int
+	Checklists int
+	Sightings  int
+}
+
+// OpportunityCell is a cell with recent lifer reports the user has never birded.
+type OpportunityCell struct {
+	GridCell
+	DistanceKm   float64
+	LiferCount   int
+	LiferSpecies []string
+}
+
+type coverageGrid struct {
+	Grid             string
+	CellKm           float64 `json:",omitempty"`
+	GeohashPrecision int     `json:",omitempty"`
+	CenterLat        float64
+	CenterLng        float64
+	RadiusKm         float64
+	// Cells are the user's birded cells, most checklists first.
+	Cells []GridCell
+	// Unbirded are nearby cells with recent lifer reports but no personal
+	// checklists, most lifers first.
+	Unbirded []OpportunityCell
+}
+
+// Exported aliases for the MCP layer.
+type CoverageGridArgs = coverageGridArgs
+type CoverageGrid = coverageGrid
+
+const (
+	defaultCellKm       = 5.0
+	defaultGridRadiusKm = 50.0
+	defaultGridMaxCells = 100
+	maxGeohashPrecision = 12
+	minGridCellKm       = 0.1
+)
+
+// BuildCoverageGrid bins personal sightings into grid cells and finds
+// nearby unbirded cells where recent observations include species the user
+// has not seen. Sightings and observations without coordinates are skipped.
+func BuildCoverageGrid(_ context.Context, args coverageGridArgs, pc *it.PersonalChecklist, personalSeen map[string]struct{}, recent []RecentObs) (coverageGrid, error) {
+	var out coverageGrid
+
+	var grid geo.Grid
+	switch {
+	case args.GeohashPrecision < 0 || args.GeohashPrecision > maxGeohashPrecision:
+		return out, fmt.Errorf("geohashPrecision must be 1-%d", maxGeohashPrecision)
+	case args.GeohashPrecision > 0:
+		grid = geo.GeohashGrid{Precision: args.GeohashPrecision}
+		out.Grid, out.GeohashPrecision = "geohash", args.GeohashPrecision
+	default:
+		if args.CellKm <= 0 {
+			args.CellKm = defaultCellKm
+		}
+		if args.CellKm < minGridCellKm {
+			args.CellKm = minGridCellKm
+		}
+		grid = geo.KmGrid{SizeKm: args.CellKm}
+		out.Grid, out.CellKm = "km", args.CellKm
+	}
+	if args.RadiusKm <= 0 {
+		args.RadiusKm = defaultGridRadiusKm
+	}
+	if args.MaxCells <= 0 {
+		args.MaxCells = defaultGridMaxCells
+	}
+	out.RadiusKm = args.RadiusKm
+
+	type tally struct {
+		cell       geo.Cell
+		species    map[string]bool
+		checklists map[string]bool
+		sightings  int
+	}
+	birded := map[string]*tally{}
+	var sumLat, sumLng float64
+	located := 0
+	for _, s := range pc.Sightings {
+		if (s.Lat == 0 && s.Lng == 0) || !geo.ValidLatLng(s.Lat, s.Lng) {
+			continue
+		}
+		located++
+		sumLat += s.Lat
+		sumLng += s.Lng
+		c := grid.CellOf(s.Lat, s.Lng)
+		t, ok := birded[c.ID]
+		if !ok {
+			t = &tally{cell: c, species: map[string]bool{}, checklists: map[string]bool{}}
+			birded[c.ID] = t
+		}
+		t.sightings++
+		t.species[s.SpeciesCode] = true
+		if s.ChecklistID != "" {
+			t.checklists[s.ChecklistID] = true
+		}
+	}
+
+	switch lat, lng, err := geo.ParseLatLng(args.Location); {
+	case err == nil:
+		out.CenterLat, out.CenterLng = lat, lng
+	case args.Location != "":
+		return out, fmt.Errorf("location must be \"lat,lng\": %w", err)
+	case located > 0:
+		out.CenterLat, out.CenterLng = sumLat/float64(located), sumLng/float64(located)
+	}
+
+	out.Cells = make([]GridCell, 0, len(birded))
+	for _, t := range birded {
+		out.Cells = append(out.Cells, gridCell(t.cell, len(t.species), len(t.checklists), t.sightings))
+	}
+	sort.Slice(out.Cells, func(i, j int) bool {
+		a, b := out.Cells[i], out.Cells[j]
+		if a.Checklists != b.Checklists {
+			return a.Checklists > b.Checklists
+		}
+		if a.Species != b.Species {
+			return a.Species > b.Species
+		}
+		return a.ID < b.ID
+	})
+	if len(out.Cells) > args.MaxCells {
+		out.Cells = out.Cells[:args.MaxCells]
+	}
+
+	opps := map[string]*OpportunityCell{}
+	oppSpecies := map[string]map[string]bool{}
+	for _, r := range recent {
+		if (r.Lat == 0 && r.Lng == 0) || !geo.ValidLatLng(r.Lat, r.Lng) || (r.HeardOnly && !args.IncludeHeardOnly) {
+			continue
+		}
+		if _, seen := personalSeen[r.SpeciesCode]; seen {
+			continue
+		}
+		c := grid.CellOf(r.Lat, r.Lng)
+		if t, ok := birded[c.ID]; ok && len(t.checklists) > 0 {
+			continue
+		}
+		lat, lng := c.Center()
+		d := geo.DistanceKm(out.CenterLat, out.CenterLng, lat, lng)
+		if located == 0 && args.Location == "" {
+			d = 0 // no center to measure from
+		} else if d > args.RadiusKm {
+			continue
+		}
+		o, ok := opps[c.ID]
+		if !ok {
+			o = &OpportunityCell{GridCell: gridCell(c, 0, 0, 0), DistanceKm: d}
+			opps[c.ID] = o
+			oppSpecies[c.ID] = map[string]bool{}
+		}
+		if !oppSpecies[c.ID][r.SpeciesCode] {
+			oppSpecies[c.ID][r.SpeciesCode] = true
+			o.LiferSpecies = append(o.LiferSpecies, r.SpeciesCode)
+		}
+	}
+	out.Unbirded = make([]OpportunityCell, 0, len(opps))
+	for _, o := range opps {
+		o.LiferCount = len(o.LiferSpecies)
+		sort.Strings(o.LiferSpecies)
+		out.Unbirded = append(out.Unbirded, *o)
+	}
+	sort.Slice(out.Unbirded, func(i, j int) bool {
+		a, b := out.Unbirded[i], out.Unbirded[j]
+		if a.LiferCount != b.LiferCount {
+			return a.LiferCount > b.LiferCount
+		}
+		if a.DistanceKm != b.DistanceKm {
+			return a.DistanceKm < b.DistanceKm
+		}
+		return a.ID < b.ID
+	})
+	if len(out.Unbirded) > args.MaxCells {
+		out.Unbirded = out.Unbirded[:args.MaxCells]
+	}
+	return out, nil
+}
+
+func gridCell(c geo.Cell, species, checklists, sightings int) GridCell {
+	lat, lng := c.Center()
+	return GridCell{
+		ID: c.ID, CenterLat: lat, CenterLng: lng,
+		MinLat: c.MinLat, MinLng: c.MinLng, MaxLat: c.MaxLat, MaxLng: c.MaxLng,
+		Species: species, Checklists: checklists, Sightings: sightings,
+	}
+}
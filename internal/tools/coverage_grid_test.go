@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	it "github.com/kpb/wingit-mcp/internal/types"
+)
+
+func Test_build_coverage_grid_finds_unbirded_lifer_cells(t *testing.T) {
+	t.Parallel()
+
+	pc := &it.PersonalChecklist{Sightings: []it.PersonalSighting{
+		{SpeciesCode: "clanut", Lat: 35.7770, Lng: -105.8106, ChecklistID: "S1"},
+		{SpeciesCode: "amgold", Lat: 35.7771, Lng: -105.8107, ChecklistID: "S1"},
+		{SpeciesCode: "amgold", Lat: 35.6848, Lng: -105.9532, ChecklistID: "S2"},
+		{SpeciesCode: "nocoords"},
+	}}
+	seen := map[string]struct{}{"clanut": {}, "amgold": {}}
+	recent := []RecentObs{
+		{SpeciesCode: "lewo", Lat: 35.7312, Lng: -105.8367},   // unbirded cell
+		{SpeciesCode: "pinsis", Lat: 35.7313, Lng: -105.8368}, // same cell
+		{SpeciesCode: "caltow", Lat: 35.7770, Lng: -105.8106}, // already birded cell
+		{SpeciesCode: "clanut", Lat: 35.6000, Lng: -106.0000}, // already seen
+		{SpeciesCode: "lewo", Lat: 40.0, Lng: -100.0},         // too far
+		{SpeciesCode: "gogeag", Lat: 35.73, Lng: -465.84},     // out of range
+		{SpeciesCode: "rethaw", Lat: 35.65, Lng: -105.95, HeardOnly: true},
+	}
+
+	got, err := BuildCoverageGrid(context.Background(), coverageGridArgs{CellKm: 2}, pc, seen, recent)
+	if err != nil {
+		t.Fatalf("BuildCoverageGrid: %v", err)
+	}
+	if len(got.Cells) != 2 || got.Cells[0].Checklists != 1 || got.Cells[0].Species != 2 {
+		t.Fatalf("cells = %+v", got.Cells)
+	}
+	if len(got.Unbirded) != 1 || got.Unbirded[0].LiferCount != 2 {
+		t.Fatalf("unbirded = %+v", got.Unbirded)
+	}
+	if got.Unbirded[0].LiferSpecies[0] != "lewo" || got.Unbirded[0].DistanceKm > 50 {
+		t.Fatalf("unbirded[0] = %+v", got.Unbirded[0])
+	}
+
+	gh, err := BuildCoverageGrid(context.Background(), coverageGridArgs{GeohashPrecision: 5, Location: "35.687,-105.938"}, pc, seen, recent)
+	if err != nil {
+		t.Fatalf("BuildCoverageGrid(geohash): %v", err)
+	}
+	if gh.Grid != "geohash" || len(gh.Cells[0].ID) != 5 {
+		t.Fatalf("geohash grid = %+v", gh)
+	}
+
+	if _, err := BuildCoverageGrid(context.Background(), coverageGridArgs{Location: "Santa Fe"}, pc, seen, recent); err == nil {
+		t.Fatalf("expected error for non-coordinate location")
+	}
+}
@@ -0,0 +1,205 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type groupArgs struct {
+	Location         string
+	Users            []string
+	IncludeHeardOnly bool
+	MaxSpecies       int `json:",omitempty"`
+}
+
+type GroupTargetRow struct {
+	SpeciesCode    string
+	CommonName     string
+	SciName        string
+	NeededBy       []string
+	NeededCount    int
+	LastSeenNearby string
+	Locations      []string
+}
+
+// GroupStop summarizes one recent-observation location for the group.
+type GroupStop struct {
+	LocID       string
+	LocName     string
+	Species     []string
+	HelpsUsers  []string
+	HelpedCount int
+}
+
+type groupResult struct {
+	Users []string
+	// Union: species at least one member needs (ranked, capped).
+	Targets []GroupTargetRow
+	// Intersection: species every member needs.
+	NeededByAll []string
+	Stops       []GroupStop
+}
+
+// Exported aliases for the MCP layer.
+type GroupArgs = groupArgs
+type GroupResult = groupResult
+
+// BuildGroupTargets ranks recent species by how many group members still need
+// them. seenByUser maps each member ID to that member's personal seen set.
+// Ranking: NeededCount (desc), then recency (desc), then input order.
+func BuildGroupTargets(_ context.Context, args groupArgs, seenByUser map[string]map[string]struct{}, recent []RecentObs) (groupResult, error) {
+	var out groupResult
+
+	if strings.TrimSpace(args.Location) == "" {
+		return out, fmt.Errorf("location is required")
+	}
+	if len(seenByUser) == 0 {
+		return out, fmt.Errorf("at least one user is required")
+	}
+	if args.MaxSpecies <= 0 {
+		args.MaxSpecies = defaultMaxSpecies
+	}
+
+	members := make([]string, 0, len(seenByUser))
+	for id := range seenByUser {
+		members = append(members, id)
+	}
+	sort.Strings(members)
+	out.Users = members
+
+	type row struct {
+		GroupTargetRow
+		obsTime time.Time
+		locs    map[string]bool
+	}
+	bySpecies := map[string]*row{}
+	order := []string{}
+
+	type stop struct {
+		GroupStop
+		species map[string]bool
+		users   map[string]bool
+	}
+	byLoc := map[string]*stop{}
+	locOrder := []string{}
+
+	for _, r := range recent {
+		if r.SpeciesCode == "" {
+			continue
+		}
+		if !args.IncludeHeardOnly && r.HeardOnly {
+			continue
+		}
+
+		var needers []string
+		for _, id := range members {
+			if _, seen := seenByUser[id][r.SpeciesCode]; !seen {
+				needers = append(needers, id)
+			}
+		}
+		if len(needers) == 0 {
+			continue
+		}
+
+		t, _ := time.Parse("2006-01-02", r.ObsDt)
+
+		sp, ok := bySpecies[r.SpeciesCode]
+		if !ok {
+			sp = &row{
+				GroupTargetRow: GroupTargetRow{
+					SpeciesCode: r.SpeciesCode,
+					CommonName:  r.CommonName,
+					SciName:     r.SciName,
+					NeededBy:    needers,
+					NeededCount: len(needers),
+				},
+				locs: map[string]bool{},
+			}
+			bySpecies[r.SpeciesCode] = sp
+			order = append(order, r.SpeciesCode)
+		}
+		if t.After(sp.obsTime) || sp.LastSeenNearby == "" {
+			sp.obsTime = t
+			sp.LastSeenNearby = r.ObsDt
+		}
+		loc := r.LocID
+		if loc == "" {
+			loc = r.LocName
+		}
+		if loc != "" && !sp.locs[loc] {
+			sp.locs[loc] = true
+			sp.Locations = append(sp.Locations, loc)
+		}
+
+		if loc == "" {
+			continue
+		}
+		st, ok := byLoc[loc]
+		if !ok {
+			st = &stop{
+				GroupStop: GroupStop{LocID: r.LocID, LocName: r.LocName},
+				species:   map[string]bool{},
+				users:     map[string]bool{},
+			}
+			byLoc[loc] = st
+			locOrder = append(locOrder, loc)
+		}
+		if !st.species[r.SpeciesCode] {
+			st.species[r.SpeciesCode] = true
+			st.Species = append(st.Species, r.SpeciesCode)
+		}
+		for _, id := range needers {
+			st.users[id] = true
+		}
+	}
+
+	rows := make([]*row, 0, len(order))
+	for _, code := range order {
+		rows = append(rows, bySpecies[code])
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].NeededCount != rows[j].NeededCount {
+			return rows[i].NeededCount > rows[j].NeededCount
+		}
+		return rows[i].obsTime.After(rows[j].obsTime)
+	})
+
+	out.NeededByAll = []string{}
+	for _, r := range rows {
+		if r.NeededCount == len(members) {
+			out.NeededByAll = append(out.NeededByAll, r.SpeciesCode)
+		}
+	}
+
+	limit := len(rows)
+	if limit > args.MaxSpecies {
+		limit = args.MaxSpecies
+	}
+	out.Targets = make([]GroupTargetRow, 0, limit)
+	for _, r := range rows[:limit] {
+		out.Targets = append(out.Targets, r.GroupTargetRow)
+	}
+
+	out.Stops = make([]GroupStop, 0, len(locOrder))
+	for _, loc := range locOrder {
+		st := byLoc[loc]
+		for _, id := range members {
+			if st.users[id] {
+				st.HelpsUsers = append(st.HelpsUsers, id)
+			}
+		}
+		st.HelpedCount = len(st.HelpsUsers)
+		out.Stops = append(out.Stops, st.GroupStop)
+	}
+	sort.SliceStable(out.Stops, func(i, j int) bool {
+		if out.Stops[i].HelpedCount != out.Stops[j].HelpedCount {
+			return out.Stops[i].HelpedCount > out.Stops[j].HelpedCount
+		}
+		return len(out.Stops[i].Species) > len(out.Stops[j].Species)
+	})
+
+	return out, nil
+}
@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func Test_build_group_targets_ranks_by_members_in_need(t *testing.T) {
+	t.Parallel()
+
+	seenByUser := map[string]map[string]struct{}{
+		"alice": {"clanut": {}},
+		"bob":   {"clanut": {}, "lewo": {}},
+		"carol": {},
+	}
+	recent := []RecentObs{
+		{SpeciesCode: "clanut", CommonName: "Clark's Nutcracker", LocID: "L1", LocName: "Aspen Vista", ObsDt: "2025-10-06"},
+		{SpeciesCode: "lewo", CommonName: "Lewis's Woodpecker", LocID: "L2", LocName: "Hyde Park Rd", ObsDt: "2025-10-06"},
+		{SpeciesCode: "pinsis", CommonName: "Pine Siskin", LocID: "L2", LocName: "Hyde Park Rd", ObsDt: "2025-10-04"},
+		{SpeciesCode: "caltow", CommonName: "Canyon Towhee", LocID: "L3", ObsDt: "2025-10-05", HeardOnly: true},
+	}
+
+	got, err := BuildGroupTargets(context.Background(), groupArgs{Location: "Santa Fe, NM"}, seenByUser, recent)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var codes []string
+	for _, r := range got.Targets {
+		codes = append(codes, r.SpeciesCode)
+	}
+	// pinsis needed by all 3, lewo by 2, clanut by 1; caltow dropped (heard only).
+	if want := []string{"pinsis", "lewo", "clanut"}; !reflect.DeepEqual(codes, want) {
+		t.Fatalf("targets = %v, want %v", codes, want)
+	}
+	if want := []string{"alice", "carol"}; !reflect.DeepEqual(got.Targets[1].NeededBy, want) {
+		t.Fatalf("lewo neededBy = %v, want %v", got.Targets[1].NeededBy, want)
+	}
+	if want := []string{"pinsis"}; !reflect.DeepEqual(got.NeededByAll, want) {
+		t.Fatalf("neededByAll = %v, want %v", got.NeededByAll, want)
+	}
+
+	// Hyde Park Rd helps everyone; Aspen Vista only carol.
+	if len(got.Stops) != 2 || got.Stops[0].LocID != "L2" || got.Stops[0].HelpedCount != 3 {
+		t.Fatalf("stops = %+v", got.Stops)
+	}
+}
+
+func Test_build_group_targets_requires_users(t *testing.T) {
+	t.Parallel()
+
+	if _, err := BuildGroupTargets(context.Background(), groupArgs{Location: "x"}, nil, nil); err == nil {
+		t.Fatalf("expected error with no users")
+	}
+}
@@ -0,0 +1,115 @@
+package tools
+
+import (
+	"sort"
+)
+
+type exportMapArgs struct {
+	Location         string
+	RadiusKm         float64 `json:",omitempty"`
+	DaysBack         int     `json:",omitempty"`
+	IncludeHeardOnly bool    `json:",omitempty"`
+	MaxSpecies       int     `json:",omitempty"`
+	Locale           string  `json:",omitempty"`
+	// MapFormat is gpx (default), kml or geojson.
+	MapFormat string `json:",omitempty"`
+	User      string `json:",omitempty"`
+}
+
+// Exported alias for the MCP layer.
+type ExportMapArgs = exportMapArgs
+
+// TargetArgs returns the target_checklist query behind a map export.
+func (a exportMapArgs) TargetArgs() targetArgs {
+	return targetArgs{
+		Location:         a.Location,
+		RadiusKm:         a.RadiusKm,
+		DaysBack:         a.DaysBack,
+		IncludeHeardOnly: a.IncludeHeardOnly,
+		MaxSpecies:       a.MaxSpecies,
+		Locale:           a.Locale,
+		User:             a.User,
+	}
+}
+
+// HotspotSpecies is a target species recently reported at a hotspot.
+type HotspotSpecies struct {
+	SpeciesCode string
+	CommonName  string
+	SciName     string
+	LastSeen    string
+}
+
+// Hotspot is a location where target species were recently reported.
+type Hotspot struct {
+	LocID   string
+	LocName string
+	Lat     float64
+	Lng     float64
+	Species []HotspotSpecies
+}
+
+// TargetHotspots collects the locations behind a target checklist: every
+// recent observation of a target species, grouped by location. Locations
+// without coordinates cannot be mapped and are counted in skipped.
+// Hotspots are ordered by number of target species (desc), then name.
+func TargetHotspots(res targetResult, recent []RecentObs) (hotspots []Hotspot, skipped int) {
+	targets := make(map[string]TargetRow, len(res.Targets))
+	for _, t := range res.Targets {
+		targets[t.SpeciesCode] = t
+	}
+
+	byLoc := map[string]*Hotspot{}
+	speciesAt := map[string]map[string]int{} // loc -> species -> index in Species
+	noCoords := map[string]bool{}
+	for _, r := range recent {
+		t, ok := targets[r.SpeciesCode]
+		if !ok || (r.HeardOnly && !res.Filters.IncludeHeardOnly) {
+			continue
+		}
+		key := r.LocID
+		if key == "" {
+			key = r.LocName
+		}
+		if r.Lat == 0 && r.Lng == 0 {
+			noCoords[key] = true
+			continue
+		}
+		h, ok := byLoc[key]
+		if !ok {
+			h = &Hotspot{LocID: r.LocID, LocName: r.LocName, Lat: r.Lat, Lng: r.Lng}
+			byLoc[key] = h
+			speciesAt[key] = map[string]int{}
+		}
+		if i, ok := speciesAt[key][r.SpeciesCode]; ok {
+			if r.ObsDt > h.Species[i].LastSeen {
+				h.Species[i].LastSeen = r.ObsDt
+			}
+			continue
+		}
+		speciesAt[key][r.SpeciesCode] = len(h.Species)
+		h.Species = append(h.Species, HotspotSpecies{
+			SpeciesCode: r.SpeciesCode,
+			CommonName:  t.CommonName, // may be localized
+			SciName:     r.SciName,
+			LastSeen:    r.ObsDt,
+		})
+	}
+
+	for key := range noCoords {
+		if _, mapped := byLoc[key]; !mapped {
+			skipped++
+		}
+	}
+	hotspots = make([]Hotspot, 0, len(byLoc))
+	for _, h := range byLoc {
+		hotspots = append(hotspots, *h)
+	}
+	sort.Slice(hotspots, func(i, j int) bool {
+		if len(hotspots[i].Species) != len(hotspots[j].Species) {
+			return len(hotspots[i].Species) > len(hotspots[j].Species)
+		}
+		return hotspots[i].LocName < hotspots[j].LocName
+	})
+	return hotspots, skipped
+}
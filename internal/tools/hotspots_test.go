@@ -0,0 +1,35 @@
+package tools
+
+import (
+	"testing"
+)
+
+func Test_target_hotspots_groups_lifers_by_location(t *testing.T) {
+	t.Parallel()
+
+	var res targetResult
+	res.Targets = []TargetRow{
+		{SpeciesCode: "lewo", CommonName: "Lewis's Woodpecker"},
+		{SpeciesCode: "pinsis", CommonName: "Pine Siskin"},
+	}
+	recent := []RecentObs{
+		{SpeciesCode: "lewo", LocID: "L1", LocName: "Hyde Park Rd", Lat: 35.73, Lng: -105.84, ObsDt: "2025-10-05"},
+		{SpeciesCode: "lewo", LocID: "L1", LocName: "Hyde Park Rd", Lat: 35.73, Lng: -105.84, ObsDt: "2025-10-06"},
+		{SpeciesCode: "pinsis", LocID: "L1", LocName: "Hyde Park Rd", Lat: 35.73, Lng: -105.84, ObsDt: "2025-10-04"},
+		{SpeciesCode: "pinsis", LocID: "L2", LocName: "River Trail", Lat: 35.68, Lng: -105.95, ObsDt: "2025-10-04"},
+		{SpeciesCode: "clanut", LocID: "L3", LocName: "Aspen Vista", Lat: 35.77, Lng: -105.81}, // not a target
+		{SpeciesCode: "lewo", LocID: "L4", LocName: "Somewhere"},                               // no coordinates
+		{SpeciesCode: "pinsis", LocID: "L5", Lat: 1, Lng: 1, HeardOnly: true},                  // heard only
+	}
+
+	got, skipped := TargetHotspots(res, recent)
+	if skipped != 1 {
+		t.Fatalf("skipped = %d, want 1", skipped)
+	}
+	if len(got) != 2 || got[0].LocID != "L1" || len(got[0].Species) != 2 {
+		t.Fatalf("hotspots = %+v", got)
+	}
+	if got[0].Species[0].SpeciesCode != "lewo" || got[0].Species[0].LastSeen != "2025-10-06" {
+		t.Fatalf("L1 species = %+v", got[0].Species)
+	}
+}
@@ -0,0 +1,203 @@
+package tools
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/kpb/wingit-mcp/internal/ebird"
+	"github.com/kpb/wingit-mcp/internal/taxonomy"
+	it "github.com/kpb/wingit-mcp/internal/types"
+)
+
+type lifeStatsArgs struct {
+	// Region restricts stats to an eBird region prefix such as "US-NM".
+	Region string `json:",omitempty"`
+	User   string `json:",omitempty"`
+}
+
+type RegionCount struct {
+	Region  string
+	Species int
+}
+
+type YearCount struct {
+	Year        string
+	SpeciesSeen int
+	Lifers      int
+	Cumulative  int
+}
+
+type GroupCount struct {
+	Name    string
+	Species int
+}
+
+type Milestone struct {
+	N           int
+	Date        string
+	SpeciesCode string
+	CommonName  string
+}
+
+type lifeStats struct {
+	Region       string
+	TotalSpecies int
+	ByCountry    []RegionCount
+	ByState      []RegionCount
+	ByCounty     []RegionCount
+	ByYear       []YearCount
+	ByOrder      []GroupCount
+	ByFamily     []GroupCount
+	// Unclassified counts species missing from the taxonomy.
+	Unclassified int
+	Milestones   []Milestone
+}
+
+// Exported aliases for the MCP layer.
+type LifeStatsArgs = lifeStatsArgs
+type LifeStats = lifeStats
+
+// BuildLifeStats derives life list statistics from the personal checklist.
+// Region, year and growth stats need dated, located Sightings; the
+// SpeciesIndex contributes to totals only when no Region is requested.
+// tax may be nil, in which case family/order stats are empty.
+func BuildLifeStats(_ context.Context, args lifeStatsArgs, pc *it.PersonalChecklist, tax *taxonomy.Taxonomy) (lifeStats, error) {
+	out := lifeStats{Region: strings.ToUpper(strings.TrimSpace(args.Region))}
+
+	sightings := pc.Sightings
+	var lifers []ebird.Lifer
+	if out.Region != "" {
+		sightings = sightingsInRegion(pc.Sightings, out.Region)
+		lifers = ebird.LifeListFromSightings(sightings)
+	} else {
+		lifers = ebird.LifeList(pc)
+	}
+	out.TotalSpecies = len(lifers)
+
+	countries, states, counties := map[string]map[string]bool{}, map[string]map[string]bool{}, map[string]map[string]bool{}
+	years := map[string]map[string]bool{}
+	for _, s := range sightings {
+		parts := strings.Split(s.CountyCode, "-")
+		if s.CountyCode != "" {
+			addTo(countries, parts[0], s.SpeciesCode)
+			if len(parts) >= 2 {
+				addTo(states, strings.Join(parts[:2], "-"), s.SpeciesCode)
+			}
+			if len(parts) >= 3 {
+				addTo(counties, s.CountyCode, s.SpeciesCode)
+			}
+		}
+		if len(s.ObsDt) >= 4 {
+			addTo(years, s.ObsDt[:4], s.SpeciesCode)
+		}
+	}
+	out.ByCountry = regionCounts(countries)
+	out.ByState = regionCounts(states)
+	out.ByCounty = regionCounts(counties)
+
+	// Yearly lifers and cumulative growth come from first-seen dates.
+	liferYears := map[string]int{}
+	for _, l := range lifers {
+		if len(l.FirstSeen) >= 4 {
+			liferYears[l.FirstSeen[:4]]++
+			if years[l.FirstSeen[:4]] == nil {
+				years[l.FirstSeen[:4]] = map[string]bool{}
+			}
+		}
+	}
+	yearKeys := make([]string, 0, len(years))
+	for y := range years {
+		yearKeys = append(yearKeys, y)
+	}
+	sort.Strings(yearKeys)
+	cum := 0
+	for _, y := range yearKeys {
+		cum += liferYears[y]
+		out.ByYear = append(out.ByYear, YearCount{Year: y, SpeciesSeen: len(years[y]), Lifers: liferYears[y], Cumulative: cum})
+	}
+
+	orders, families := map[string]map[string]bool{}, map[string]map[string]bool{}
+	for _, l := range lifers {
+		e, ok := tax.Lookup(l.SpeciesCode)
+		if !ok {
+			out.Unclassified++
+			continue
+		}
+		addTo(orders, e.Order, l.SpeciesCode)
+		addTo(families, e.FamilySciName+" ("+e.FamilyComName+")", l.SpeciesCode)
+	}
+	out.ByOrder = groupCounts(orders)
+	out.ByFamily = groupCounts(families)
+
+	for i, l := range lifers {
+		if n := i + 1; isMilestone(n) && l.FirstSeen != "" {
+			out.Milestones = append(out.Milestones, Milestone{N: n, Date: l.FirstSeen, SpeciesCode: l.SpeciesCode, CommonName: l.CommonName})
+		}
+	}
+
+	return out, nil
+}
+
+// sightingsInRegion keeps sightings whose CountyCode is region or lies within it.
+func sightingsInRegion(all []it.PersonalSighting, region string) []it.PersonalSighting {
+	var out []it.PersonalSighting
+	for _, s := range all {
+		cc := strings.ToUpper(s.CountyCode)
+		if cc == region || strings.HasPrefix(cc, region+"-") {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// isMilestone reports whether the nth species is worth calling out:
+// the first, every 100th up to 1000, then every 500th.
+func isMilestone(n int) bool {
+	switch {
+	case n == 1:
+		return true
+	case n <= 1000:
+		return n%100 == 0
+	default:
+		return n%500 == 0
+	}
+}
+
+func addTo(m map[string]map[string]bool, key, species string) {
+	if key == "" || species == "" {
+		return
+	}
+	if m[key] == nil {
+		m[key] = map[string]bool{}
+	}
+	m[key][species] = true
+}
+
+func regionCounts(m map[string]map[string]bool) []RegionCount {
+	out := make([]RegionCount, 0, len(m))
+	for k, v := range m {
+		out = append(out, RegionCount{Region: k, Species: len(v)})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Species != out[j].Species {
+			return out[i].Species > out[j].Species
+		}
+		return out[i].Region < out[j].Region
+	})
+	return out
+}
+
+func groupCounts(m map[string]map[string]bool) []GroupCount {
+	out := make([]GroupCount, 0, len(m))
+	for k, v := range m {
+		out = append(out, GroupCount{Name: k, Species: len(v)})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Species != out[j].Species {
+			return out[i].Species > out[j].Species
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
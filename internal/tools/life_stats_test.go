@@ -0,0 +1,61 @@
+package tools
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/kpb/wingit-mcp/internal/taxonomy"
+	it "github.com/kpb/wingit-mcp/internal/types"
+)
+
+func Test_build_life_stats_by_region_year_and_family(t *testing.T) {
+	t.Parallel()
+
+	tax, err := taxonomy.Load(filepath.Join("testdata", "taxonomy_example.json"))
+	if err != nil {
+		t.Fatalf("taxonomy.Load: %v", err)
+	}
+	pc := &it.PersonalChecklist{Sightings: []it.PersonalSighting{
+		{SpeciesCode: "amgold", CommonName: "American Goldfinch", ObsDt: "2023-06-10", CountyCode: "US-NM-049"},
+		{SpeciesCode: "pinsis", CommonName: "Pine Siskin", ObsDt: "2024-01-02", CountyCode: "US-NM-049"},
+		{SpeciesCode: "clanut", CommonName: "Clark's Nutcracker", ObsDt: "2024-07-04", CountyCode: "US-CO-031"},
+		{SpeciesCode: "amgold", CommonName: "American Goldfinch", ObsDt: "2024-07-05", CountyCode: "US-CO-031"},
+		{SpeciesCode: "mystery", ObsDt: "2024-08-01", CountyCode: "MX-SON"},
+	}}
+
+	got, err := BuildLifeStats(context.Background(), lifeStatsArgs{}, pc, tax)
+	if err != nil {
+		t.Fatalf("BuildLifeStats: %v", err)
+	}
+	if got.TotalSpecies != 4 || got.Unclassified != 1 {
+		t.Fatalf("total=%d unclassified=%d, want 4 and 1", got.TotalSpecies, got.Unclassified)
+	}
+	if got.ByCountry[0] != (RegionCount{Region: "US", Species: 3}) {
+		t.Fatalf("byCountry = %+v", got.ByCountry)
+	}
+	if len(got.ByState) != 3 || got.ByState[0].Species != 2 {
+		t.Fatalf("byState = %+v", got.ByState)
+	}
+	want := []YearCount{
+		{Year: "2023", SpeciesSeen: 1, Lifers: 1, Cumulative: 1},
+		{Year: "2024", SpeciesSeen: 4, Lifers: 3, Cumulative: 4},
+	}
+	if len(got.ByYear) != 2 || got.ByYear[0] != want[0] || got.ByYear[1] != want[1] {
+		t.Fatalf("byYear = %+v, want %+v", got.ByYear, want)
+	}
+	if got.ByFamily[0].Name != "Fringillidae (Finches, Euphonias, and Allies)" || got.ByFamily[0].Species != 2 {
+		t.Fatalf("byFamily = %+v", got.ByFamily)
+	}
+	if len(got.Milestones) != 1 || got.Milestones[0].SpeciesCode != "amgold" {
+		t.Fatalf("milestones = %+v", got.Milestones)
+	}
+
+	nm, err := BuildLifeStats(context.Background(), lifeStatsArgs{Region: "us-nm"}, pc, tax)
+	if err != nil {
+		t.Fatalf("BuildLifeStats(US-NM): %v", err)
+	}
+	if nm.TotalSpecies != 2 || len(nm.ByState) != 1 {
+		t.Fatalf("US-NM stats = %+v", nm)
+	}
+}
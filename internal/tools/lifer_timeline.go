@@ -0,0 +1,135 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kpb/wingit-mcp/internal/ebird"
+	it "github.com/kpb/wingit-mcp/internal/types"
+)
+
+type liferTimelineArgs struct {
+	// Year keeps only lifers first seen in that year.
+	Year int `json:",omitempty"`
+	// Region restricts to an eBird region prefix such as "US-NM"; lifers are
+	// then first sightings within the region.
+	Region string `json:",omitempty"`
+	// OnThisDay lists past-year sightings on Date's month and day instead.
+	OnThisDay bool `json:",omitempty"`
+	// Date is YYYY-MM-DD for OnThisDay; defaults to today.
+	Date string `json:",omitempty"`
+	User string `json:",omitempty"`
+}
+
+// OnThisDayYear groups sightings from one past year on the requested day.
+type OnThisDayYear struct {
+	Year      int
+	YearsAgo  int
+	Sightings []it.PersonalSighting
+	// Lifers lists species first seen on that exact date.
+	Lifers []string
+}
+
+type liferTimeline struct {
+	Lifers    []ebird.Lifer   `json:",omitempty"`
+	OnThisDay []OnThisDayYear `json:",omitempty"`
+	MonthDay  string          `json:",omitempty"`
+}
+
+// Exported aliases for the MCP layer.
+type LiferTimelineArgs = liferTimelineArgs
+type LiferTimeline = liferTimeline
+
+// BuildLiferTimeline returns the user's lifers in chronological order, or in
+// OnThisDay mode what they saw on today's date (per now) in past years.
+func BuildLiferTimeline(_ context.Context, args liferTimelineArgs, pc *it.PersonalChecklist, now time.Time) (liferTimeline, error) {
+	var out liferTimeline
+	region := strings.ToUpper(strings.TrimSpace(args.Region))
+
+	// Without a region the SpeciesIndex counts too: a species it dates
+	// before any sighting was not a lifer on that sighting's day.
+	sightings := pc.Sightings
+	var lifers []ebird.Lifer
+	if region != "" {
+		sightings = sightingsInRegion(sightings, region)
+		lifers = ebird.LifeListFromSightings(sightings)
+	} else {
+		lifers = ebird.LifeList(pc)
+	}
+
+	if args.OnThisDay {
+		day := now
+		if args.Date != "" {
+			d, err := time.Parse("2006-01-02", args.Date)
+			if err != nil {
+				return out, fmt.Errorf("date must be YYYY-MM-DD: %w", err)
+			}
+			day = d
+		}
+		out.MonthDay = day.Format("01-02")
+		out.OnThisDay = onThisDay(sightings, lifers, day)
+		return out, nil
+	}
+
+	out.Lifers = []ebird.Lifer{}
+	yearPrefix := ""
+	if args.Year > 0 {
+		yearPrefix = strconv.Itoa(args.Year) + "-"
+	}
+	for _, l := range lifers {
+		if yearPrefix != "" && !strings.HasPrefix(l.FirstSeen, yearPrefix) {
+			continue
+		}
+		out.Lifers = append(out.Lifers, l)
+	}
+	return out, nil
+}
+
+// dateOnly trims a "YYYY-MM-DD HH:MM" timestamp to its date.
+func dateOnly(s string) string {
+	if len(s) > len("2006-01-02") {
+		return s[:len("2006-01-02")]
+	}
+	return s
+}
+
+func onThisDay(sightings []it.PersonalSighting, lifers []ebird.Lifer, day time.Time) []OnThisDayYear {
+	monthDay := day.Format("-01-02")
+	firstSeen := make(map[string]string, len(lifers))
+	for _, l := range lifers {
+		firstSeen[l.SpeciesCode] = l.FirstSeen
+	}
+
+	byYear := map[int]*OnThisDayYear{}
+	for _, s := range sightings {
+		date := dateOnly(s.ObsDt)
+		if len(date) != len("2006-01-02") || !strings.HasSuffix(date, monthDay) {
+			continue
+		}
+		y, err := strconv.Atoi(s.ObsDt[:4])
+		if err != nil || y >= day.Year() {
+			continue
+		}
+		e, ok := byYear[y]
+		if !ok {
+			e = &OnThisDayYear{Year: y, YearsAgo: day.Year() - y, Lifers: []string{}}
+			byYear[y] = e
+		}
+		e.Sightings = append(e.Sightings, s)
+		if dateOnly(firstSeen[s.SpeciesCode]) == date && !slices.Contains(e.Lifers, s.SpeciesCode) {
+			e.Lifers = append(e.Lifers, s.SpeciesCode)
+		}
+	}
+
+	out := make([]OnThisDayYear, 0, len(byYear))
+	for _, e := range byYear {
+		out = append(out, *e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Year > out[j].Year })
+	return out
+}
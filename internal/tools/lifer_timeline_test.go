@@ -0,0 +1,122 @@
+package tools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	it "github.com/kpb/wingit-mcp/internal/types"
+)
+
+func Test_build_lifer_timeline_filters_and_on_this_day(t *testing.T) {
+	t.Parallel()
+
+	pc := &it.PersonalChecklist{Sightings: []it.PersonalSighting{
+		{SpeciesCode: "amgold", ObsDt: "2022-10-18", CountyCode: "US-NM-049", ChecklistID: "S1"},
+		{SpeciesCode: "pinsis", ObsDt: "2023-10-18", CountyCode: "US-CO-031", ChecklistID: "S2"},
+		{SpeciesCode: "amgold", ObsDt: "2023-10-18", CountyCode: "US-CO-031", ChecklistID: "S2"},
+		{SpeciesCode: "clanut", ObsDt: "2024-03-01", CountyCode: "US-NM-049", ChecklistID: "S3"},
+		{SpeciesCode: "lewo", ObsDt: "2026-10-18", CountyCode: "US-NM-049", ChecklistID: "S4"},
+	}}
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+
+	got, err := BuildLiferTimeline(context.Background(), liferTimelineArgs{}, pc, now)
+	if err != nil {
+		t.Fatalf("BuildLiferTimeline: %v", err)
+	}
+	if len(got.Lifers) != 4 || got.Lifers[0].SpeciesCode != "amgold" || got.Lifers[0].ChecklistID != "S1" {
+		t.Fatalf("lifers = %+v", got.Lifers)
+	}
+
+	got, _ = BuildLiferTimeline(context.Background(), liferTimelineArgs{Year: 2023}, pc, now)
+	if len(got.Lifers) != 1 || got.Lifers[0].SpeciesCode != "pinsis" {
+		t.Fatalf("2023 lifers = %+v", got.Lifers)
+	}
+
+	// amgold is a Colorado lifer in 2023 even though it was seen in NM first.
+	got, _ = BuildLiferTimeline(context.Background(), liferTimelineArgs{Region: "US-CO"}, pc, now)
+	if len(got.Lifers) != 2 {
+		t.Fatalf("US-CO lifers = %+v", got.Lifers)
+	}
+
+	got, err = BuildLiferTimeline(context.Background(), liferTimelineArgs{OnThisDay: true}, pc, now)
+	if err != nil {
+		t.Fatalf("BuildLiferTimeline(onThisDay): %v", err)
+	}
+	// Today's sighting is excluded; 2023 comes before 2022.
+	if len(got.OnThisDay) != 2 || got.OnThisDay[0].Year != 2023 || got.OnThisDay[0].YearsAgo != 3 {
+		t.Fatalf("onThisDay = %+v", got.OnThisDay)
+	}
+	if len(got.OnThisDay[0].Sightings) != 2 || len(got.OnThisDay[0].Lifers) != 1 || got.OnThisDay[0].Lifers[0] != "pinsis" {
+		t.Fatalf("2023 on this day = %+v", got.OnThisDay[0])
+	}
+
+	if _, err := BuildLiferTimeline(context.Background(), liferTimelineArgs{OnThisDay: true, Date: "10/18"}, pc, now); err == nil {
+		t.Fatalf("expected error for bad date")
+	}
+}
+
+func Test_on_this_day_lifers_respect_the_species_index(t *testing.T) {
+	t.Parallel()
+
+	// The index dates pinsis to 2015, so the 2023 sighting is not a lifer;
+	// amgold's index entry is later than its sighting and changes nothing.
+	pc := &it.PersonalChecklist{
+		Sightings: []it.PersonalSighting{
+			{SpeciesCode: "pinsis", ObsDt: "2023-10-18", CountyCode: "US-CO-031", ChecklistID: "S2"},
+			{SpeciesCode: "amgold", ObsDt: "2023-10-18", CountyCode: "US-CO-031", ChecklistID: "S2"},
+		},
+		SpeciesIndex: []it.SpeciesIndex{
+			{SpeciesCode: "pinsis", FirstSeen: "2015-06-01"},
+			{SpeciesCode: "amgold", FirstSeen: "2024-01-01"},
+		},
+	}
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+
+	got, err := BuildLiferTimeline(context.Background(), liferTimelineArgs{OnThisDay: true}, pc, now)
+	if err != nil {
+		t.Fatalf("BuildLiferTimeline: %v", err)
+	}
+	if len(got.OnThisDay) != 1 || len(got.OnThisDay[0].Sightings) != 2 {
+		t.Fatalf("onThisDay = %+v", got.OnThisDay)
+	}
+	if l := got.OnThisDay[0].Lifers; len(l) != 1 || l[0] != "amgold" {
+		t.Fatalf("lifers = %v, want [amgold]", l)
+	}
+
+	// Within a region only the region's sightings decide.
+	got, _ = BuildLiferTimeline(context.Background(), liferTimelineArgs{OnThisDay: true, Region: "US-CO"}, pc, now)
+	if l := got.OnThisDay[0].Lifers; len(l) != 2 {
+		t.Fatalf("US-CO lifers = %v, want both", l)
+	}
+}
+
+func Test_on_this_day_matches_timed_sightings(t *testing.T) {
+	t.Parallel()
+
+	// The index dates amgold without a time; the sightings carry times.
+	pc := &it.PersonalChecklist{
+		Sightings: []it.PersonalSighting{
+			{SpeciesCode: "pinsis", ObsDt: "2020-05-01 07:30", ChecklistID: "S1"},
+			{SpeciesCode: "pinsis", ObsDt: "2020-05-01 16:10", ChecklistID: "S2"},
+			{SpeciesCode: "amgold", ObsDt: "2020-05-01 16:10", ChecklistID: "S2"},
+			{SpeciesCode: "pinsis", ObsDt: "2021-05-01 08:00", ChecklistID: "S3"},
+		},
+		SpeciesIndex: []it.SpeciesIndex{{SpeciesCode: "amgold", FirstSeen: "2020-05-01"}},
+	}
+	now := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	got, err := BuildLiferTimeline(context.Background(), liferTimelineArgs{OnThisDay: true}, pc, now)
+	if err != nil {
+		t.Fatalf("BuildLiferTimeline: %v", err)
+	}
+	if len(got.OnThisDay) != 2 || got.OnThisDay[1].Year != 2020 || len(got.OnThisDay[1].Sightings) != 3 {
+		t.Fatalf("onThisDay = %+v", got.OnThisDay)
+	}
+	if l := got.OnThisDay[1].Lifers; len(l) != 2 || l[0] != "pinsis" || l[1] != "amgold" {
+		t.Errorf("2020 lifers = %v, want [pinsis amgold]", l)
+	}
+	if l := got.OnThisDay[0].Lifers; len(l) != 0 {
+		t.Errorf("2021 lifers = %v, want none", l)
+	}
+}
@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kpb/wingit-mcp/internal/ebird"
+	"github.com/kpb/wingit-mcp/internal/taxonomy"
+	it "github.com/kpb/wingit-mcp/internal/types"
+)
+
+type speciesInfoArgs struct {
+	// Query is a common name, scientific name, banding code or eBird code.
+	Query string
+	User  string `json:",omitempty"`
+}
+
+type speciesInfo struct {
+	Query   string
+	Species taxonomy.Entry
+	Score   float64
+	Seen    bool
+	// History is the user's record of the species, when seen.
+	History *ebird.SpeciesHistory `json:",omitempty"`
+	// Alternatives are other plausible matches for an ambiguous query.
+	Alternatives []taxonomy.Match
+}
+
+// Exported aliases for the MCP layer.
+type SpeciesInfoArgs = speciesInfoArgs
+type SpeciesInfo = speciesInfo
+
+const maxSpeciesAlternatives = 5
+
+// BuildSpeciesInfo resolves a species query against the taxonomy and attaches
+// the user's personal history. Without a taxonomy, the user's own species are
+// searched instead (family and order are then unknown).
+func BuildSpeciesInfo(_ context.Context, args speciesInfoArgs, pc *it.PersonalChecklist, tax *taxonomy.Taxonomy) (speciesInfo, error) {
+	if tax == nil {
+		tax = ebird.PersonalTaxonomy(pc)
+	}
+	out, err := ResolveSpeciesInfo(args, tax)
+	if err != nil {
+		return out, err
+	}
+	return out.WithHistory(pc), nil
+}
+
+// ResolveSpeciesInfo is BuildSpeciesInfo without the history, so callers
+// can fetch only the resolved species' sightings for WithHistory.
+func ResolveSpeciesInfo(args speciesInfoArgs, tax *taxonomy.Taxonomy) (speciesInfo, error) {
+	out := speciesInfo{Query: args.Query}
+	if strings.TrimSpace(args.Query) == "" {
+		return out, fmt.Errorf("query is required")
+	}
+
+	matches := tax.Resolve(args.Query, maxSpeciesAlternatives+1)
+	if len(matches) == 0 {
+		return out, fmt.Errorf("no species matches %q", args.Query)
+	}
+	best := matches[0]
+	out.Species, out.Score = best.Entry, best.Score
+	out.Alternatives = matches[1:]
+	return out, nil
+}
+
+// WithHistory attaches the user's history of the resolved species from pc,
+// which need hold only that species' sightings.
+func (out speciesInfo) WithHistory(pc *it.PersonalChecklist) speciesInfo {
+	if h, ok := ebird.FindSpeciesHistory(pc, out.Species.SpeciesCode); ok {
+		out.Seen = true
+		out.History = &h
+	}
+	return out
+}
@@ -0,0 +1,52 @@
+package tools
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/kpb/wingit-mcp/internal/ebird"
+	"github.com/kpb/wingit-mcp/internal/taxonomy"
+)
+
+func Test_build_species_info_with_taxonomy_and_history(t *testing.T) {
+	t.Parallel()
+
+	tax, err := taxonomy.Load(filepath.Join("testdata", "taxonomy_example.json"))
+	if err != nil {
+		t.Fatalf("taxonomy.Load: %v", err)
+	}
+	pc, err := ebird.LoadPersonalChecklist(filepath.Join("testdata", "personal_checklist_example.json"))
+	if err != nil {
+		t.Fatalf("LoadPersonalChecklist: %v", err)
+	}
+
+	got, err := BuildSpeciesInfo(context.Background(), speciesInfoArgs{Query: "Clarks Nutcraker"}, pc, tax)
+	if err != nil {
+		t.Fatalf("BuildSpeciesInfo: %v", err)
+	}
+	if got.Species.SpeciesCode != "clanut" || got.Species.FamilySciName != "Corvidae" {
+		t.Fatalf("species = %+v", got.Species)
+	}
+	if !got.Seen || got.History == nil || got.History.FirstSeen != "2018-05-01" {
+		t.Fatalf("history = %+v seen=%v", got.History, got.Seen)
+	}
+
+	got, err = BuildSpeciesInfo(context.Background(), speciesInfoArgs{Query: "HOSP"}, pc, tax)
+	if err != nil {
+		t.Fatalf("BuildSpeciesInfo(HOSP): %v", err)
+	}
+	if got.Species.SpeciesCode != "houspa" || got.Species.ExoticCategory != "N" || got.Seen {
+		t.Fatalf("HOSP = %+v", got)
+	}
+
+	// Without a taxonomy the user's own species still resolve.
+	got, err = BuildSpeciesInfo(context.Background(), speciesInfoArgs{Query: "goldfinch"}, pc, nil)
+	if err != nil || got.Species.SpeciesCode != "amgold" || !got.Seen {
+		t.Fatalf("fallback = %+v, %v", got, err)
+	}
+
+	if _, err := BuildSpeciesInfo(context.Background(), speciesInfoArgs{Query: "  "}, pc, tax); err == nil {
+		t.Fatalf("expected error for empty query")
+	}
+}
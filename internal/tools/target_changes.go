@@ -0,0 +1,199 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/kpb/wingit-mcp/internal/state"
+)
+
+const (
+	// TargetHistoryBucket is the state bucket holding target results.
+	TargetHistoryBucket = "targets"
+	// TargetHistoryKeep is how many results are kept per query: two weeks
+	// of daily runs.
+	TargetHistoryKeep = 14
+)
+
+// TargetSnapshot is one saved run of a target query: who asked, the
+// normalized arguments (enough to re-run it) and the result.
+type TargetSnapshot struct {
+	User   string
+	Args   TargetArgs
+	Result TargetResult
+}
+
+// FrequencyChange is a target whose recent frequency moved between runs.
+type FrequencyChange struct {
+	SpeciesCode string
+	CommonName  string
+	Previous    float64
+	Current     float64
+}
+
+// TargetChanges compares a target result with an earlier one of the same
+// query. Baseline is when the earlier result was saved (RFC3339); it is
+// empty when the query has no earlier result, and then nothing is listed.
+type TargetChanges struct {
+	Key              string
+	Baseline         string `json:",omitempty"`
+	New              []TargetRow
+	Gone             []TargetRow
+	FrequencyChanges []FrequencyChange
+	Unchanged        int
+}
+
+// Changed reports whether anything differs from the baseline.
+func (c TargetChanges) Changed() bool {
+	return len(c.New) > 0 || len(c.Gone) > 0 || len(c.FrequencyChanges) > 0
+}
+
+// Summary is a one-line description such as
+// "2 new targets, 1 no longer reported since 2025-10-20".
+func (c TargetChanges) Summary() string {
+	if c.Baseline == "" {
+		return "no earlier result for this query"
+	}
+	since := c.Baseline
+	if t, err := time.Parse(time.RFC3339, c.Baseline); err == nil {
+		since = t.Local().Format("2006-01-02 15:04")
+	}
+	if !c.Changed() {
+		return "no changes since " + since
+	}
+	var parts []string
+	if n := len(c.New); n > 0 {
+		parts = append(parts, plural(n, "new target", "new targets"))
+	}
+	if n := len(c.Gone); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d no longer reported", n))
+	}
+	if n := len(c.FrequencyChanges); n > 0 {
+		parts = append(parts, plural(n, "frequency change", "frequency changes"))
+	}
+	return strings.Join(parts, ", ") + " since " + since
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return "1 " + one
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
+
+// TargetQueryKey identifies a target query across runs: the user plus the
+// normalized arguments that choose targets. Presentation arguments (locale,
+// sort order, format) don't change which species are targets and are left
+// out, as is since.
+func TargetQueryKey(user string, args TargetArgs) string {
+	a := normalizeArgs(args)
+	return fmt.Sprintf("%s|%s|r=%g|d=%d|heard=%t|minf=%g|max=%d",
+		user, strings.ToLower(strings.TrimSpace(a.Location)),
+		a.RadiusKm, a.DaysBack, a.IncludeHeardOnly, a.MinFrequency, a.MaxSpecies)
+}
+
+// DiffTargets compares cur with prev by species code. New targets keep
+// cur's order and gone ones prev's.
+func DiffTargets(prev, cur TargetResult) TargetChanges {
+	c := TargetChanges{New: []TargetRow{}, Gone: []TargetRow{}, FrequencyChanges: []FrequencyChange{}}
+	before := make(map[string]TargetRow, len(prev.Targets))
+	for _, t := range prev.Targets {
+		before[t.SpeciesCode] = t
+	}
+	now := make(map[string]bool, len(cur.Targets))
+	for _, t := range cur.Targets {
+		now[t.SpeciesCode] = true
+		p, ok := before[t.SpeciesCode]
+		switch {
+		case !ok:
+			c.New = append(c.New, t)
+		case math.Abs(p.RecentFrequency-t.RecentFrequency) > 1e-9:
+			c.FrequencyChanges = append(c.FrequencyChanges, FrequencyChange{
+				SpeciesCode: t.SpeciesCode, CommonName: t.CommonName,
+				Previous: p.RecentFrequency, Current: t.RecentFrequency,
+			})
+		default:
+			c.Unchanged++
+		}
+	}
+	for _, t := range prev.Targets {
+		if !now[t.SpeciesCode] {
+			c.Gone = append(c.Gone, t)
+		}
+	}
+	return c
+}
+
+// parseSince turns a since argument into the latest acceptable save time:
+// "last" (the newest result, returned as the zero time), a date (any result
+// saved that day or earlier) or an RFC3339 time.
+func parseSince(since string, now time.Time) (time.Time, error) {
+	since = strings.TrimSpace(since)
+	if strings.EqualFold(since, "last") {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseInLocation("2006-01-02", since, now.Location()); err == nil {
+		return d.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+	if t, err := time.Parse(time.RFC3339, since); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("since %q: want \"last\", a date (YYYY-MM-DD) or an RFC3339 time", since)
+}
+
+// TargetBaseline loads the saved result of key to compare against: the
+// newest one saved at or before since, or the oldest kept when all are newer.
+// ok is false when the query has never been saved.
+func TargetBaseline(st *state.Store, key, since string, now time.Time) (snap TargetSnapshot, savedAt time.Time, ok bool, err error) {
+	cutoff, err := parseSince(since, now)
+	if err != nil {
+		return snap, savedAt, false, err
+	}
+	snaps, err := st.Snapshots(TargetHistoryBucket, key)
+	if err != nil || len(snaps) == 0 {
+		return snap, savedAt, false, err
+	}
+	pick := snaps[len(snaps)-1]
+	for _, s := range snaps { // newest first
+		if cutoff.IsZero() || !s.SavedAt.After(cutoff) {
+			pick = s
+			break
+		}
+	}
+	if err := json.Unmarshal(pick.Data, &snap); err != nil {
+		return snap, savedAt, false, fmt.Errorf("decode saved targets %s: %w", key, err)
+	}
+	return snap, pick.SavedAt, true, nil
+}
+
+// RecordTargets saves res as the newest result of its query. When since is
+// set it first diffs res against the baseline that since selects and
+// returns the changes; otherwise it returns nil.
+func RecordTargets(st *state.Store, user string, args TargetArgs, res TargetResult, now time.Time) (*TargetChanges, error) {
+	key := TargetQueryKey(user, args)
+	var changes *TargetChanges
+	if args.Since != "" {
+		prev, savedAt, ok, err := TargetBaseline(st, key, args.Since, now)
+		if err != nil {
+			return nil, err
+		}
+		c := TargetChanges{Key: key, New: []TargetRow{}, Gone: []TargetRow{}, FrequencyChanges: []FrequencyChange{}}
+		if ok {
+			c = DiffTargets(prev.Result, res)
+			c.Key, c.Baseline = key, savedAt.Format(time.RFC3339)
+		}
+		changes = &c
+	}
+
+	saved := args
+	saved.Since, saved.Format, saved.Profile = "", "", ""
+	res.Changes = nil
+	snap := TargetSnapshot{User: user, Args: normalizeArgs(saved), Result: res}
+	if err := st.Save(TargetHistoryBucket, key, now, snap, TargetHistoryKeep); err != nil {
+		return changes, err
+	}
+	return changes, nil
+}
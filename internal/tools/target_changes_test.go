@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kpb/wingit-mcp/internal/state"
+)
+
+func Test_diff_targets_new_gone_and_frequency(t *testing.T) {
+	t.Parallel()
+
+	prev := targetResult{Targets: []TargetRow{
+		{SpeciesCode: "lewo", RecentFrequency: 0.2},
+		{SpeciesCode: "pinsis", RecentFrequency: 0.2},
+		{SpeciesCode: "caltow", RecentFrequency: 0.1},
+	}}
+	cur := targetResult{Targets: []TargetRow{
+		{SpeciesCode: "rethaw", RecentFrequency: 0.3},
+		{SpeciesCode: "lewo", RecentFrequency: 0.2},
+		{SpeciesCode: "caltow", RecentFrequency: 0.25},
+	}}
+	c := DiffTargets(prev, cur)
+	if len(c.New) != 1 || c.New[0].SpeciesCode != "rethaw" {
+		t.Errorf("unexpected new targets: %+v", c.New)
+	}
+	if len(c.Gone) != 1 || c.Gone[0].SpeciesCode != "pinsis" {
+		t.Errorf("unexpected gone targets: %+v", c.Gone)
+	}
+	if len(c.FrequencyChanges) != 1 || c.FrequencyChanges[0].Previous != 0.1 || c.FrequencyChanges[0].Current != 0.25 {
+		t.Errorf("unexpected frequency changes: %+v", c.FrequencyChanges)
+	}
+	if c.Unchanged != 1 {
+		t.Errorf("expected 1 unchanged target, got %d", c.Unchanged)
+	}
+}
+
+func Test_target_query_key_ignores_presentation(t *testing.T) {
+	t.Parallel()
+
+	a := TargetQueryKey("me", TargetArgs{Location: "Albuquerque, NM"})
+	b := TargetQueryKey("me", TargetArgs{Location: " albuquerque, nm", RadiusKm: defaultRadiusKm, SortOrder: SortTaxonomic, Format: "md", Since: "last"})
+	if a != b {
+		t.Errorf("expected equal keys, got %q and %q", a, b)
+	}
+	if a == TargetQueryKey("me", TargetArgs{Location: "Albuquerque, NM", RadiusKm: 50}) {
+		t.Errorf("radius should change the key")
+	}
+	if a == TargetQueryKey("you", TargetArgs{Location: "Albuquerque, NM"}) {
+		t.Errorf("user should change the key")
+	}
+}
+
+func Test_record_targets_diffs_against_saved_runs(t *testing.T) {
+	t.Parallel()
+
+	st, err := state.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("state.Open: %v", err)
+	}
+	args := TargetArgs{Location: "Albuquerque, NM"}
+	run := func(at time.Time, since string, codes ...string) *TargetChanges {
+		t.Helper()
+		res := targetResult{}
+		for _, c := range codes {
+			res.Targets = append(res.Targets, TargetRow{SpeciesCode: c, RecentFrequency: 0.2})
+		}
+		a := args
+		a.Since = since
+		changes, err := RecordTargets(st, "me", a, res, at)
+		if err != nil {
+			t.Fatalf("RecordTargets: %v", err)
+		}
+		return changes
+	}
+
+	day1 := time.Date(2025, 10, 20, 7, 0, 0, 0, time.UTC)
+	if c := run(day1, "last", "lewo"); c == nil || c.Baseline != "" || c.Changed() {
+		t.Fatalf("first run should have no baseline, got %+v", c)
+	}
+	if c := run(day1.AddDate(0, 0, 1), "", "lewo", "pinsis"); c != nil {
+		t.Fatalf("no since should return no changes, got %+v", c)
+	}
+
+	day3 := day1.AddDate(0, 0, 2)
+	c := run(day3, "last", "pinsis")
+	if len(c.New) != 0 || len(c.Gone) != 1 || c.Gone[0].SpeciesCode != "lewo" {
+		t.Errorf("since last: unexpected changes %+v", c)
+	}
+	c = run(day3.Add(time.Hour), "2025-10-20", "pinsis", "rethaw")
+	if c.Baseline != day1.Format(time.RFC3339) || len(c.New) != 2 || len(c.Gone) != 1 {
+		t.Errorf("since a date: unexpected changes %+v", c)
+	}
+
+	if _, err := RecordTargets(st, "me", TargetArgs{Location: "x", Since: "yesterday"}, targetResult{}, day3); err == nil {
+		t.Errorf("expected an error for a bad since")
+	}
+}
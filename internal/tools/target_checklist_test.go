@@ -111,3 +111,41 @@ func Test_build_target_checklist_rejects_empty_location(t *testing.T) {
 		t.Fatalf("expected error for empty location, got nil")
 	}
 }
+
+func Test_localize_targets_keeps_english_name(t *testing.T) {
+	t.Parallel()
+
+	res := targetResult{Targets: []TargetRow{
+		{SpeciesCode: "lewo", CommonName: "Lewis's Woodpecker", SciName: "Melanerpes lewis"},
+		{SpeciesCode: "unknown", CommonName: "Mystery Bird"},
+	}}
+	names := func(locale, code string) (string, bool) {
+		if locale == "es" && code == "lewo" {
+			return "Carpintero de Lewis", true
+		}
+		return "", false
+	}
+
+	got := LocalizeTargets(res, "es", names)
+	want := []TargetRow{
+		{SpeciesCode: "lewo", CommonName: "Carpintero de Lewis", SciName: "Melanerpes lewis", EnglishName: "Lewis's Woodpecker"},
+		{SpeciesCode: "unknown", CommonName: "Mystery Bird"},
+	}
+	if !reflect.DeepEqual(got.Targets, want) {
+		t.Fatalf("targets mismatch\n got: %#v\nwant: %#v", got.Targets, want)
+	}
+	if got.Filters.Locale != "es" || res.Targets[0].CommonName != "Lewis's Woodpecker" {
+		t.Fatalf("locale not echoed or input mutated: %#v", got.Filters)
+	}
+}
+
+func Test_target_defaults_fill_only_unset_fields(t *testing.T) {
+	t.Parallel()
+
+	d := TargetDefaults{RadiusKm: 5, DaysBack: 3, MaxSpecies: 10}
+	got := d.Apply(TargetArgs{Location: "here", DaysBack: 14})
+	want := TargetArgs{Location: "here", RadiusKm: 5, DaysBack: 14, MaxSpecies: 10}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Apply = %+v, want %+v", got, want)
+	}
+}
@@ -9,12 +9,30 @@ import (
 )
 
 type targetArgs struct {
-	Location         string
-	RadiusKm         float64
-	DaysBack         int
-	IncludeHeardOnly bool
-	MinFrequency     float64
-	MaxSpecies       int
+	// Location is required unless Profile supplies it.
+	Location         string  `json:",omitempty"`
+	RadiusKm         float64 `json:",omitempty"`
+	DaysBack         int     `json:",omitempty"`
+	IncludeHeardOnly bool    `json:",omitempty"`
+	// ExcludeHeardOnly turns IncludeHeardOnly off for one call, whether it
+	// came from a preset or was set alongside it.
+	ExcludeHeardOnly bool    `json:",omitempty"`
+	MinFrequency     float64 `json:",omitempty"`
+	MaxSpecies       int     `json:",omitempty"`
+	// Locale (eBird locale code, e.g. "es") selects localized common names.
+	Locale string `json:",omitempty"`
+	// SortOrder is frequency (default), taxonomic, alphabetical or family-grouped.
+	SortOrder string `json:",omitempty"`
+	// Format adds a rendered checklist to the result: md, csv, html or geojson.
+	Format string `json:",omitempty"`
+	// User selects a profile on multi-user servers; the engine ignores it.
+	User string `json:",omitempty"`
+	// Since ("last", a date or an RFC3339 time) adds Changes: a diff against
+	// the saved result of the same query from then. See RecordTargets.
+	Since string `json:",omitempty"`
+	// Profile names a saved preset whose values fill the fields left unset
+	// here. See ApplyProfile.
+	Profile string `json:",omitempty"`
 }
 
 type RecentObs struct {
@@ -23,6 +41,8 @@ type RecentObs struct {
 	SciName     string
 	LocName     string
 	LocID       string
+	Lat         float64
+	Lng         float64
 	ObsDt       string
 	HeardOnly   bool
 }
@@ -33,6 +53,10 @@ type TargetRow struct {
 	SciName         string
 	RecentFrequency float64
 	LastSeenNearby  string
+	// EnglishName keeps the original name when CommonName is localized.
+	EnglishName string `json:",omitempty"`
+	// Family is the family's common name, set by taxonomic sort orders.
+	Family string `json:",omitempty"`
 }
 
 type targetResult struct {
@@ -44,8 +68,14 @@ type targetResult struct {
 		IncludeHeardOnly bool
 		MinFrequency     float64
 		MaxSpecies       int
+		Locale           string `json:",omitempty"`
+		SortOrder        string `json:",omitempty"`
 	}
 	ExcludedBecauseAlreadySeen int
+	// Families gives family headings for taxonomic and family-grouped orders.
+	Families []FamilyGroup `json:",omitempty"`
+	// Changes is set when the query asked for a diff with since.
+	Changes *TargetChanges `json:",omitempty"`
 }
 
 // Exported aliases so other packages (cmd/wingit-mcp) can use engine types.
@@ -59,6 +89,33 @@ const (
 	defaultMaxSpecies = 40
 )
 
+// TargetDefaults fill the query fields a call leaves unset. The engine
+// falls back to BuiltinTargetDefaults; servers apply configured ones first.
+type TargetDefaults struct {
+	RadiusKm   float64
+	DaysBack   int
+	MaxSpecies int
+}
+
+// BuiltinTargetDefaults returns the engine's own defaults.
+func BuiltinTargetDefaults() TargetDefaults {
+	return TargetDefaults{RadiusKm: defaultRadiusKm, DaysBack: defaultDaysBack, MaxSpecies: defaultMaxSpecies}
+}
+
+// Apply fills a's unset radius, days back and species cap from d.
+func (d TargetDefaults) Apply(a TargetArgs) TargetArgs {
+	if a.RadiusKm <= 0 {
+		a.RadiusKm = d.RadiusKm
+	}
+	if a.DaysBack <= 0 {
+		a.DaysBack = d.DaysBack
+	}
+	if a.MaxSpecies <= 0 {
+		a.MaxSpecies = d.MaxSpecies
+	}
+	return a
+}
+
 // BuildTargetChecklist is the pure engine the MCP tool will call.
 // This minimal implementation passes the tests and is a sane starting point.
 // Ranking: by RecentFrequency (desc), then by recency (ObsDt desc), then stable.
@@ -79,6 +136,8 @@ func BuildTargetChecklist(_ context.Context, args targetArgs, personalSeen map[s
 	out.Filters.IncludeHeardOnly = args.IncludeHeardOnly
 	out.Filters.MinFrequency = args.MinFrequency
 	out.Filters.MaxSpecies = args.MaxSpecies
+	out.Filters.Locale = args.Locale
+	out.Filters.SortOrder = args.SortOrder
 
 	type row struct {
 		TargetRow
@@ -142,17 +201,29 @@ func BuildTargetChecklist(_ context.Context, args targetArgs, personalSeen map[s
 	return out, nil
 }
 
-// normalizeArgs clamps obviously bad numeric values to sane defaults.
-func normalizeArgs(a targetArgs) targetArgs {
-	if a.RadiusKm <= 0 {
-		a.RadiusKm = defaultRadiusKm
-	}
-	if a.DaysBack <= 0 {
-		a.DaysBack = defaultDaysBack
-	}
-	if a.MaxSpecies <= 0 {
-		a.MaxSpecies = defaultMaxSpecies
+// LocalizeTargets swaps each target's CommonName for its name in locale via
+// name, keeping the original in EnglishName. Species without a localized
+// name are left as they are.
+func LocalizeTargets(res targetResult, locale string, name func(locale, code string) (string, bool)) targetResult {
+	out := res
+	out.Targets = make([]TargetRow, len(res.Targets))
+	for i, t := range res.Targets {
+		if local, ok := name(locale, t.SpeciesCode); ok && local != t.CommonName {
+			t.EnglishName = t.CommonName
+			t.CommonName = local
+		}
+		out.Targets[i] = t
 	}
+	out.Filters.Locale = locale
+	return out
+}
+
+// normalizeArgs clamps obviously bad numeric values to sane defaults and
+// folds ExcludeHeardOnly into IncludeHeardOnly.
+func normalizeArgs(a targetArgs) targetArgs {
+	a = BuiltinTargetDefaults().Apply(a)
+	a.IncludeHeardOnly = a.IncludeHeardOnly && !a.ExcludeHeardOnly
+	a.ExcludeHeardOnly = false
 	if a.MinFrequency < 0 {
 		a.MinFrequency = 0
 	}
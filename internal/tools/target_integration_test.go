@@ -35,6 +35,8 @@ func Test_integration_target_checklist_with_fixtures(t *testing.T) {
 			SciName:     r.SciName,
 			LocName:     r.LocName,
 			LocID:       r.LocID,
+			Lat:         r.Lat,
+			Lng:         r.Lng,
 			ObsDt:       r.ObsDt,
 			HeardOnly:   r.HeardOnly,
 		})
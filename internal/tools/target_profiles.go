@@ -0,0 +1,223 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kpb/wingit-mcp/internal/state"
+)
+
+// TargetProfile is a named target query preset such as "home patch".
+type TargetProfile struct {
+	Name string
+	Args TargetArgs
+}
+
+// TargetProfileArgs creates or replaces a preset. The query fields mirror
+// TargetArgs; Format, Since and Profile are per-call and not saved.
+type TargetProfileArgs struct {
+	Name             string
+	Location         string  `json:",omitempty"`
+	RadiusKm         float64 `json:",omitempty"`
+	DaysBack         int     `json:",omitempty"`
+	IncludeHeardOnly bool    `json:",omitempty"`
+	MinFrequency     float64 `json:",omitempty"`
+	MaxSpecies       int     `json:",omitempty"`
+	Locale           string  `json:",omitempty"`
+	SortOrder        string  `json:",omitempty"`
+	User             string  `json:",omitempty"`
+}
+
+// TargetArgs returns the query a preset saves.
+func (a TargetProfileArgs) TargetArgs() TargetArgs {
+	return TargetArgs{
+		Location: a.Location, RadiusKm: a.RadiusKm, DaysBack: a.DaysBack,
+		IncludeHeardOnly: a.IncludeHeardOnly, MinFrequency: a.MinFrequency, MaxSpecies: a.MaxSpecies,
+		Locale: a.Locale, SortOrder: a.SortOrder,
+	}
+}
+
+// TargetProfiles keeps each user's presets in one JSON file, keyed by user
+// ID then preset name, so it can be edited by hand. A nil *TargetProfiles
+// has no presets and cannot save any.
+type TargetProfiles struct {
+	path string
+	mu   sync.Mutex
+}
+
+// OpenTargetProfiles returns the presets stored at path; the file is
+// created on the first Put.
+func OpenTargetProfiles(path string) *TargetProfiles {
+	return &TargetProfiles{path: path}
+}
+
+// Path is the presets file.
+func (p *TargetProfiles) Path() string {
+	if p == nil {
+		return ""
+	}
+	return p.path
+}
+
+// Get returns a user's preset by name (case-insensitive).
+func (p *TargetProfiles) Get(user, name string) (TargetArgs, error) {
+	all, err := p.read()
+	if err != nil {
+		return TargetArgs{}, err
+	}
+	mine := all[user]
+	if args, ok := mine[normalizeProfileName(name)]; ok {
+		return args, nil
+	}
+	names := make([]string, 0, len(mine))
+	for n := range mine {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return TargetArgs{}, fmt.Errorf("unknown target profile %q (saved: %v)", name, names)
+}
+
+// List returns a user's presets sorted by name.
+func (p *TargetProfiles) List(user string) ([]TargetProfile, error) {
+	all, err := p.read()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]TargetProfile, 0, len(all[user]))
+	for name, args := range all[user] {
+		out = append(out, TargetProfile{Name: name, Args: args})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// Put saves args as a user's preset, replacing any of the same name. The
+// location is required so a preset can always run on its own.
+func (p *TargetProfiles) Put(user, name string, args TargetArgs) error {
+	if p == nil {
+		return fmt.Errorf("no config directory for target profiles")
+	}
+	key := normalizeProfileName(name)
+	if key == "" {
+		return fmt.Errorf("profile name is required")
+	}
+	if strings.TrimSpace(args.Location) == "" {
+		return fmt.Errorf("location is required")
+	}
+	args.Format, args.Since, args.User, args.Profile = "", "", "", ""
+	args.ExcludeHeardOnly = false
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	all, err := p.readLocked()
+	if err != nil {
+		return err
+	}
+	if all[user] == nil {
+		all[user] = map[string]TargetArgs{}
+	}
+	all[user][key] = args
+	return p.writeLocked(all)
+}
+
+// Delete removes a user's preset, reporting whether it existed.
+func (p *TargetProfiles) Delete(user, name string) (bool, error) {
+	if p == nil {
+		return false, nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	all, err := p.readLocked()
+	if err != nil {
+		return false, err
+	}
+	key := normalizeProfileName(name)
+	if _, ok := all[user][key]; !ok {
+		return false, nil
+	}
+	delete(all[user], key)
+	if len(all[user]) == 0 {
+		delete(all, user)
+	}
+	return true, p.writeLocked(all)
+}
+
+func (p *TargetProfiles) read() (map[string]map[string]TargetArgs, error) {
+	if p == nil {
+		return map[string]map[string]TargetArgs{}, nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.readLocked()
+}
+
+func (p *TargetProfiles) readLocked() (map[string]map[string]TargetArgs, error) {
+	all := map[string]map[string]TargetArgs{}
+	b, err := os.ReadFile(p.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return all, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read target profiles: %w", err)
+	}
+	if err := json.Unmarshal(b, &all); err != nil {
+		return nil, fmt.Errorf("decode target profiles %s: %w", p.path, err)
+	}
+	return all, nil
+}
+
+func (p *TargetProfiles) writeLocked(all map[string]map[string]TargetArgs) error {
+	b, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode target profiles: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(p.path), 0o755); err != nil {
+		return fmt.Errorf("write target profiles: %w", err)
+	}
+	if err := state.WriteFile(p.path, b); err != nil {
+		return fmt.Errorf("write target profiles: %w", err)
+	}
+	return nil
+}
+
+func normalizeProfileName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// ApplyProfile fills the fields a call leaves at their zero value from a
+// saved preset, so "profile": "home patch", "daysBack": 3 runs the preset
+// over three days. A call turns a preset's includeHeardOnly on with
+// includeHeardOnly and off with excludeHeardOnly.
+func ApplyProfile(preset, call TargetArgs) TargetArgs {
+	out := call
+	if out.Location == "" {
+		out.Location = preset.Location
+	}
+	if out.RadiusKm == 0 {
+		out.RadiusKm = preset.RadiusKm
+	}
+	if out.DaysBack == 0 {
+		out.DaysBack = preset.DaysBack
+	}
+	out.IncludeHeardOnly = !out.ExcludeHeardOnly && (out.IncludeHeardOnly || preset.IncludeHeardOnly)
+	out.ExcludeHeardOnly = false
+	if out.MinFrequency == 0 {
+		out.MinFrequency = preset.MinFrequency
+	}
+	if out.MaxSpecies == 0 {
+		out.MaxSpecies = preset.MaxSpecies
+	}
+	if out.Locale == "" {
+		out.Locale = preset.Locale
+	}
+	if out.SortOrder == "" {
+		out.SortOrder = preset.SortOrder
+	}
+	return out
+}
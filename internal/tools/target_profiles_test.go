@@ -0,0 +1,122 @@
+package tools
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func Test_target_profiles_put_get_list_delete(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "config", "profiles.json")
+	p := OpenTargetProfiles(path)
+	home := TargetArgs{Location: "Albuquerque, NM", RadiusKm: 10, DaysBack: 3, Format: "md", Since: "last"}
+	if err := p.Put("me", "Home  Patch", home); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := p.Put("me", "bosque weekend", TargetArgs{Location: "35.1,-106.7", IncludeHeardOnly: true}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := p.Put("me", "nowhere", TargetArgs{}); err == nil {
+		t.Errorf("expected an error for a preset without a location")
+	}
+
+	// A reopened file sees the same presets; names are case-insensitive.
+	p = OpenTargetProfiles(path)
+	got, err := p.Get("me", "home patch")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Location != home.Location || got.RadiusKm != 10 || got.Format != "" || got.Since != "" {
+		t.Errorf("unexpected preset: %+v", got)
+	}
+	if _, err := p.Get("you", "home patch"); err == nil {
+		t.Errorf("presets must be per user")
+	}
+
+	list, err := p.List("me")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(list) != 2 || list[0].Name != "bosque weekend" || list[1].Name != "home patch" {
+		t.Errorf("unexpected list: %+v", list)
+	}
+
+	if ok, err := p.Delete("me", "Bosque Weekend"); err != nil || !ok {
+		t.Fatalf("Delete = %v, %v", ok, err)
+	}
+	if ok, _ := p.Delete("me", "bosque weekend"); ok {
+		t.Errorf("second Delete should report a missing preset")
+	}
+	if list, _ := p.List("me"); len(list) != 1 {
+		t.Errorf("expected 1 preset left, got %+v", list)
+	}
+}
+
+func Test_apply_profile_call_overrides_preset(t *testing.T) {
+	t.Parallel()
+
+	preset := TargetArgs{Location: "Albuquerque, NM", RadiusKm: 10, DaysBack: 14, MaxSpecies: 20, SortOrder: SortTaxonomic}
+	got := ApplyProfile(preset, TargetArgs{DaysBack: 3, IncludeHeardOnly: true, Profile: "home patch"})
+	if got.Location != preset.Location || got.RadiusKm != 10 || got.DaysBack != 3 ||
+		got.MaxSpecies != 20 || !got.IncludeHeardOnly || got.SortOrder != SortTaxonomic {
+		t.Errorf("unexpected merged args: %+v", got)
+	}
+	if TargetQueryKey("me", got) != TargetQueryKey("me", ApplyProfile(preset, TargetArgs{DaysBack: 3, IncludeHeardOnly: true})) {
+		t.Errorf("the profile name should not change the query key")
+	}
+}
+
+func Test_apply_profile_call_can_turn_off_heard_only(t *testing.T) {
+	t.Parallel()
+
+	preset := TargetArgs{Location: "Albuquerque, NM", IncludeHeardOnly: true}
+	if got := ApplyProfile(preset, TargetArgs{}); !got.IncludeHeardOnly {
+		t.Errorf("the preset's includeHeardOnly should carry over: %+v", got)
+	}
+	got := ApplyProfile(preset, TargetArgs{ExcludeHeardOnly: true})
+	if got.IncludeHeardOnly || got.ExcludeHeardOnly {
+		t.Errorf("excludeHeardOnly should turn the preset's setting off: %+v", got)
+	}
+	if TargetQueryKey("me", got) != TargetQueryKey("me", TargetArgs{Location: preset.Location}) {
+		t.Errorf("an overridden preset should key like the plain query")
+	}
+}
+
+func Test_exclude_heard_only_wins_without_a_profile(t *testing.T) {
+	t.Parallel()
+
+	args := TargetArgs{Location: "Albuquerque, NM", IncludeHeardOnly: true, ExcludeHeardOnly: true}
+	recent := []RecentObs{
+		{SpeciesCode: "grhowl", CommonName: "Great Horned Owl", LocID: "L1", HeardOnly: true},
+		{SpeciesCode: "clanut", CommonName: "Clark's Nutcracker", LocID: "L1"},
+	}
+	res, err := BuildTargetChecklist(context.Background(), args, map[string]struct{}{}, recent)
+	if err != nil {
+		t.Fatalf("BuildTargetChecklist: %v", err)
+	}
+	if res.Filters.IncludeHeardOnly {
+		t.Errorf("excludeHeardOnly should turn includeHeardOnly off: %+v", res.Filters)
+	}
+	for _, row := range res.Targets {
+		if row.SpeciesCode == "grhowl" {
+			t.Errorf("heard-only report should be left out: %+v", res.Targets)
+		}
+	}
+	if TargetQueryKey("me", args) != TargetQueryKey("me", TargetArgs{Location: args.Location}) {
+		t.Errorf("excludeHeardOnly should key like the plain query")
+	}
+}
+
+func Test_nil_target_profiles_has_none(t *testing.T) {
+	t.Parallel()
+
+	var p *TargetProfiles
+	if list, err := p.List("me"); err != nil || len(list) != 0 {
+		t.Errorf("List = %v, %v", list, err)
+	}
+	if err := p.Put("me", "home", TargetArgs{Location: "x"}); err == nil {
+		t.Errorf("expected Put to fail without a config directory")
+	}
+}
@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kpb/wingit-mcp/internal/taxonomy"
+)
+
+// Sort orders accepted by TargetArgs.SortOrder.
+const (
+	SortFrequency     = "frequency"
+	SortTaxonomic     = "taxonomic"
+	SortAlphabetical  = "alphabetical"
+	SortFamilyGrouped = "family-grouped"
+)
+
+// FamilyGroup is a family heading over a run of consecutive targets.
+type FamilyGroup struct {
+	FamilyCode    string
+	FamilyComName string
+	FamilySciName string
+	Species       []string
+}
+
+// SortTargets reorders targets for printing. The engine has already chosen
+// the most likely MaxSpecies targets by frequency; this only changes their
+// presentation. Taxonomic and family-grouped orders need tax, fill in each
+// row's Family and set Families headings; species missing from the taxonomy
+// go last under an "Other" heading.
+func SortTargets(res targetResult, order string, tax *taxonomy.Taxonomy) (targetResult, error) {
+	order = strings.ToLower(strings.TrimSpace(order))
+	out := res
+	out.Targets = append([]TargetRow(nil), res.Targets...)
+	out.Families = nil
+
+	switch order {
+	case "", SortFrequency:
+		out.Filters.SortOrder = SortFrequency
+		return out, nil
+	case SortAlphabetical:
+		sort.SliceStable(out.Targets, func(i, j int) bool {
+			return strings.ToLower(out.Targets[i].CommonName) < strings.ToLower(out.Targets[j].CommonName)
+		})
+		out.Filters.SortOrder = order
+		return out, nil
+	case SortTaxonomic, SortFamilyGrouped:
+		if tax == nil {
+			return res, fmt.Errorf("sortOrder %q needs a taxonomy (set WINGIT_TAXONOMY_JSON)", order)
+		}
+	default:
+		return res, fmt.Errorf("unknown sortOrder %q (want %s, %s, %s or %s)",
+			order, SortFrequency, SortTaxonomic, SortAlphabetical, SortFamilyGrouped)
+	}
+	out.Filters.SortOrder = order
+
+	type keyed struct {
+		row   TargetRow
+		entry taxonomy.Entry
+		known bool
+		// familyOrder is the taxon order of the family's first target, so
+		// families sort taxonomically while members keep frequency order.
+		familyOrder float64
+	}
+	rows := make([]keyed, len(out.Targets))
+	familyFirst := map[string]float64{}
+	for i, t := range out.Targets {
+		e, ok := tax.Lookup(t.SpeciesCode)
+		rows[i] = keyed{row: t, entry: e, known: ok}
+		if ok {
+			rows[i].row.Family = e.FamilyComName
+			if f, seen := familyFirst[e.FamilyCode]; !seen || e.TaxonOrder < f {
+				familyFirst[e.FamilyCode] = e.TaxonOrder
+			}
+		}
+	}
+	for i := range rows {
+		rows[i].familyOrder = familyFirst[rows[i].entry.FamilyCode]
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if a.known != b.known {
+			return a.known
+		}
+		if !a.known {
+			return false
+		}
+		if order == SortFamilyGrouped {
+			return a.familyOrder < b.familyOrder
+		}
+		return a.entry.TaxonOrder < b.entry.TaxonOrder
+	})
+
+	for i, r := range rows {
+		out.Targets[i] = r.row
+		g := FamilyGroup{FamilyCode: r.entry.FamilyCode, FamilyComName: r.entry.FamilyComName, FamilySciName: r.entry.FamilySciName}
+		if !r.known {
+			g = FamilyGroup{FamilyComName: "Other"}
+		}
+		if n := len(out.Families); n > 0 && out.Families[n-1].FamilyCode == g.FamilyCode && out.Families[n-1].FamilyComName == g.FamilyComName {
+			out.Families[n-1].Species = append(out.Families[n-1].Species, r.row.SpeciesCode)
+			continue
+		}
+		g.Species = []string{r.row.SpeciesCode}
+		out.Families = append(out.Families, g)
+	}
+	return out, nil
+}
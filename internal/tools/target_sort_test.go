@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/kpb/wingit-mcp/internal/taxonomy"
+)
+
+func Test_sort_targets_orders_and_family_headings(t *testing.T) {
+	t.Parallel()
+
+	tax, err := taxonomy.Load(filepath.Join("testdata", "taxonomy_example.json"))
+	if err != nil {
+		t.Fatalf("taxonomy.Load: %v", err)
+	}
+	// Frequency order as produced by the engine.
+	res := targetResult{Targets: []TargetRow{
+		{SpeciesCode: "amgold", CommonName: "American Goldfinch"},
+		{SpeciesCode: "caltow", CommonName: "Canyon Towhee"},
+		{SpeciesCode: "mystery", CommonName: "Mystery Bird"},
+		{SpeciesCode: "pinsis", CommonName: "Pine Siskin"},
+		{SpeciesCode: "lewo", CommonName: "Lewis's Woodpecker"},
+	}}
+
+	codes := func(r targetResult) []string {
+		var out []string
+		for _, t := range r.Targets {
+			out = append(out, t.SpeciesCode)
+		}
+		return out
+	}
+	check := func(order string, want ...string) targetResult {
+		t.Helper()
+		got, err := SortTargets(res, order, tax)
+		if err != nil {
+			t.Fatalf("SortTargets(%s): %v", order, err)
+		}
+		g := codes(got)
+		for i := range want {
+			if i >= len(g) || g[i] != want[i] {
+				t.Fatalf("SortTargets(%s) = %v, want %v", order, g, want)
+			}
+		}
+		return got
+	}
+
+	check(SortFrequency, "amgold", "caltow", "mystery", "pinsis", "lewo")
+	check(SortAlphabetical, "amgold", "caltow", "lewo", "mystery", "pinsis")
+	got := check(SortTaxonomic, "lewo", "pinsis", "amgold", "caltow", "mystery")
+	if len(got.Families) != 4 || got.Families[1].FamilySciName != "Fringillidae" || len(got.Families[1].Species) != 2 {
+		t.Fatalf("taxonomic families = %+v", got.Families)
+	}
+	if got.Families[3].FamilyComName != "Other" || got.Targets[0].Family != "Woodpeckers" {
+		t.Fatalf("headings = %+v, first row = %+v", got.Families, got.Targets[0])
+	}
+	// Family-grouped keeps frequency order within the finches.
+	check(SortFamilyGrouped, "lewo", "amgold", "pinsis", "caltow", "mystery")
+
+	if _, err := SortTargets(res, "random", tax); err == nil {
+		t.Fatalf("expected error for unknown sort order")
+	}
+	if _, err := SortTargets(res, SortTaxonomic, nil); err == nil {
+		t.Fatalf("expected error for taxonomic sort without taxonomy")
+	}
+}
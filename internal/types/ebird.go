@@ -30,6 +30,9 @@ type PersonalSighting struct {
 	Media              bool    `json:"media"`
 	EnteredAsHeardOnly bool    `json:"enteredAsHeardOnly"`
 	ChecklistID        string  `json:"checklistId"`
+	// Source tags where the sighting came from when several personal
+	// sources are merged (see ebird.LoadPersonal).
+	Source string `json:"source,omitempty"`
 }
 
 type SpeciesIndex struct {
@@ -45,11 +48,13 @@ type SpeciesIndex struct {
 
 // Subset of eBird recent observations (matches fixture)
 type RecentObservation struct {
-	SpeciesCode string `json:"speciesCode"`
-	CommonName  string `json:"comName"`
-	SciName     string `json:"sciName"`
-	LocName     string `json:"locName"`
-	LocID       string `json:"locId"`
-	ObsDt       string `json:"obsDt"`
-	HeardOnly   bool   `json:"howr,omitempty"`
+	SpeciesCode string  `json:"speciesCode"`
+	CommonName  string  `json:"comName"`
+	SciName     string  `json:"sciName"`
+	LocName     string  `json:"locName"`
+	LocID       string  `json:"locId"`
+	Lat         float64 `json:"lat,omitempty"`
+	Lng         float64 `json:"lng,omitempty"`
+	ObsDt       string  `json:"obsDt"`
+	HeardOnly   bool    `json:"howr,omitempty"`
 }
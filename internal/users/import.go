@@ -0,0 +1,175 @@
+// internal/users/import.go
+package users
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kpb/wingit-mcp/internal/ebird"
+	"github.com/kpb/wingit-mcp/internal/state"
+	"github.com/kpb/wingit-mcp/internal/store"
+	it "github.com/kpb/wingit-mcp/internal/types"
+)
+
+// Import ingests a new personal export at path, in any format
+// ebird.LoadPersonal accepts. path must be the user's own checklist file
+// (the default when empty) or, in multi-user mode, a file inside the users
+// directory other than users.json and the other users' checklists; relative
+// paths are resolved against that directory. The export is validated,
+// duplicate sightings (same checklist and species) are dropped, and the
+// result is compared with the loaded data. Unless dryRun,
+// it then replaces the loaded data and is saved so it survives a restart: a
+// store-backed user keeps it in the store, and any other user has it
+// written over their checklist file, which must then be a JSON export. On
+// error the previous data is kept.
+func (u *User) Import(ctx context.Context, path string, dryRun bool) (ebird.ChangeReport, error) {
+	path, err := u.importPath(path)
+	if err != nil {
+		return ebird.ChangeReport{}, err
+	}
+	// Another file replaces an in-memory user's data only if it can be
+	// saved over theirs; check before doing any work.
+	rewrite := u.st == nil && !sameFile(path, u.path)
+	if rewrite && !dryRun && !writableExport(u.path) {
+		return ebird.ChangeReport{}, fmt.Errorf("import %q: %s is not a single JSON export, so the import could not be saved; replace that file or configure an observation store", path, u.path)
+	}
+
+	// Stamp the user's own files before reading them, as Reload does.
+	own := sameFile(path, u.path)
+	var stamp fileStamp
+	if own {
+		stamp, _ = statFile(u.path)
+	}
+	next, err := ebird.LoadPersonal(path, u.tax)
+	if err != nil {
+		return ebird.ChangeReport{}, err
+	}
+	if err := u.validateForSwap(next); err != nil {
+		return ebird.ChangeReport{}, fmt.Errorf("import %q: %w", path, err)
+	}
+	next, dupes := ebird.Dedupe(next)
+
+	prev, err := u.Checklist(ctx)
+	if errors.Is(err, store.ErrNoUser) {
+		prev, err = &it.PersonalChecklist{}, nil // first import into the store
+	}
+	if err != nil {
+		return ebird.ChangeReport{}, fmt.Errorf("import %q: %w", path, err)
+	}
+	report := ebird.DiffChecklists(prev, next)
+	report.DuplicatesDropped = dupes
+	if dryRun || !report.Changed() {
+		return report, ctx.Err()
+	}
+	if rewrite {
+		b, err := json.MarshalIndent(next, "", "  ")
+		if err != nil {
+			return report, fmt.Errorf("import %q: %w", path, err)
+		}
+		if err := state.WriteFile(u.path, b); err != nil {
+			return report, fmt.Errorf("import %q: save to %s: %w", path, u.path, err)
+		}
+		own = true
+		stamp, _ = statFile(u.path)
+	}
+	if err := u.Swap(next); err != nil {
+		return report, fmt.Errorf("import %q: %w", path, err)
+	}
+	if own {
+		u.mu.Lock()
+		u.stamp = stamp
+		u.mu.Unlock()
+	}
+	return report, nil
+}
+
+// importPath resolves and checks a path passed to Import. Paths outside
+// the allowed places are rejected before the file system is consulted, so
+// callers cannot probe which files exist.
+func (u *User) importPath(path string) (string, error) {
+	if path == "" {
+		path = u.path
+	}
+	if path == "" {
+		return "", fmt.Errorf("user %q has no personal checklist file; pass a path", u.ID)
+	}
+	if !filepath.IsAbs(path) && u.importDir != "" {
+		path = filepath.Join(u.importDir, path)
+	}
+	path = filepath.Clean(path)
+	if sameFile(path, u.path) {
+		return path, nil
+	}
+	if u.importDir == "" || !within(u.importDir, path) {
+		return "", fmt.Errorf("import path must be your personal checklist file or a file in the users directory")
+	}
+	// A symlink inside the directory must not lead out of it.
+	dir, err := filepath.EvalSymlinks(u.importDir)
+	if err != nil {
+		return "", fmt.Errorf("users directory: %w", err)
+	}
+	real, err := filepath.EvalSymlinks(path)
+	if err == nil && !within(dir, real) {
+		return "", fmt.Errorf("import path must be your personal checklist file or a file in the users directory")
+	}
+	if u.notImportableFile(path) || (err == nil && u.notImportableFile(real)) {
+		return "", fmt.Errorf("import path must not be %s or another user's checklist", ProfilesFile)
+	}
+	return path, nil
+}
+
+// notImportableFile reports whether path is one of u.notImportable or, for
+// a manifest among them, one of its sources.
+func (u *User) notImportableFile(path string) bool {
+	for _, f := range u.notImportable {
+		files, _ := ebird.PersonalSourceFiles(f)
+		for _, f := range files {
+			if sameFile(path, f) {
+				return true
+			}
+			if real, err := filepath.EvalSymlinks(f); err == nil && sameFile(path, real) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func sameFile(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+// within reports whether path is dir or below it, lexically.
+func within(dir, path string) bool {
+	absDir, err1 := filepath.Abs(dir)
+	absPath, err2 := filepath.Abs(path)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	rel, err := filepath.Rel(absDir, absPath)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// writableExport reports whether path is a plain eBird JSON export (not a
+// manifest), so a checklist can be saved over it.
+func writableExport(path string) bool {
+	if !strings.EqualFold(filepath.Ext(path), ".json") {
+		return false
+	}
+	files, err := ebird.PersonalSourceFiles(path)
+	if err != nil || len(files) != 1 {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
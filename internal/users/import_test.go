@@ -0,0 +1,151 @@
+package users
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kpb/wingit-mcp/internal/ebird"
+	it "github.com/kpb/wingit-mcp/internal/types"
+)
+
+func Test_import_reports_changes_and_dedupes(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	pc, err := ebird.LoadPersonalChecklist(filepath.Join("testdata", "bob.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	own := filepath.Join(dir, "bob.json")
+	if err := os.WriteFile(own, []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	u := NewUser("bob", "Bob", own, pc)
+	u.importDir = dir
+
+	// Next month's export: a new checklist with a lifer (listed twice), and
+	// bob's original checklist deleted.
+	next := *pc
+	next.Sightings = []it.PersonalSighting{
+		{SpeciesCode: "lewo", ChecklistID: "S9", ObsDt: "2025-11-02", Count: 1},
+		{SpeciesCode: "lewo", ChecklistID: "S9", ObsDt: "2025-11-02", Count: 1},
+	}
+	next.SpeciesIndex = nil
+	b, err := json.Marshal(next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "export.json")
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	dry, err := u.Import(ctx, path, true)
+	if err != nil {
+		t.Fatalf("Import(dry run): %v", err)
+	}
+	if len(dry.NewLifers) != 1 || dry.NewLifers[0].SpeciesCode != "lewo" || dry.DuplicatesDropped != 1 {
+		t.Fatalf("dry run report = %+v", dry)
+	}
+	if len(dry.NewChecklists) != 1 || len(dry.DeletedChecklists) != 1 {
+		t.Fatalf("dry run checklists = new %v, deleted %v", dry.NewChecklists, dry.DeletedChecklists)
+	}
+	if _, ok := u.Seen()["lewo"]; ok {
+		t.Fatalf("dry run must not change the seen set")
+	}
+	if got, want := dry.Summary(), "1 new lifer, 1 new checklist, 1 deleted, 1 species dropped from life list"; got != want {
+		t.Fatalf("Summary = %q, want %q", got, want)
+	}
+
+	if _, err := u.Import(ctx, path, false); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	pc, err = u.Checklist(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := u.Seen()["lewo"]; !ok || len(pc.Sightings) != 1 {
+		t.Fatalf("after import seen=%v sightings=%d", u.Seen(), len(pc.Sightings))
+	}
+	again, err := u.Import(ctx, path, false)
+	if err != nil || again.Changed() {
+		t.Fatalf("re-import = %+v, %v; want no changes", again, err)
+	}
+
+	// The import was saved over bob's own file, so it survives a reload.
+	if err := u.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if _, ok := u.Seen()["lewo"]; !ok {
+		t.Fatalf("import lost on reload: seen=%v", u.Seen())
+	}
+}
+
+func Test_import_rejects_paths_outside_the_users_directory(t *testing.T) {
+	t.Parallel()
+
+	pc, err := ebird.LoadPersonalChecklist(filepath.Join("testdata", "bob.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	u := NewUser("bob", "Bob", filepath.Join(dir, "bob.json"), pc)
+	u.importDir = filepath.Join(dir, "users")
+	outside := filepath.Join(dir, "other.json")
+	if err := os.WriteFile(outside, []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(u.importDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(u.importDir, "link.json")); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{outside, "../other.json", "link.json", "/etc/passwd"} {
+		if _, err := u.Import(context.Background(), path, true); err == nil || !strings.Contains(err.Error(), "import path must be") {
+			t.Errorf("Import(%q) err = %v, want a path error", path, err)
+		}
+	}
+
+	// Without a users directory only the user's own file may be imported.
+	single := NewUser("default", "", filepath.Join("testdata", "bob.json"), pc)
+	if _, err := single.Import(context.Background(), filepath.Join("testdata", "alice.json"), true); err == nil {
+		t.Errorf("expected single-user import of another file to be rejected")
+	}
+	if _, err := single.Import(context.Background(), "", true); err != nil {
+		t.Errorf("Import(own file): %v", err)
+	}
+}
+
+func Test_import_rejects_other_users_files(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, name := range []string{"users.json", "alice.json", "bob.json"} {
+		copyFile(t, filepath.Join("testdata", name), filepath.Join(dir, name))
+	}
+	if err := os.Symlink(filepath.Join(dir, "alice.json"), filepath.Join(dir, "upload.json")); err != nil {
+		t.Fatal(err)
+	}
+	r, err := LoadDir(dir)
+	if err != nil {
+		t.Fatalf("LoadDir: %v", err)
+	}
+	bob, err := r.Get("bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"alice.json", "users.json", "upload.json", filepath.Join(dir, "alice.json")} {
+		if _, err := bob.Import(context.Background(), path, true); err == nil || !strings.Contains(err.Error(), "another user's checklist") {
+			t.Errorf("Import(%q) err = %v, want a path error", path, err)
+		}
+	}
+	if _, err := bob.Import(context.Background(), "bob.json", true); err != nil {
+		t.Errorf("Import(own file): %v", err)
+	}
+}
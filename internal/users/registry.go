@@ -0,0 +1,578 @@
+// internal/users/registry.go
+package users
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kpb/wingit-mcp/internal/ebird"
+	"github.com/kpb/wingit-mcp/internal/store"
+	"github.com/kpb/wingit-mcp/internal/taxonomy"
+	it "github.com/kpb/wingit-mcp/internal/types"
+)
+
+// ProfilesFile is the name of the profile list inside a users config directory.
+const ProfilesFile = "users.json"
+
+// DefaultUserID is the ID given to the single user in stdio / single-user mode.
+const DefaultUserID = "default"
+
+// UserHeader lets a trusted proxy name the calling user when no bearer token is used.
+const UserHeader = "X-Wingit-User"
+
+// ErrUnknownUser is returned when a requested user ID or token matches no profile.
+var ErrUnknownUser = errors.New("unknown user")
+
+// ErrUnauthorized is returned when a profile with a token is selected
+// without presenting that token.
+var ErrUnauthorized = errors.New("unauthorized")
+
+// Profile is one entry in users.json.
+type Profile struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	PersonalJSON string `json:"personalJson"`
+	Token        string `json:"token,omitempty"`
+	// ShareWith lists the users (or "*" for everyone) who may include this
+	// profile's life list in group queries.
+	ShareWith []string `json:"shareWith,omitempty"`
+}
+
+type profilesDoc struct {
+	Default string    `json:"default"`
+	Users   []Profile `json:"users"`
+}
+
+// User is a loaded profile: its personal checklist and derived seen set.
+// The checklist and seen set are swapped together under a lock on reload.
+// A store-backed user keeps only the seen set in memory and reads its
+// checklist from the store on demand.
+type User struct {
+	ID   string
+	Name string
+
+	token     string
+	shareWith []string
+	path      string
+	// importDir is where Import may read files other than path from (the
+	// users directory in multi-user mode).
+	importDir string
+	st        *store.Store
+	tax       *taxonomy.Taxonomy
+
+	// notImportable are files in importDir Import must not read: users.json
+	// and the other profiles' checklists, which are not the caller's data.
+	notImportable []string
+
+	mu   sync.RWMutex
+	pc   *it.PersonalChecklist
+	seen map[string]struct{}
+	// ptax caches PersonalTaxonomy until the checklist changes, which
+	// bumps gen.
+	ptax *taxonomy.Taxonomy
+	gen  int
+	// stamp is the statFile stamp of the files behind the data in use, so
+	// Watch reloads only what changed since.
+	stamp fileStamp
+}
+
+// Registry holds every known user and resolves the caller of a request.
+type Registry struct {
+	users     map[string]*User
+	defaultID string
+}
+
+// NewUser builds a User from an already-loaded personal checklist.
+// path is the file the checklist came from, used by Reload; it may be empty.
+func NewUser(id, name, path string, pc *it.PersonalChecklist) *User {
+	st, _ := statFile(path)
+	return &User{
+		ID:    id,
+		Name:  name,
+		path:  path,
+		pc:    pc,
+		seen:  ebird.BuildPersonalSeenSet(pc),
+		stamp: st,
+	}
+}
+
+// Options configure how users' personal checklists are loaded.
+type Options struct {
+	// Store, if set, holds checklists instead of memory: files are imported
+	// into it and queries are answered from its indexes.
+	Store *store.Store
+	// Taxonomy resolves species names to codes for CSV and YAML sources.
+	Taxonomy *taxonomy.Taxonomy
+}
+
+// OpenUser loads the personal checklist at path, which may be an eBird JSON
+// export or any source ebird.LoadPersonal accepts, including a manifest
+// merging several. With opts.Store the file is imported into the store (and
+// not even decoded when unchanged since the last import).
+func OpenUser(ctx context.Context, id, name, path string, opts Options) (*User, error) {
+	stamp, _ := statFile(path)
+	if opts.Store != nil {
+		if _, err := opts.Store.ImportFile(ctx, id, path, opts.Taxonomy); err != nil {
+			return nil, fmt.Errorf("import %q: %w", path, err)
+		}
+		seen, err := opts.Store.SeenSet(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return &User{ID: id, Name: name, path: path, st: opts.Store, tax: opts.Taxonomy, seen: seen, stamp: stamp}, nil
+	}
+	pc, err := ebird.LoadPersonal(path, opts.Taxonomy)
+	if err != nil {
+		return nil, err
+	}
+	if err := ebird.ValidatePersonalChecklist(pc).Err(); err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = pc.Meta.Owner
+	}
+	u := NewUser(id, name, path, pc)
+	u.tax, u.stamp = opts.Taxonomy, stamp
+	return u, nil
+}
+
+// HasToken reports whether the user authenticates with a bearer token.
+func (u *User) HasToken() bool { return u.token != "" }
+
+// SharesWith reports whether the user's life list may be read by the user
+// with the given ID: their own, or one named in the profile's shareWith.
+func (u *User) SharesWith(id string) bool {
+	if id == u.ID {
+		return true
+	}
+	for _, s := range u.shareWith {
+		if s == id || s == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// Checklist returns the user's current personal checklist. For a
+// store-backed user it is materialized from the store, which reads every
+// sighting; prefer Subset or the aggregate methods where they suffice.
+func (u *User) Checklist(ctx context.Context) (*it.PersonalChecklist, error) {
+	if u.st != nil {
+		return u.st.Checklist(ctx, u.ID)
+	}
+	return u.memory(), nil
+}
+
+// Subset returns the part of the user's checklist matching f, answered from
+// the store's indexes for a store-backed user.
+func (u *User) Subset(ctx context.Context, f ebird.Filter) (*it.PersonalChecklist, error) {
+	if u.st != nil {
+		return u.st.Subset(ctx, u.ID, f)
+	}
+	return ebird.Subset(u.memory(), f), nil
+}
+
+// FirstSightings returns a checklist with the same life list and regional
+// and yearly species counts as the user's, reduced by the store to each
+// species' first sighting per county and year.
+func (u *User) FirstSightings(ctx context.Context) (*it.PersonalChecklist, error) {
+	if u.st != nil {
+		return u.st.FirstSightings(ctx, u.ID)
+	}
+	return u.memory(), nil
+}
+
+// Summary returns the per-species aggregates ebird.ReconcileSummary needs.
+func (u *User) Summary(ctx context.Context) (ebird.Summary, error) {
+	if u.st != nil {
+		return u.st.Summary(ctx, u.ID)
+	}
+	return ebird.Summarize(u.memory()), nil
+}
+
+// Locations returns the user's sightings aggregated per location.
+func (u *User) Locations(ctx context.Context) ([]ebird.LocationSummary, error) {
+	if u.st != nil {
+		return u.st.Locations(ctx, u.ID)
+	}
+	return ebird.SummarizeLocations(u.memory().Sightings), nil
+}
+
+// SpeciesCodes returns every species code the user has recorded, sorted.
+func (u *User) SpeciesCodes(ctx context.Context) ([]string, error) {
+	if u.st != nil {
+		return u.st.SpeciesCodes(ctx, u.ID)
+	}
+	return ebird.SpeciesCodes(u.memory()), nil
+}
+
+// LocationIDs returns the user's location IDs, sorted.
+func (u *User) LocationIDs(ctx context.Context) ([]string, error) {
+	if u.st != nil {
+		return u.st.LocationIDs(ctx, u.ID)
+	}
+	return ebird.LocationIDs(u.memory()), nil
+}
+
+// ChecklistIDs returns the user's checklist IDs, sorted.
+func (u *User) ChecklistIDs(ctx context.Context) ([]string, error) {
+	if u.st != nil {
+		return u.st.ChecklistIDs(ctx, u.ID)
+	}
+	return ebird.ChecklistIDs(u.memory()), nil
+}
+
+func (u *User) memory() *it.PersonalChecklist {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.pc
+}
+
+// Seen returns the user's current seen set. Callers must not modify it.
+func (u *User) Seen() map[string]struct{} {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.seen
+}
+
+// PersonalTaxonomy returns ebird.PersonalTaxonomy of the user's checklist,
+// for name lookups when no taxonomy file is loaded. It is built on first
+// use and again after each Swap or Reload.
+func (u *User) PersonalTaxonomy(ctx context.Context) (*taxonomy.Taxonomy, error) {
+	u.mu.RLock()
+	tax, gen := u.ptax, u.gen
+	u.mu.RUnlock()
+	if tax != nil {
+		return tax, nil
+	}
+	pc, err := u.FirstSightings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tax = ebird.PersonalTaxonomy(pc)
+	u.mu.Lock()
+	if u.gen == gen {
+		u.ptax = tax
+	}
+	u.mu.Unlock()
+	return tax, nil
+}
+
+// Path returns the personal checklist file backing this user, if any.
+func (u *User) Path() string { return u.path }
+
+// Reload re-reads the user's personal checklist file, validates it and, if
+// valid, swaps it in atomically. On error the previous data is kept. Either
+// way the files are stamped as read, so Watch waits for the next change.
+func (u *User) Reload() error {
+	if u.path == "" {
+		return fmt.Errorf("user %q has no personal checklist file", u.ID)
+	}
+	u.setStamp(u.path)
+	if u.st != nil {
+		if _, err := u.st.ImportFile(context.Background(), u.ID, u.path, u.tax); err != nil {
+			return fmt.Errorf("reload %q: %w", u.path, err)
+		}
+		return u.refreshSeen()
+	}
+	pc, err := ebird.LoadPersonal(u.path, u.tax)
+	if err != nil {
+		return err
+	}
+	if err := u.validateForSwap(pc); err != nil {
+		return fmt.Errorf("reload %q: %w", u.path, err)
+	}
+	return u.Swap(pc)
+}
+
+// Swap replaces the user's checklist and rebuilds the seen set. A
+// store-backed user imports pc into the store instead.
+func (u *User) Swap(pc *it.PersonalChecklist) error {
+	if u.st != nil {
+		if _, err := u.st.Import(context.Background(), u.ID, pc); err != nil {
+			return err
+		}
+		return u.refreshSeen()
+	}
+	seen := ebird.BuildPersonalSeenSet(pc)
+	u.mu.Lock()
+	u.pc, u.seen = pc, seen
+	u.ptax, u.gen = nil, u.gen+1
+	u.mu.Unlock()
+	return nil
+}
+
+// setStamp records the current stamp of path's files as loaded.
+func (u *User) setStamp(path string) {
+	st, _ := statFile(path)
+	u.mu.Lock()
+	u.stamp = st
+	u.mu.Unlock()
+}
+
+func (u *User) loadedStamp() fileStamp {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.stamp
+}
+
+func (u *User) refreshSeen() error {
+	seen, err := u.st.SeenSet(context.Background(), u.ID)
+	if err != nil {
+		return err
+	}
+	u.mu.Lock()
+	u.seen = seen
+	u.ptax, u.gen = nil, u.gen+1
+	u.mu.Unlock()
+	return nil
+}
+
+// validateForSwap rejects checklists that would silently wipe or corrupt a
+// life list, such as a half-written export, one missing species codes or,
+// once the user has data, an empty one. Warnings (inconsistent totals,
+// index drift) don't block a reload.
+func (u *User) validateForSwap(pc *it.PersonalChecklist) error {
+	if err := ebird.ValidatePersonalChecklist(pc).Err(); err != nil {
+		return err
+	}
+	if len(pc.Sightings) == 0 && len(pc.SpeciesIndex) == 0 && len(u.Seen()) > 0 {
+		return errEmptyChecklist
+	}
+	return nil
+}
+
+var errEmptyChecklist = errors.New("no sightings or species index; keeping the loaded life list")
+
+// NewSingle returns a registry with one user that every request resolves to.
+func NewSingle(u *User) *Registry {
+	return &Registry{
+		users:     map[string]*User{u.ID: u},
+		defaultID: u.ID,
+	}
+}
+
+// LoadDir reads dir/users.json and loads each profile's personal checklist.
+// Relative checklist paths are resolved against dir.
+func LoadDir(dir string) (*Registry, error) {
+	return LoadDirWith(context.Background(), dir, Options{})
+}
+
+// LoadDirWith is LoadDir with every profile's checklist opened per opts
+// (see OpenUser).
+func LoadDirWith(ctx context.Context, dir string, opts Options) (*Registry, error) {
+	return loadDir(dir, func(p Profile, path string) (*User, error) {
+		return OpenUser(ctx, p.ID, p.Name, path, opts)
+	})
+}
+
+// LoadProfiles reads and checks dir/users.json like LoadDir without loading
+// any checklist: every user starts empty, with Path set. It is for
+// inspecting a configuration, as doctor does, where one bad checklist must
+// not hide the others.
+func LoadProfiles(dir string) (*Registry, error) {
+	return loadDir(dir, func(p Profile, path string) (*User, error) {
+		return NewUser(p.ID, p.Name, path, &it.PersonalChecklist{}), nil
+	})
+}
+
+func loadDir(dir string, load func(p Profile, path string) (*User, error)) (*Registry, error) {
+	b, err := os.ReadFile(filepath.Join(dir, ProfilesFile))
+	if err != nil {
+		return nil, fmt.Errorf("read user profiles: %w", err)
+	}
+	var doc profilesDoc
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("decode user profiles: %w", err)
+	}
+	if len(doc.Users) == 0 {
+		return nil, fmt.Errorf("user profiles: no users in %s", ProfilesFile)
+	}
+
+	r := &Registry{users: make(map[string]*User, len(doc.Users)), defaultID: doc.Default}
+	tokens := make(map[string]string, len(doc.Users))
+	for _, p := range doc.Users {
+		if p.ID == "" {
+			return nil, fmt.Errorf("user profiles: profile with empty id")
+		}
+		if _, dup := r.users[p.ID]; dup {
+			return nil, fmt.Errorf("user profiles: duplicate id %q", p.ID)
+		}
+		if p.Token != "" {
+			if other, dup := tokens[p.Token]; dup {
+				return nil, fmt.Errorf("user profiles: %q and %q share a token", other, p.ID)
+			}
+			tokens[p.Token] = p.ID
+		}
+		path := p.PersonalJSON
+		if path == "" {
+			return nil, fmt.Errorf("user profiles: %q has no personalJson", p.ID)
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		u, err := load(p, path)
+		if err != nil {
+			return nil, fmt.Errorf("user %q: %w", p.ID, err)
+		}
+		u.token = p.Token
+		u.shareWith = p.ShareWith
+		u.importDir = dir
+		r.users[p.ID] = u
+	}
+	for _, u := range r.users {
+		u.notImportable = []string{filepath.Join(dir, ProfilesFile)}
+		for _, other := range r.users {
+			if other != u {
+				u.notImportable = append(u.notImportable, other.path)
+			}
+		}
+	}
+	for _, p := range doc.Users {
+		for _, id := range p.ShareWith {
+			if _, ok := r.users[id]; !ok && id != "*" {
+				return nil, fmt.Errorf("user profiles: %q shares with unknown user %q", p.ID, id)
+			}
+		}
+	}
+	if r.defaultID != "" {
+		if _, ok := r.users[r.defaultID]; !ok {
+			return nil, fmt.Errorf("user profiles: default %q is not a known user", r.defaultID)
+		}
+	}
+	return r, nil
+}
+
+// Get returns the user with the given ID.
+func (r *Registry) Get(id string) (*User, error) {
+	u, ok := r.users[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownUser, id)
+	}
+	return u, nil
+}
+
+// Users returns all users ordered by ID.
+func (r *Registry) Users() []*User {
+	out := make([]*User, 0, len(r.users))
+	for _, id := range r.IDs() {
+		out = append(out, r.users[id])
+	}
+	return out
+}
+
+// IDs returns all user IDs in sorted order.
+func (r *Registry) IDs() []string {
+	ids := make([]string, 0, len(r.users))
+	for id := range r.users {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Resolve picks the calling user for a request.
+//
+// A bearer token in the Authorization header wins; if a user ID is also
+// requested it must name the token's owner. Without a token, the requested
+// ID, then the X-Wingit-User header, then the registry default are tried,
+// but only profiles without a token can be chosen that way: a profile with
+// a token is reachable only by presenting it. h may be nil (stdio
+// transport).
+func (r *Registry) Resolve(h http.Header, requested string) (*User, error) {
+	requested = strings.TrimSpace(requested)
+
+	if tok := bearerToken(h); tok != "" {
+		u := r.byToken(tok)
+		if u == nil {
+			return nil, fmt.Errorf("%w: bearer token not recognized", ErrUnknownUser)
+		}
+		if requested != "" && requested != u.ID {
+			return nil, fmt.Errorf("user %q may not act as %q", u.ID, requested)
+		}
+		return u, nil
+	}
+
+	if requested == "" && h != nil {
+		requested = strings.TrimSpace(h.Get(UserHeader))
+	}
+	u, err := r.Lookup(requested)
+	if err != nil {
+		return nil, err
+	}
+	if u.HasToken() {
+		if requested != "" {
+			return nil, fmt.Errorf("%w: user %q requires its bearer token", ErrUnauthorized, u.ID)
+		}
+		return nil, fmt.Errorf("%w: the default user requires its bearer token; authenticate or pass a user", ErrUnauthorized)
+	}
+	return u, nil
+}
+
+// Identify is Resolve for callers that must have said who they are: it
+// succeeds only on a bearer token, a requested ID or the X-Wingit-User
+// header, or when the registry has a single user. Falling back to the
+// default user is not identification.
+func (r *Registry) Identify(h http.Header, requested string) (*User, bool) {
+	named := strings.TrimSpace(requested) != "" || bearerToken(h) != ""
+	if h != nil && strings.TrimSpace(h.Get(UserHeader)) != "" {
+		named = true
+	}
+	if !named && len(r.users) != 1 {
+		return nil, false
+	}
+	u, err := r.Resolve(h, requested)
+	return u, err == nil
+}
+
+// Lookup picks a user by ID, else the registry default or only user,
+// without checking tokens. It is for trusted local callers such as the
+// command line; requests from clients go through Resolve.
+func (r *Registry) Lookup(requested string) (*User, error) {
+	if requested = strings.TrimSpace(requested); requested != "" {
+		return r.Get(requested)
+	}
+	if r.defaultID != "" {
+		return r.users[r.defaultID], nil
+	}
+	if len(r.users) == 1 {
+		for _, u := range r.users {
+			return u, nil
+		}
+	}
+	return nil, fmt.Errorf("no user selected: pass a user or authenticate")
+}
+
+func (r *Registry) byToken(tok string) *User {
+	var found *User
+	for _, u := range r.users {
+		if u.token == "" {
+			continue
+		}
+		// Compare every token so timing does not reveal which user matched.
+		if subtle.ConstantTimeCompare([]byte(u.token), []byte(tok)) == 1 {
+			found = u
+		}
+	}
+	return found
+}
+
+func bearerToken(h http.Header) string {
+	if h == nil {
+		return ""
+	}
+	fields := strings.Fields(h.Get("Authorization"))
+	if len(fields) != 2 || !strings.EqualFold(fields[0], "Bearer") {
+		return ""
+	}
+	return fields[1]
+}
@@ -0,0 +1,303 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kpb/wingit-mcp/internal/ebird"
+	"github.com/kpb/wingit-mcp/internal/store"
+	it "github.com/kpb/wingit-mcp/internal/types"
+)
+
+func Test_load_dir_and_resolve(t *testing.T) {
+	t.Parallel()
+
+	r, err := LoadDir("testdata")
+	if err != nil {
+		t.Fatalf("LoadDir: %v", err)
+	}
+	if got := r.IDs(); len(got) != 2 || got[0] != "alice" || got[1] != "bob" {
+		t.Fatalf("IDs = %v, want [alice bob]", got)
+	}
+
+	// No token, no argument: the default is alice, who has a token, so
+	// anonymous callers get nobody rather than her.
+	if _, err := r.Resolve(nil, ""); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("Resolve default err = %v, want ErrUnauthorized", err)
+	}
+	// Nor can they name her, by argument or header.
+	if _, err := r.Resolve(nil, "alice"); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("Resolve(alice) err = %v, want ErrUnauthorized", err)
+	}
+	h := http.Header{}
+	h.Set(UserHeader, "alice")
+	if _, err := r.Resolve(h, ""); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("Resolve(header alice) err = %v, want ErrUnauthorized", err)
+	}
+
+	// Tool argument selects a profile without a token.
+	u, err := r.Resolve(nil, "bob")
+	if err != nil || u.ID != "bob" {
+		t.Fatalf("Resolve(bob) = %v, %v", u, err)
+	}
+	if _, ok := u.Seen()["clanut"]; ok {
+		t.Fatalf("bob should not have clanut")
+	}
+
+	// Header selects a profile when no argument is given.
+	h = http.Header{}
+	h.Set(UserHeader, "bob")
+	if u, err = r.Resolve(h, ""); err != nil || u.ID != "bob" {
+		t.Fatalf("Resolve(header bob) = %v, %v", u, err)
+	}
+
+	// Local callers look users up without tokens.
+	if u, err = r.Lookup(""); err != nil || u.ID != "alice" {
+		t.Fatalf("Lookup default = %v, %v; want alice", u, err)
+	}
+
+	// Bearer token identifies the caller.
+	h = http.Header{}
+	h.Set("Authorization", "Bearer alice-secret")
+	if u, err = r.Resolve(h, ""); err != nil || u.ID != "alice" {
+		t.Fatalf("Resolve(token) = %v, %v", u, err)
+	}
+	if _, ok := u.Seen()["clanut"]; !ok {
+		t.Fatalf("alice seen set missing clanut: %v", u.Seen())
+	}
+	// ...and may not impersonate someone else.
+	if _, err = r.Resolve(h, "bob"); err == nil {
+		t.Fatalf("expected error impersonating bob with alice's token")
+	}
+
+	h.Set("Authorization", "Bearer nope")
+	if _, err = r.Resolve(h, ""); !errors.Is(err, ErrUnknownUser) {
+		t.Fatalf("bad token err = %v, want ErrUnknownUser", err)
+	}
+	if _, err = r.Resolve(nil, "carol"); !errors.Is(err, ErrUnknownUser) {
+		t.Fatalf("unknown user err = %v, want ErrUnknownUser", err)
+	}
+}
+
+func Test_load_dir_rejects_shared_tokens(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	pc, err := os.ReadFile(filepath.Join("testdata", "alice.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "alice.json"), pc, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	profiles := `{"users":[
+		{"id":"alice","personalJson":"alice.json","token":"same"},
+		{"id":"bob","personalJson":"alice.json","token":"same"}]}`
+	if err := os.WriteFile(filepath.Join(dir, ProfilesFile), []byte(profiles), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadDir(dir); err == nil {
+		t.Fatalf("expected error for shared token")
+	}
+}
+
+func Test_load_dir_store_serves_from_store(t *testing.T) {
+	t.Parallel()
+
+	st, err := store.Open(filepath.Join(t.TempDir(), "wingit.db"))
+	if err != nil {
+		t.Fatalf("store.Open: %v", err)
+	}
+	defer st.Close()
+
+	r, err := LoadDirWith(context.Background(), "testdata", Options{Store: st})
+	if err != nil {
+		t.Fatalf("LoadDirWith: %v", err)
+	}
+	u, err := r.Get("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := u.Seen()["clanut"]; !ok {
+		t.Fatalf("alice seen set missing clanut: %v", u.Seen())
+	}
+	pc, err := u.Checklist(context.Background())
+	if err != nil || len(pc.Sightings) != 2 {
+		t.Fatalf("alice checklist = %+v, %v; want 2 sightings", pc, err)
+	}
+	sub, err := u.Subset(context.Background(), ebird.Filter{SpeciesCodes: []string{"clanut"}})
+	if err != nil || len(sub.Sightings) != 1 || len(sub.SpeciesIndex) != 1 {
+		t.Fatalf("Subset(clanut) = %+v, %v", sub, err)
+	}
+	if err := u.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+}
+
+func Test_load_dir_reads_share_with(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	pc, err := os.ReadFile(filepath.Join("testdata", "alice.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "alice.json"), pc, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	write := func(profiles string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, ProfilesFile), []byte(profiles), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`{"users":[
+		{"id":"alice","personalJson":"alice.json","shareWith":["bob"]},
+		{"id":"bob","personalJson":"alice.json","shareWith":["*"]},
+		{"id":"carol","personalJson":"alice.json"}]}`)
+	r, err := LoadDir(dir)
+	if err != nil {
+		t.Fatalf("LoadDir: %v", err)
+	}
+	for _, c := range []struct {
+		owner, reader string
+		want          bool
+	}{
+		{"alice", "bob", true},
+		{"alice", "carol", false},
+		{"bob", "carol", true},
+		{"carol", "alice", false},
+		{"carol", "carol", true},
+	} {
+		u, _ := r.Get(c.owner)
+		if got := u.SharesWith(c.reader); got != c.want {
+			t.Errorf("%s.SharesWith(%s) = %v, want %v", c.owner, c.reader, got, c.want)
+		}
+	}
+
+	write(`{"users":[{"id":"alice","personalJson":"alice.json","shareWith":["mallory"]}]}`)
+	if _, err := LoadDir(dir); err == nil {
+		t.Fatalf("expected error for sharing with an unknown user")
+	}
+}
+
+func Test_identify_ignores_the_default_fallback(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	pc, err := os.ReadFile(filepath.Join("testdata", "alice.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "alice.json"), pc, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	profiles := `{"default":"bob","users":[
+		{"id":"alice","personalJson":"alice.json","token":"alice-secret"},
+		{"id":"bob","personalJson":"alice.json"}]}`
+	if err := os.WriteFile(filepath.Join(dir, ProfilesFile), []byte(profiles), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := LoadDir(dir)
+	if err != nil {
+		t.Fatalf("LoadDir: %v", err)
+	}
+
+	// Resolve falls back to bob; Identify does not.
+	if u, err := r.Resolve(nil, ""); err != nil || u.ID != "bob" {
+		t.Fatalf("Resolve default = %v, %v; want bob", u, err)
+	}
+	if u, ok := r.Identify(nil, ""); ok {
+		t.Fatalf("Identify with nothing = %v, want none", u.ID)
+	}
+
+	byHeader := http.Header{}
+	byHeader.Set(UserHeader, "bob")
+	byToken := http.Header{}
+	byToken.Set("Authorization", "Bearer alice-secret")
+	for _, c := range []struct {
+		h         http.Header
+		requested string
+		want      string
+	}{
+		{nil, "bob", "bob"},
+		{byHeader, "", "bob"},
+		{byToken, "", "alice"},
+		{nil, "alice", ""}, // named, but without her token
+	} {
+		got := ""
+		if u, ok := r.Identify(c.h, c.requested); ok {
+			got = u.ID
+		}
+		if got != c.want {
+			t.Errorf("Identify(%v, %q) = %q, want %q", c.h, c.requested, got, c.want)
+		}
+	}
+
+	// With one user there is nobody else to mistake the caller for.
+	single := NewSingle(NewUser("me", "", "", &it.PersonalChecklist{}))
+	if u, ok := single.Identify(nil, ""); !ok || u.ID != "me" {
+		t.Fatalf("single Identify = %v, %v", u, ok)
+	}
+}
+
+func Test_personal_taxonomy_is_cached_until_swap(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	u := NewUser("me", "", "", &it.PersonalChecklist{Sightings: []it.PersonalSighting{
+		{SpeciesCode: "clanut", CommonName: "Clark's Nutcracker", ObsDt: "2025-09-12"},
+	}})
+	tax, err := u.PersonalTaxonomy(ctx)
+	if err != nil {
+		t.Fatalf("PersonalTaxonomy: %v", err)
+	}
+	if again, _ := u.PersonalTaxonomy(ctx); again != tax {
+		t.Errorf("expected the cached taxonomy on a second call")
+	}
+	if err := u.Swap(&it.PersonalChecklist{Sightings: []it.PersonalSighting{
+		{SpeciesCode: "clanut", CommonName: "Clark's Nutcracker", ObsDt: "2025-09-12"},
+		{SpeciesCode: "pinsis", CommonName: "Pine Siskin", ObsDt: "2025-09-13"},
+	}}); err != nil {
+		t.Fatalf("Swap: %v", err)
+	}
+	tax, err = u.PersonalTaxonomy(ctx)
+	if err != nil {
+		t.Fatalf("PersonalTaxonomy: %v", err)
+	}
+	if _, ok := tax.Lookup("pinsis"); !ok {
+		t.Errorf("the taxonomy should be rebuilt after Swap")
+	}
+}
+
+func Test_empty_checklist_starts_a_user_but_never_replaces_data(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.json")
+	if err := os.WriteFile(empty, []byte(`{"sightings": []}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenUser(context.Background(), "new", "", empty, Options{}); err != nil {
+		t.Fatalf("a new user with no data should load: %v", err)
+	}
+
+	path := filepath.Join(dir, "bob.json")
+	copyFile(t, filepath.Join("testdata", "bob.json"), path)
+	u, err := OpenUser(context.Background(), "bob", "", path, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	copyFile(t, empty, path)
+	if err := u.Reload(); !errors.Is(err, errEmptyChecklist) {
+		t.Fatalf("Reload(empty) = %v, want errEmptyChecklist", err)
+	}
+	if len(u.Seen()) == 0 {
+		t.Errorf("a rejected reload should keep the life list")
+	}
+}
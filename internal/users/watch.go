@@ -0,0 +1,84 @@
+// internal/users/watch.go
+package users
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/kpb/wingit-mcp/internal/ebird"
+)
+
+// DefaultWatchInterval is how often Watch polls checklist files.
+const DefaultWatchInterval = 2 * time.Second
+
+type fileStamp struct {
+	mod  time.Time
+	size int64
+}
+
+// statFile stamps path and, for a sources manifest, every file it merges:
+// the latest modification time and the total size.
+func statFile(path string) (fileStamp, bool) {
+	files, err := ebird.PersonalSourceFiles(path)
+	if err != nil {
+		files = []string{path}
+	}
+	var st fileStamp
+	for _, f := range files {
+		fi, err := os.Stat(f)
+		if err != nil {
+			return fileStamp{}, false
+		}
+		if fi.ModTime().After(st.mod) {
+			st.mod = fi.ModTime()
+		}
+		st.size += fi.Size()
+	}
+	return st, true
+}
+
+// Watch polls every user's personal checklist files and reloads them when its
+// modification time or size changes and has then held steady for one poll,
+// so a file still being written is not read half-way. onReload is called after each reload
+// attempt with the error (nil on success); a failed reload keeps the old data
+// and is retried on the next change. Watch blocks until ctx is done.
+//
+// Polling avoids a platform-specific file notification dependency and copes
+// with editors and exporters that replace files by rename.
+func (r *Registry) Watch(ctx context.Context, interval time.Duration, onReload func(*User, error)) {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	pending := make(map[*User]fileStamp) // changed stamp awaiting a quiet poll
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, u := range r.Users() {
+			if u.path == "" {
+				continue
+			}
+			st, ok := statFile(u.path)
+			if !ok || st == u.loadedStamp() {
+				delete(pending, u)
+				continue
+			}
+			if p, waiting := pending[u]; !waiting || p != st {
+				pending[u] = st
+				continue
+			}
+			delete(pending, u)
+			err := u.Reload()
+			if onReload != nil {
+				onReload(u, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package users
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kpb/wingit-mcp/internal/ebird"
+)
+
+func Test_watch_reloads_changed_checklist(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "personal.json")
+	copyFile(t, filepath.Join("testdata", "bob.json"), path)
+
+	pc, err := ebird.LoadPersonalChecklist(path)
+	if err != nil {
+		t.Fatalf("LoadPersonalChecklist: %v", err)
+	}
+	u := NewUser("bob", "Bob", path, pc)
+	r := NewSingle(u)
+	if _, ok := u.Seen()["clanut"]; ok {
+		t.Fatalf("bob should start without clanut")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloaded := make(chan error, 4)
+	go r.Watch(ctx, 10*time.Millisecond, func(_ *User, err error) { reloaded <- err })
+
+	// A broken export is rejected and the old data kept.
+	writeAfterTick(t, path, []byte(`{"sightings":[{"speciesCode":""}]}`))
+	if err := waitReload(t, reloaded); err == nil {
+		t.Fatalf("expected validation error for missing speciesCode")
+	}
+	if _, ok := u.Seen()["amgold"]; !ok {
+		t.Fatalf("failed reload should keep previous seen set")
+	}
+
+	// A good export is swapped in.
+	copyFileAfterTick(t, filepath.Join("testdata", "alice.json"), path)
+	if err := waitReload(t, reloaded); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if _, ok := u.Seen()["clanut"]; !ok {
+		t.Fatalf("reloaded seen set missing clanut")
+	}
+}
+
+func Test_reload_and_import_record_the_file_stamp(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "personal.json")
+	copyFile(t, filepath.Join("testdata", "bob.json"), path)
+	pc, err := ebird.LoadPersonalChecklist(path)
+	if err != nil {
+		t.Fatalf("LoadPersonalChecklist: %v", err)
+	}
+	u := NewUser("bob", "Bob", path, pc)
+
+	// Watch compares files against this stamp, so a change picked up by
+	// hand must not be reloaded again.
+	copyFileAfterTick(t, filepath.Join("testdata", "alice.json"), path)
+	if err := u.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if st, _ := statFile(path); u.loadedStamp() != st {
+		t.Errorf("Reload should record the stamp it read")
+	}
+
+	copyFileAfterTick(t, filepath.Join("testdata", "bob.json"), path)
+	if _, err := u.Import(context.Background(), "", false); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if st, _ := statFile(path); u.loadedStamp() != st {
+		t.Errorf("Import should record the stamp of the user's file")
+	}
+}
+
+func waitReload(t *testing.T, ch <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-ch:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for reload")
+		return nil
+	}
+}
+
+// writeAfterTick sleeps briefly so the new mtime differs on coarse
+// filesystems, then replaces path by rename as exporters typically do.
+func writeAfterTick(t *testing.T, path string, b []byte) {
+	t.Helper()
+	time.Sleep(20 * time.Millisecond)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func copyFileAfterTick(t *testing.T, src, dst string) {
+	t.Helper()
+	b, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeAfterTick(t, dst, b)
+}
+
+func copyFile(t *testing.T, src, dst string) {
+	t.Helper()
+	b, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dst, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}